@@ -0,0 +1,65 @@
+package controllers
+
+import (
+    "encoding/json"
+    "io"
+    "log"
+    "net/http"
+    "time"
+
+    "backend/Models"
+)
+
+// AuditEntry is a single append-only record of a mutation
+type AuditEntry struct {
+    Timestamp string    `json:"timestamp"`
+    Operation string    `json:"operation"`
+    TargetId  models.ID `json:"targetId"`
+    RequestId string    `json:"requestId"`
+    BoardId   string    `json:"boardId,omitempty"`
+}
+
+// AuditLogger writes one JSON line per mutation to a dedicated log stream,
+// separate from the regular application logs so it can be shipped on its own
+type AuditLogger struct {
+    out *log.Logger
+}
+
+func NewAuditLogger(w io.Writer) *AuditLogger {
+    return &AuditLogger{
+        out: log.New(w, "[AUDIT] ", 0),
+    }
+}
+
+// Record writes an audit entry. It never fails the caller's operation;
+// any problem writing the entry is logged loudly instead.
+func (a *AuditLogger) Record(r *http.Request, operation string, targetId models.ID) {
+    if a == nil {
+        return
+    }
+
+    entry := AuditEntry{
+        Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+        Operation: operation,
+        TargetId:  targetId,
+        RequestId: requestIdFor(r),
+        BoardId:   BoardIDFromContext(r.Context()),
+    }
+
+    payload, err := json.Marshal(entry)
+    if err != nil {
+        LoggerFromContext(r.Context()).Printf("[AUDIT ERROR] Failed to encode audit entry for %s id=%d: %v", operation, targetId, err)
+        return
+    }
+    if err := a.out.Output(2, string(payload)); err != nil {
+        LoggerFromContext(r.Context()).Printf("[AUDIT ERROR] Failed to write audit entry %s: %v", string(payload), err)
+    }
+}
+
+// requestIdFor returns the caller-supplied X-Request-Id, or a random id when absent
+func requestIdFor(r *http.Request) string {
+    if id := r.Header.Get("X-Request-Id"); id != "" {
+        return id
+    }
+    return NewRequestId()
+}
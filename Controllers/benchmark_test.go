@@ -0,0 +1,120 @@
+package controllers
+
+import (
+    "database/sql/driver"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "strings"
+    "testing"
+
+    "backend/Models"
+)
+
+// The handler benchmarks run against the stub driver, so they measure the handlers'
+// own work (negotiation, scanning, encoding) and not a database. Run them with:
+//
+//     go test ./Controllers -run '^$' -bench . -benchmem
+
+// benchProjects returns n projects for the encoding benchmarks
+func benchProjects(n int) []models.TestProjects {
+    projects := make([]models.TestProjects, n)
+    for i := range projects {
+        projects[i] = models.TestProjects{Id: models.ID(i + 1), Name: fmt.Sprintf("project %d", i+1)}
+    }
+    return projects
+}
+
+func BenchmarkGetById(b *testing.B) {
+    stub := &stubConnector{rows: [][]driver.Value{{int64(7), "seven"}}}
+    tc := newStubController(b, stub, 4)
+    
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        w := httptest.NewRecorder()
+        tc.GetById(w, httptest.NewRequest(http.MethodGet, "/api/test/7", nil), 7)
+        if w.Code != http.StatusOK {
+            b.Fatalf("status = %d", w.Code)
+        }
+    }
+}
+
+func BenchmarkGetAll(b *testing.B) {
+    for _, n := range []int{10, 100, 1000} {
+        stub := &stubConnector{rows: projectRows(n)}
+        tc := newStubController(b, stub, 4)
+        target := fmt.Sprintf("/api/test?limit=%d", n)
+        b.Run(strconv.Itoa(n), func(b *testing.B) {
+            b.ReportAllocs()
+            for i := 0; i < b.N; i++ {
+                w := httptest.NewRecorder()
+                tc.GetAll(w, httptest.NewRequest(http.MethodGet, target, nil))
+                if w.Code != http.StatusOK {
+                    b.Fatalf("status = %d", w.Code)
+                }
+            }
+        })
+    }
+}
+
+func BenchmarkCreate(b *testing.B) {
+    stub := &stubConnector{rows: [][]driver.Value{{int64(7), "seven"}}}
+    tc := newStubController(b, stub, 4)
+    
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        w := httptest.NewRecorder()
+        tc.Create(w, httptest.NewRequest(http.MethodPost, "/api/test", strings.NewReader(`{"Name": "seven"}`)))
+        if w.Code != http.StatusCreated {
+            b.Fatalf("status = %d", w.Code)
+        }
+    }
+}
+
+func BenchmarkWriteNegotiated(b *testing.B) {
+    for _, format := range []string{formatJSON, formatJSONAPI, formatXML} {
+        for _, n := range []int{1, 100, 1000} {
+            projects := benchProjects(n)
+            b.Run(fmt.Sprintf("%s/%d", format, n), func(b *testing.B) {
+                b.ReportAllocs()
+                for i := 0; i < b.N; i++ {
+                    writeNegotiated(httptest.NewRecorder(), format, http.StatusOK, projects)
+                }
+            })
+        }
+    }
+}
+
+func BenchmarkNegotiate(b *testing.B) {
+    headers := []string{
+        "",
+        "application/json",
+        "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+        "application/vnd.api+json, application/json;q=0.5",
+    }
+    for _, accept := range headers {
+        b.Run(fmt.Sprintf("%q", accept), func(b *testing.B) {
+            b.ReportAllocs()
+            for i := 0; i < b.N; i++ {
+                negotiate(accept, availableFormats)
+            }
+        })
+    }
+}
+
+func BenchmarkValidateProject(b *testing.B) {
+    cases := map[string]string{
+        "valid":   "a reasonably named project",
+        "invalid": strings.Repeat("x", MaxNameLength+1) + "\x00",
+    }
+    for label, name := range cases {
+        project := models.TestProjects{Name: name}
+        b.Run(label, func(b *testing.B) {
+            b.ReportAllocs()
+            for i := 0; i < b.N; i++ {
+                validateProject(project)
+            }
+        })
+    }
+}
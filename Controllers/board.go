@@ -0,0 +1,17 @@
+package controllers
+
+import "context"
+
+type boardIdKey struct{}
+
+// WithBoardID returns a copy of ctx carrying the request's resolved board id
+func WithBoardID(ctx context.Context, boardId string) context.Context {
+    return context.WithValue(ctx, boardIdKey{}, boardId)
+}
+
+// BoardIDFromContext returns the board id stored by WithBoardID, or "" when the
+// request has none (or outside a request)
+func BoardIDFromContext(ctx context.Context) string {
+    boardId, _ := ctx.Value(boardIdKey{}).(string)
+    return boardId
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func newTableController(t *testing.T, pt *projectTable) *TestController {
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    tc.NameUniquePerBoard = true
+    return tc
+}
+
+func TestCreateScopesNameUniquenessToBoard(t *testing.T) {
+    tc := newTableController(t, &projectTable{})
+    create := func(board string) int {
+        w := httptest.NewRecorder()
+        req := httptest.NewRequest(http.MethodPost, "/api/test", strings.NewReader(`{"Name":"roadmap"}`))
+        req.Header.Set("Content-Type", "application/json")
+        tc.Create(w, onBoard(req, board))
+        return w.Code
+    }
+    
+    if code := create("aaaa"); code != http.StatusCreated {
+        t.Fatalf("first create on board aaaa = %d, want 201", code)
+    }
+    if code := create("bbbb"); code != http.StatusCreated {
+        t.Errorf("same name on board bbbb = %d, want 201", code)
+    }
+    if code := create("aaaa"); code != http.StatusConflict {
+        t.Errorf("same name again on board aaaa = %d, want 409", code)
+    }
+}
+
+func rename(tc *TestController, board, body string) *httptest.ResponseRecorder {
+    w := httptest.NewRecorder()
+    req := httptest.NewRequest(http.MethodPut, "/api/test/rename", strings.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+    tc.Rename(w, onBoard(req, board))
+    return w
+}
+
+func TestRenameScopesNamesToBoard(t *testing.T) {
+    pt := &projectTable{}
+    onA := pt.add("draft", "aaaa")
+    onB := pt.add("draft", "bbbb")
+    pt.add("final", "bbbb")
+    tc := newTableController(t, pt)
+    
+    // "final" only exists on board bbbb, so board aaaa can take the name
+    if w := rename(tc, "aaaa", `{"from":"draft","to":"final"}`); w.Code != http.StatusOK {
+        t.Fatalf("rename on board aaaa = %d, want 200: %s", w.Code, w.Body)
+    }
+    // Within board bbbb the target is taken
+    if w := rename(tc, "bbbb", `{"from":"draft","to":"final"}`); w.Code != http.StatusConflict {
+        t.Errorf("rename on board bbbb = %d, want 409: %s", w.Code, w.Body)
+    }
+    
+    for _, row := range pt.rows {
+        want := map[int64]string{onA: "final", onB: "draft"}[row.id]
+        if want != "" && row.name != want {
+            t.Errorf("project %d (board %s) is named %q, want %q", row.id, row.board, row.name, want)
+        }
+    }
+}
+
+func getOrCreate(tc *TestController, board, name string) *httptest.ResponseRecorder {
+    w := httptest.NewRecorder()
+    req := httptest.NewRequest(http.MethodPost, "/api/test?getOrCreate=true", strings.NewReader(`{"Name":"`+name+`"}`))
+    req.Header.Set("Content-Type", "application/json")
+    tc.Create(w, onBoard(req, board))
+    return w
+}
+
+func TestGetOrCreateCreatesThenReturnsExisting(t *testing.T) {
+    pt := &projectTable{}
+    tc := newTableController(t, pt)
+    
+    created := getOrCreate(tc, "aaaa", "roadmap")
+    if created.Code != http.StatusCreated {
+        t.Fatalf("first call = %d, want 201: %s", created.Code, created.Body)
+    }
+    existing := getOrCreate(tc, "aaaa", "roadmap")
+    if existing.Code != http.StatusOK {
+        t.Fatalf("second call = %d, want 200: %s", existing.Code, existing.Body)
+    }
+    if existing.Body.String() != created.Body.String() {
+        t.Errorf("second call returned %s, want the project created first %s", existing.Body, created.Body)
+    }
+    if len(pt.rows) != 1 {
+        t.Errorf("%d projects stored, want 1", len(pt.rows))
+    }
+    
+    // The name is only taken on board aaaa
+    if w := getOrCreate(tc, "bbbb", "roadmap"); w.Code != http.StatusCreated {
+        t.Errorf("call on board bbbb = %d, want 201", w.Code)
+    }
+}
+
+func TestGetOrCreateNeedsBoardScopedNames(t *testing.T) {
+    pt := &projectTable{}
+    tc := newTableController(t, pt)
+    if w := getOrCreate(tc, "", "roadmap"); w.Code != http.StatusBadRequest {
+        t.Errorf("without a board id = %d, want 400", w.Code)
+    }
+    
+    tc.NameUniquePerBoard = false
+    if w := getOrCreate(tc, "aaaa", "roadmap"); w.Code != http.StatusBadRequest {
+        t.Errorf("without NAME_UNIQUE_PER_BOARD = %d, want 400", w.Code)
+    }
+    if len(pt.rows) != 0 {
+        t.Errorf("%d projects stored by rejected requests", len(pt.rows))
+    }
+}
@@ -0,0 +1,330 @@
+package controllers
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "net/http"
+    "strconv"
+
+    "backend/Dberr"
+    "backend/Models"
+)
+
+// maxBulkItems caps the number of items in one bulk request
+const maxBulkItems = 1000
+
+// bulkItemResult reports the outcome of one item of a bulk request. Index is the
+// item's position in the request body, so results can be matched to inputs.
+//
+// Results are always in input order: handlers allocate results up front and fill
+// results[i] for input i. Items are written with one INSERT ... RETURNING each (in a
+// shared transaction when atomic), so every returned id is tied to its input by the
+// loop index, never by the order rows come back from a multi-row RETURNING, which
+// Postgres does not guarantee.
+type bulkItemResult struct {
+    Index  int        `json:"index"`
+    Status int        `json:"status"`
+    Id     *models.ID `json:"id,omitempty"`
+    Error  string     `json:"error,omitempty"`
+}
+
+// bulkResponse is the body of every bulk response
+type bulkResponse struct {
+    Results []bulkItemResult `json:"results"`
+}
+
+// parseAtomic reads the atomic query parameter (default true)
+func parseAtomic(r *http.Request) (bool, error) {
+    raw := r.URL.Query().Get("atomic")
+    if raw == "" {
+        return true, nil
+    }
+    atomic, err := strconv.ParseBool(raw)
+    if err != nil {
+        return false, fmt.Errorf("atomic must be true or false")
+    }
+    return atomic, nil
+}
+
+// BulkCreate creates every project in a JSON array body.
+//
+// atomic=true (the default) is all-or-nothing: if any item is invalid or fails, nothing
+// is created and the response is 400 (or the DB error status) with the per-item results
+// explaining which. atomic=false is best effort: each item is created on its own and the
+// response is always 207 Multi-Status with one result per item.
+func (tc *TestController) BulkCreate(w http.ResponseWriter, r *http.Request) {
+    w, done := tc.track("BulkCreate", w)
+    defer done()
+    
+    atomic, err := parseAtomic(r)
+    if err != nil {
+        http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    var projects []models.TestProjects
+    if err := decodeJSON(r, &projects); err != nil {
+        http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    if len(projects) == 0 || len(projects) > maxBulkItems {
+        http.Error(w, fmt.Sprintf("Invalid request: between 1 and %d items are required", maxBulkItems), http.StatusBadRequest)
+        return
+    }
+    
+    results := make([]bulkItemResult, len(projects))
+    invalid := false
+    for i, project := range projects {
+        results[i].Index = i
+        if err := validateName(project.Name); err != nil {
+            results[i].Status = http.StatusBadRequest
+            results[i].Error = err.Error()
+            invalid = true
+        }
+    }
+    
+    if !atomic {
+        for i, project := range projects {
+            if results[i].Status != 0 {
+                continue
+            }
+            var id models.ID
+            ctx, cancel := tc.dbContext(r.Context())
+            err := tc.stmts.insert.QueryRowContext(ctx, tc.insertArgs(r, project.Name)...).Scan(&id, &project.Name)
+            cancel()
+            if r.Context().Err() != nil {
+                writeDBError(w, r, err)
+                return
+            }
+            tc.setItemResult(r, &results[i], "create", id, http.StatusCreated, err)
+        }
+        WriteJSON(w, http.StatusMultiStatus, bulkResponse{Results: results})
+        return
+    }
+    
+    if invalid {
+        WriteJSON(w, http.StatusBadRequest, bulkResponse{Results: results})
+        return
+    }
+    
+    // ids[i] is the id assigned to projects[i]; see bulkItemResult on ordering
+    ids := make([]models.ID, len(projects))
+    err = tc.inTx(r, func(ctx context.Context, tx *sql.Tx) error {
+        insert := tx.StmtContext(ctx, tc.stmts.insert)
+        for i, project := range projects {
+            if err := insert.QueryRowContext(ctx, tc.insertArgs(r, project.Name)...).Scan(&ids[i], &project.Name); err != nil {
+                results[i].Status = itemErrorStatus(err)
+                results[i].Error = itemErrorMessage(err)
+                return err
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        tc.writeAtomicFailure(w, r, results, err)
+        return
+    }
+    
+    for i := range results {
+        id := ids[i]
+        results[i].Status = http.StatusCreated
+        results[i].Id = &id
+        tc.Audit.Record(r, "create", id)
+    }
+    WriteJSON(w, http.StatusCreated, bulkResponse{Results: results})
+}
+
+// BulkDelete deletes every project whose id is in a JSON array body, with the same
+// atomic / best-effort modes as BulkCreate. In atomic mode a missing id aborts the
+// whole request with 404.
+func (tc *TestController) BulkDelete(w http.ResponseWriter, r *http.Request) {
+    w, done := tc.track("BulkDelete", w)
+    defer done()
+    
+    atomic, err := parseAtomic(r)
+    if err != nil {
+        http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    var ids []models.ID
+    if err := decodeJSON(r, &ids); err != nil {
+        http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    if len(ids) == 0 || len(ids) > maxBulkItems {
+        http.Error(w, fmt.Sprintf("Invalid request: between 1 and %d items are required", maxBulkItems), http.StatusBadRequest)
+        return
+    }
+    
+    results := make([]bulkItemResult, len(ids))
+    for i := range ids {
+        results[i].Index = i
+    }
+    
+    if !atomic {
+        for i, id := range ids {
+            ctx, cancel := tc.dbContext(r.Context())
+            result, err := tc.stmts.delete.ExecContext(ctx, id)
+            cancel()
+            if r.Context().Err() != nil {
+                writeDBError(w, r, err)
+                return
+            }
+            if err == nil {
+                err = requireRowAffected(result)
+            }
+            tc.setItemResult(r, &results[i], "delete", id, http.StatusOK, err)
+        }
+        WriteJSON(w, http.StatusMultiStatus, bulkResponse{Results: results})
+        return
+    }
+    
+    err = tc.inTx(r, func(ctx context.Context, tx *sql.Tx) error {
+        del := tx.StmtContext(ctx, tc.stmts.delete)
+        for i, id := range ids {
+            result, err := del.ExecContext(ctx, id)
+            if err == nil {
+                err = requireRowAffected(result)
+            }
+            if err != nil {
+                results[i].Status = itemErrorStatus(err)
+                results[i].Error = itemErrorMessage(err)
+                return err
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        tc.writeAtomicFailure(w, r, results, err)
+        return
+    }
+    
+    for i, id := range ids {
+        id := id
+        results[i].Status = http.StatusOK
+        results[i].Id = &id
+        tc.Audit.Record(r, "delete", id)
+    }
+    WriteJSON(w, http.StatusOK, bulkResponse{Results: results})
+}
+
+// DeleteAll removes every project, for resetting test environments between runs. It
+// is refused with 403 unless AllowDestructive is set, and needs ?confirm=true.
+func (tc *TestController) DeleteAll(w http.ResponseWriter, r *http.Request) {
+    w, done := tc.track("DeleteAll", w)
+    defer done()
+    
+    if !tc.AllowDestructive {
+        WriteJSON(w, http.StatusForbidden, map[string]string{"error": "destructive operations are disabled (set ALLOW_DESTRUCTIVE=true)"})
+        return
+    }
+    if r.URL.Query().Get("confirm") != "true" {
+        http.Error(w, "Invalid request: confirm=true is required", http.StatusBadRequest)
+        return
+    }
+    
+    // DELETE rather than TRUNCATE so the row count can be reported and foreign keys
+    // are checked row by row instead of failing the whole TRUNCATE up front
+    ctx, cancel := tc.dbContext(r.Context())
+    defer cancel()
+    result, err := tc.DB.ExecContext(ctx, `DELETE FROM public."TestProjects"`)
+    if dberr.IsForeignKeyViolation(err) {
+        WriteJSON(w, http.StatusConflict, map[string]string{"error": "cannot delete: some projects are referenced by other records"})
+        return
+    }
+    if err != nil {
+        writeDBError(w, r, err)
+        return
+    }
+    deleted, err := result.RowsAffected()
+    if err != nil {
+        writeDBError(w, r, err)
+        return
+    }
+    
+    LoggerFromContext(r.Context()).Printf("[DESTRUCTIVE] Deleted all %d projects", deleted)
+    tc.Audit.Record(r, "delete_all", 0)
+    WriteJSON(w, http.StatusOK, map[string]int64{"deleted": deleted})
+}
+
+// inTx runs fn in a transaction on the primary, committing only if it succeeds. fn
+// gets the transaction's context, bounded by AcquireTimeout (dbContext).
+func (tc *TestController) inTx(r *http.Request, fn func(ctx context.Context, tx *sql.Tx) error) error {
+    ctx, cancel := tc.dbContext(r.Context())
+    defer cancel()
+    tx, err := tc.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    if err := fn(ctx, tx); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// writeAtomicFailure reports a rolled-back atomic bulk request. Item-level failures
+// (not found, constraint violations) return the per-item results with that item's
+// status; anything else is treated as a plain DB error.
+func (tc *TestController) writeAtomicFailure(w http.ResponseWriter, r *http.Request, results []bulkItemResult, err error) {
+    for _, result := range results {
+        if result.Status == http.StatusNotFound || result.Status == http.StatusConflict {
+            WriteJSON(w, result.Status, bulkResponse{Results: results})
+            return
+        }
+    }
+    writeDBError(w, r, err)
+}
+
+// setItemResult records the outcome of one best-effort item
+func (tc *TestController) setItemResult(r *http.Request, result *bulkItemResult, operation string, id models.ID, okStatus int, err error) {
+    if err != nil {
+        result.Status = itemErrorStatus(err)
+        result.Error = itemErrorMessage(err)
+        if result.Status == http.StatusInternalServerError {
+            LoggerFromContext(r.Context()).Printf("[DB ERROR] bulk %s item %d: %v", operation, result.Index, err)
+        }
+        return
+    }
+    result.Status = okStatus
+    result.Id = &id
+    tc.Audit.Record(r, operation, id)
+}
+
+// requireRowAffected turns an UPDATE/DELETE that matched nothing into sql.ErrNoRows
+func requireRowAffected(result sql.Result) error {
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rowsAffected == 0 {
+        return sql.ErrNoRows
+    }
+    return nil
+}
+
+// itemErrorStatus maps an item's error to the status reported for that item
+func itemErrorStatus(err error) int {
+    switch {
+    case dberr.IsNotFound(err):
+        return http.StatusNotFound
+    case dberr.IsUniqueViolation(err), dberr.IsForeignKeyViolation(err):
+        return http.StatusConflict
+    default:
+        return http.StatusInternalServerError
+    }
+}
+
+// itemErrorMessage is the client-facing message for an item's error
+func itemErrorMessage(err error) string {
+    switch {
+    case dberr.IsNotFound(err):
+        return "project not found"
+    case dberr.IsForeignKeyViolation(err):
+        return "cannot delete: project is referenced by other records"
+    case dberr.IsUniqueViolation(err):
+        return "a conflicting record already exists"
+    default:
+        return err.Error()
+    }
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+// bulkCreate posts body to BulkCreate with the given query string
+func bulkCreate(tc *TestController, query, body string) (*httptest.ResponseRecorder, bulkResponse) {
+    w := httptest.NewRecorder()
+    req := httptest.NewRequest(http.MethodPost, "/api/test/bulk"+query, strings.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+    tc.BulkCreate(w, req)
+    var resp bulkResponse
+    json.Unmarshal(w.Body.Bytes(), &resp)
+    return w, resp
+}
+
+// mixedBatch has valid names around an invalid (empty) one
+var mixedBatch = []string{"alpha", "", "beta", "gamma"}
+
+func mixedBatchBody() string {
+    items := make([]string, len(mixedBatch))
+    for i, name := range mixedBatch {
+        items[i] = `{"Name":"` + name + `"}`
+    }
+    return "[" + strings.Join(items, ",") + "]"
+}
+
+func TestBulkCreateAtomicRejectsMixedBatch(t *testing.T) {
+    pt := &projectTable{}
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    
+    w, resp := bulkCreate(tc, "", mixedBatchBody())
+    if w.Code != http.StatusBadRequest {
+        t.Fatalf("status = %d, want 400: %s", w.Code, w.Body)
+    }
+    if len(resp.Results) != len(mixedBatch) {
+        t.Fatalf("%d results, want %d", len(resp.Results), len(mixedBatch))
+    }
+    for i, result := range resp.Results {
+        if result.Index != i {
+            t.Errorf("results[%d].index = %d", i, result.Index)
+        }
+        if result.Id != nil {
+            t.Errorf("results[%d] has id %v in a rejected batch", i, *result.Id)
+        }
+    }
+    if resp.Results[1].Status != http.StatusBadRequest || resp.Results[1].Error == "" {
+        t.Errorf("invalid item result = %+v, want 400 with an error", resp.Results[1])
+    }
+    if len(pt.rows) != 0 {
+        t.Errorf("%d projects written by a rejected atomic batch", len(pt.rows))
+    }
+}
+
+func TestBulkCreateBestEffortReportsEachItemInInputOrder(t *testing.T) {
+    pt := &projectTable{}
+    pt.add("existing", "")
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    
+    w, resp := bulkCreate(tc, "?atomic=false", mixedBatchBody())
+    if w.Code != http.StatusMultiStatus {
+        t.Fatalf("status = %d, want 207: %s", w.Code, w.Body)
+    }
+    if len(resp.Results) != len(mixedBatch) {
+        t.Fatalf("%d results, want %d", len(resp.Results), len(mixedBatch))
+    }
+    
+    names := make(map[int64]string)
+    for _, row := range pt.rows {
+        names[row.id] = row.name
+    }
+    for i, result := range resp.Results {
+        if result.Index != i {
+            t.Errorf("results[%d].index = %d", i, result.Index)
+        }
+        if mixedBatch[i] == "" {
+            if result.Status != http.StatusBadRequest || result.Id != nil {
+                t.Errorf("results[%d] = %+v, want 400 without an id", i, result)
+            }
+            continue
+        }
+        if result.Status != http.StatusCreated || result.Id == nil {
+            t.Errorf("results[%d] = %+v, want 201 with an id", i, result)
+            continue
+        }
+        // Each id must be the row created from the input at the same position
+        if got := names[int64(*result.Id)]; got != mixedBatch[i] {
+            t.Errorf("results[%d] id %d is project %q, want %q", i, *result.Id, got, mixedBatch[i])
+        }
+    }
+    if len(pt.rows) != 1+len(mixedBatch)-1 {
+        t.Errorf("%d projects stored, want the existing one plus %d", len(pt.rows), len(mixedBatch)-1)
+    }
+}
+
+func TestBulkCreateAtomicReturnsIdsInInputOrder(t *testing.T) {
+    pt := &projectTable{}
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    
+    w, resp := bulkCreate(tc, "?atomic=true", `[{"Name":"one"},{"Name":"two"},{"Name":"three"}]`)
+    if w.Code != http.StatusCreated {
+        t.Fatalf("status = %d, want 201: %s", w.Code, w.Body)
+    }
+    names := make(map[int64]string)
+    for _, row := range pt.rows {
+        names[row.id] = row.name
+    }
+    for i, want := range []string{"one", "two", "three"} {
+        result := resp.Results[i]
+        if result.Index != i || result.Status != http.StatusCreated || result.Id == nil {
+            t.Errorf("results[%d] = %+v, want index %d, 201 and an id", i, result, i)
+            continue
+        }
+        if got := names[int64(*result.Id)]; got != want {
+            t.Errorf("results[%d] id %d is project %q, want %q", i, *result.Id, got, want)
+        }
+    }
+}
+
+func deleteAll(tc *TestController, query string) *httptest.ResponseRecorder {
+    w := httptest.NewRecorder()
+    tc.DeleteAll(w, httptest.NewRequest(http.MethodDelete, "/api/test"+query, nil))
+    return w
+}
+
+func TestDeleteAllNeedsAllowDestructive(t *testing.T) {
+    pt := &projectTable{}
+    pt.add("alpha", "")
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    
+    if w := deleteAll(tc, "?confirm=true"); w.Code != http.StatusForbidden {
+        t.Errorf("status = %d, want 403: %s", w.Code, w.Body)
+    }
+    if len(pt.rows) != 1 {
+        t.Errorf("%d projects left, want 1", len(pt.rows))
+    }
+}
+
+func TestDeleteAllNeedsConfirm(t *testing.T) {
+    pt := &projectTable{}
+    pt.add("alpha", "")
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    tc.AllowDestructive = true
+    
+    for _, query := range []string{"", "?confirm=false", "?confirm=1"} {
+        if w := deleteAll(tc, query); w.Code != http.StatusBadRequest {
+            t.Errorf("DELETE /api/test%s = %d, want 400", query, w.Code)
+        }
+    }
+    if len(pt.rows) != 1 {
+        t.Errorf("%d projects left, want 1", len(pt.rows))
+    }
+}
+
+func TestDeleteAllReportsDeletedCount(t *testing.T) {
+    pt := &projectTable{}
+    pt.add("alpha", "")
+    pt.add("beta", "")
+    pt.add("gamma", "")
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    tc.AllowDestructive = true
+    
+    w := deleteAll(tc, "?confirm=true")
+    if w.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+    }
+    var body map[string]int64
+    if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["deleted"] != 3 {
+        t.Errorf("body %s, want {\"deleted\":3}", w.Body)
+    }
+    if len(pt.rows) != 0 {
+        t.Errorf("%d projects left, want none", len(pt.rows))
+    }
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "io"
+    "mime"
+    "net/http"
+    "unicode/utf8"
+
+    "backend/Models"
+)
+
+// errUnsupportedMediaType is returned by decodeProject for bodies it can't parse
+var errUnsupportedMediaType = errors.New("unsupported Content-Type")
+
+// errInvalidUTF8 is returned for a body (or form value) that isn't valid UTF-8
+var errInvalidUTF8 = errors.New("must be valid UTF-8")
+
+// decodeJSON decodes a JSON request body into v. encoding/json silently replaces
+// invalid UTF-8 with U+FFFD, so the raw bytes are checked first and a malformed body
+// is rejected with errInvalidUTF8 instead of being stored with altered names.
+func decodeJSON(r *http.Request, v interface{}) error {
+    raw, err := io.ReadAll(r.Body)
+    if err != nil {
+        return err
+    }
+    if !utf8.Valid(raw) {
+        return errInvalidUTF8
+    }
+    return json.NewDecoder(bytes.NewReader(raw)).Decode(v)
+}
+
+// decodeProject reads a project from the request body, branching on Content-Type:
+// JSON (also assumed when no Content-Type is sent, as before) or form-encoded with a
+// Name field, for simple clients that can only post forms. Anything else returns
+// errUnsupportedMediaType.
+func decodeProject(r *http.Request) (models.TestProjects, error) {
+    var project models.TestProjects
+    
+    mediaType := "application/json"
+    if contentType := r.Header.Get("Content-Type"); contentType != "" {
+        parsed, _, err := mime.ParseMediaType(contentType)
+        if err != nil {
+            return project, errUnsupportedMediaType
+        }
+        mediaType = parsed
+    }
+    
+    switch mediaType {
+    case "application/json":
+        err := decodeJSON(r, &project)
+        return project, err
+    case "application/x-www-form-urlencoded":
+        if err := r.ParseForm(); err != nil {
+            return project, err
+        }
+        project.Name = r.PostForm.Get("Name")
+        if !utf8.ValidString(project.Name) {
+            return project, errInvalidUTF8
+        }
+        return project, nil
+    }
+    return project, errUnsupportedMediaType
+}
+
+// writeDecodeError reports a decodeProject failure: 415 for an unsupported
+// Content-Type, 400 for a malformed body (invalid UTF-8 included)
+func writeDecodeError(w http.ResponseWriter, err error) {
+    if err == errUnsupportedMediaType {
+        http.Error(w, "Unsupported Content-Type: use application/json or application/x-www-form-urlencoded", http.StatusUnsupportedMediaType)
+        return
+    }
+    http.Error(w, "Invalid body: "+err.Error(), http.StatusBadRequest)
+}
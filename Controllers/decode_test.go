@@ -0,0 +1,138 @@
+package controllers
+
+import (
+    "database/sql/driver"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestCreateRejectsInvalidUTF8With400(t *testing.T) {
+    tests := []struct {
+        name        string
+        contentType string
+        body        string
+    }{
+        {"json", "application/json", "{\"Name\": \"bad \xff\xfe name\"}"},
+        {"truncated sequence", "application/json", "{\"Name\": \"caf\xc3\"}"},
+        {"form", "application/x-www-form-urlencoded", "Name=bad%FFname"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            stub := &stubConnector{rows: [][]driver.Value{{int64(1), "x"}}}
+            tc := newStubController(t, stub, 1)
+            before := stub.queries.Load()
+            
+            r := httptest.NewRequest(http.MethodPost, "/api/test", strings.NewReader(tt.body))
+            r.Header.Set("Content-Type", tt.contentType)
+            w := httptest.NewRecorder()
+            tc.Create(w, r)
+            
+            if w.Code != http.StatusBadRequest {
+                t.Fatalf("status = %d, want 400", w.Code)
+            }
+            if !strings.Contains(w.Body.String(), "UTF-8") {
+                t.Errorf("body = %q, want it to mention UTF-8", w.Body.String())
+            }
+            if n := stub.queries.Load() - before; n != 0 {
+                t.Errorf("%d database calls, want none", n)
+            }
+        })
+    }
+}
+
+func TestCreateAcceptsMultibyteUTF8(t *testing.T) {
+    tc := newStubController(t, &stubConnector{rows: [][]driver.Value{{int64(1), "café ☕"}}}, 1)
+    w := httptest.NewRecorder()
+    tc.Create(w, httptest.NewRequest(http.MethodPost, "/api/test", strings.NewReader(`{"Name": "café ☕"}`)))
+    if w.Code != http.StatusCreated {
+        t.Fatalf("status = %d, want 201: %s", w.Code, w.Body.String())
+    }
+}
+
+func TestCreateReportsNULAsValidationError(t *testing.T) {
+    tc := newStubController(t, &stubConnector{}, 1)
+    w := httptest.NewRecorder()
+    tc.Create(w, httptest.NewRequest(http.MethodPost, "/api/test", strings.NewReader(`{"Name": "a\u0000b"}`)))
+    if w.Code != http.StatusUnprocessableEntity {
+        t.Fatalf("status = %d, want 422", w.Code)
+    }
+    if !strings.Contains(w.Body.String(), "control characters") {
+        t.Errorf("body = %q, want the control character problem", w.Body.String())
+    }
+}
+
+func TestGetByNameRejectsInvalidUTF8With400(t *testing.T) {
+    tc := newStubController(t, &stubConnector{}, 1)
+    w := httptest.NewRecorder()
+    tc.GetByName(w, httptest.NewRequest(http.MethodGet, "/api/test/by-name?name=bad%FFname", nil))
+    if w.Code != http.StatusBadRequest {
+        t.Fatalf("status = %d, want 400", w.Code)
+    }
+}
+
+func TestRenameRejectsInvalidUTF8With400(t *testing.T) {
+    tc := newStubController(t, &stubConnector{}, 1)
+    w := httptest.NewRecorder()
+    tc.Rename(w, httptest.NewRequest(http.MethodPost, "/api/test/rename", strings.NewReader("{\"from\": \"a\", \"to\": \"\xff\"}")))
+    if w.Code != http.StatusBadRequest {
+        t.Fatalf("status = %d, want 400", w.Code)
+    }
+}
+
+func TestDecodeProjectContentTypes(t *testing.T) {
+    tests := []struct {
+        contentType string
+        body        string
+        want        string
+        err         error
+    }{
+        {"application/x-www-form-urlencoded", "Name=road+map&Other=ignored", "road map", nil},
+        {"application/json", `{"Name":"road map"}`, "road map", nil},
+        {"application/json; charset=utf-8", `{"Name":"road map"}`, "road map", nil},
+        {"", `{"Name":"road map"}`, "road map", nil},
+        {"text/plain", "road map", "", errUnsupportedMediaType},
+        {"multipart/form-data; boundary=x", "--x--", "", errUnsupportedMediaType},
+        {"not a media type;", "", "", errUnsupportedMediaType},
+    }
+    for _, tt := range tests {
+        r := httptest.NewRequest(http.MethodPost, "/api/test", strings.NewReader(tt.body))
+        if tt.contentType != "" {
+            r.Header.Set("Content-Type", tt.contentType)
+        }
+        project, err := decodeProject(r)
+        if err != tt.err {
+            t.Errorf("Content-Type %q: err = %v, want %v", tt.contentType, err, tt.err)
+            continue
+        }
+        if project.Name != tt.want {
+            t.Errorf("Content-Type %q: Name = %q, want %q", tt.contentType, project.Name, tt.want)
+        }
+    }
+}
+
+func TestCreateAcceptsFormAndRejectsOtherContentTypes(t *testing.T) {
+    pt := &projectTable{}
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    create := func(contentType, body string) int {
+        r := httptest.NewRequest(http.MethodPost, "/api/test", strings.NewReader(body))
+        r.Header.Set("Content-Type", contentType)
+        w := httptest.NewRecorder()
+        tc.Create(w, r)
+        return w.Code
+    }
+    
+    if code := create("application/x-www-form-urlencoded", "Name=roadmap"); code != http.StatusCreated {
+        t.Errorf("form body = %d, want 201", code)
+    }
+    if code := create("application/json", `{"Name":"launch"}`); code != http.StatusCreated {
+        t.Errorf("JSON body = %d, want 201", code)
+    }
+    if code := create("text/xml", "<Name>x</Name>"); code != http.StatusUnsupportedMediaType {
+        t.Errorf("XML body = %d, want 415", code)
+    }
+    if len(pt.rows) != 2 || pt.rows[0].name != "roadmap" || pt.rows[1].name != "launch" {
+        t.Errorf("stored %+v, want roadmap and launch", pt.rows)
+    }
+}
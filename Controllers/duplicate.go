@@ -0,0 +1,104 @@
+package controllers
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "net/http"
+    "unicode/utf8"
+
+    "backend/Dberr"
+    "backend/Models"
+    "github.com/lib/pq"
+)
+
+// maxCopyCandidates bounds the search for a free copy name: "Copy of X", then
+// "Copy of X (2)" up to "Copy of X (maxCopyCandidates)"
+const maxCopyCandidates = 100
+
+// errNoFreeCopyName is returned when every candidate copy name is taken
+var errNoFreeCopyName = errors.New("no free copy name")
+
+// copyName is the n-th candidate name for a copy of source, trimming source so the
+// result stays within MaxNameLength
+func copyName(source string, n int) string {
+    prefix, suffix := "Copy of ", ""
+    if n > 1 {
+        suffix = fmt.Sprintf(" (%d)", n)
+    }
+    room := MaxNameLength - utf8.RuneCountInString(prefix) - utf8.RuneCountInString(suffix)
+    if runes := []rune(source); len(runes) > room {
+        source = string(runes[:room])
+    }
+    return prefix + source + suffix
+}
+
+// Duplicate creates a copy of project id named "Copy of <name>", numbered
+// ("Copy of <name> (2)", ...) when that name is already in use - within the board
+// when names are unique per board (NAME_UNIQUE_PER_BOARD). The source is read, the
+// free name picked and the copy inserted in one transaction; with the unique index a
+// concurrent duplicate that picked the same name fails with 409 rather than
+// creating a second row with it.
+func (tc *TestController) Duplicate(w http.ResponseWriter, r *http.Request, id models.ID) {
+    w, done := tc.track("Duplicate", w)
+    defer done()
+    
+    var project models.TestProjects
+    err := tc.inTx(r, func(ctx context.Context, tx *sql.Tx) error {
+        var source string
+        if err := tx.QueryRowContext(ctx, `SELECT "Name" FROM public."TestProjects" WHERE "Id" = $1`, id).Scan(&source); err != nil {
+            return err
+        }
+        
+        candidates := make([]string, maxCopyCandidates)
+        for i := range candidates {
+            candidates[i] = copyName(source, i+1)
+        }
+        query := `SELECT "Name" FROM public."TestProjects" WHERE "Name" = ANY($1)`
+        args := []interface{}{pq.Array(candidates)}
+        if tc.NameUniquePerBoard {
+            query += ` AND "BoardId" IS NOT DISTINCT FROM NULLIF($2, '')`
+            args = append(args, BoardIDFromContext(ctx))
+        }
+        rows, err := tx.QueryContext(ctx, query, args...)
+        if err != nil {
+            return err
+        }
+        taken := make(map[string]bool)
+        for rows.Next() {
+            var name string
+            if err := rows.Scan(&name); err != nil {
+                rows.Close()
+                return err
+            }
+            taken[name] = true
+        }
+        rows.Close()
+        if err := rows.Err(); err != nil {
+            return err
+        }
+        
+        for _, name := range candidates {
+            if !taken[name] {
+                return tx.QueryRowContext(ctx, tc.insertSQL(""), tc.insertArgs(r, name)...).Scan(&project.Id, &project.Name)
+            }
+        }
+        return errNoFreeCopyName
+    })
+    if dberr.IsNotFound(err) {
+        writeNotFound(w, id)
+        return
+    }
+    if err == errNoFreeCopyName {
+        WriteJSON(w, http.StatusConflict, map[string]string{"error": fmt.Sprintf("project already has %d copies", maxCopyCandidates)})
+        return
+    }
+    if err != nil {
+        writeDBError(w, r, err)
+        return
+    }
+    
+    tc.Audit.Record(r, "create", project.Id)
+    WriteJSON(w, http.StatusCreated, project)
+}
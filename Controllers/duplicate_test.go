@@ -0,0 +1,76 @@
+package controllers
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "strings"
+    "testing"
+    "unicode/utf8"
+    
+    "backend/Models"
+)
+
+func duplicate(tc *TestController, id int64) *httptest.ResponseRecorder {
+    w := httptest.NewRecorder()
+    tc.Duplicate(w, httptest.NewRequest(http.MethodPost, "/api/test/1/duplicate", nil), models.ID(id))
+    return w
+}
+
+func TestDuplicateNumbersCopies(t *testing.T) {
+    pt := &projectTable{}
+    id := pt.add("roadmap", "")
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    
+    for _, want := range []string{"Copy of roadmap", "Copy of roadmap (2)", "Copy of roadmap (3)"} {
+        w := duplicate(tc, id)
+        if w.Code != http.StatusCreated {
+            t.Fatalf("status = %d, want 201: %s", w.Code, w.Body)
+        }
+        var project models.TestProjects
+        if err := json.Unmarshal(w.Body.Bytes(), &project); err != nil {
+            t.Fatalf("body %s: %v", w.Body, err)
+        }
+        if project.Name != want {
+            t.Errorf("copy is named %q, want %q", project.Name, want)
+        }
+        if project.Id == models.ID(id) {
+            t.Errorf("copy has the source's id %d", id)
+        }
+    }
+    if len(pt.rows) != 4 {
+        t.Errorf("%d projects stored, want the source and 3 copies", len(pt.rows))
+    }
+}
+
+func TestDuplicateMissingSourceIsNotFound(t *testing.T) {
+    pt := &projectTable{}
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    
+    if w := duplicate(tc, 42); w.Code != http.StatusNotFound {
+        t.Errorf("status = %d, want 404: %s", w.Code, w.Body)
+    }
+    if len(pt.rows) != 0 {
+        t.Errorf("%d projects stored", len(pt.rows))
+    }
+}
+
+func TestCopyNameStaysWithinMaxNameLength(t *testing.T) {
+    long := strings.Repeat("é", MaxNameLength)
+    for _, n := range []int{1, 2, maxCopyCandidates} {
+        name := copyName(long, n)
+        if got := utf8.RuneCountInString(name); got != MaxNameLength {
+            t.Errorf("copyName(long, %d) is %d characters, want %d", n, got, MaxNameLength)
+        }
+        if !strings.HasPrefix(name, "Copy of é") {
+            t.Errorf("copyName(long, %d) = %q, want the prefix kept", n, name)
+        }
+        if n > 1 && !strings.HasSuffix(name, "("+strconv.Itoa(n)+")") {
+            t.Errorf("copyName(long, %d) = %q, want the number kept", n, name)
+        }
+    }
+    if name := copyName("short", 2); name != "Copy of short (2)" {
+        t.Errorf("copyName(short, 2) = %q", name)
+    }
+}
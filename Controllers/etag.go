@@ -0,0 +1,63 @@
+package controllers
+
+import (
+    "context"
+    "crypto/sha256"
+    "database/sql"
+    "encoding/hex"
+    "errors"
+    "strings"
+
+    "backend/Models"
+)
+
+// errPreconditionFailed means the If-Match header didn't match the current ETag
+var errPreconditionFailed = errors.New("precondition failed")
+
+// projectETag is a strong ETag derived from the project's current contents
+func projectETag(project models.TestProjects) string {
+    sum := sha256.Sum256([]byte(project.Id.String() + "\x00" + project.Name))
+    return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// ifMatchSatisfied reports whether an If-Match header value matches etag using
+// strong comparison ("*" matches any existing resource; weak tags never match)
+func ifMatchSatisfied(ifMatch, etag string) bool {
+    for _, candidate := range strings.Split(ifMatch, ",") {
+        candidate = strings.TrimSpace(candidate)
+        if candidate == "*" || candidate == etag {
+            return true
+        }
+    }
+    return false
+}
+
+// execIfMatch runs stmt in a transaction after locking the project row and checking
+// its current ETag against ifMatch. Returns sql.ErrNoRows if the project doesn't
+// exist and errPreconditionFailed if the ETag doesn't match.
+func (tc *TestController) execIfMatch(ctx context.Context, ifMatch string, id models.ID, stmt *sql.Stmt, args ...interface{}) (sql.Result, error) {
+    tx, err := tc.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback()
+
+    var current models.TestProjects
+    err = tx.QueryRowContext(ctx, `SELECT "Id", "Name" FROM public."TestProjects" WHERE "Id" = $1 FOR UPDATE`, id).
+        Scan(&current.Id, &current.Name)
+    if err != nil {
+        return nil, err
+    }
+    if !ifMatchSatisfied(ifMatch, projectETag(current)) {
+        return nil, errPreconditionFailed
+    }
+
+    result, err := tx.StmtContext(ctx, stmt).ExecContext(ctx, args...)
+    if err != nil {
+        return nil, err
+    }
+    if err := tx.Commit(); err != nil {
+        return nil, err
+    }
+    return result, nil
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    
+    "backend/Models"
+)
+
+func TestIfMatchGuardsWrites(t *testing.T) {
+    writes := []struct {
+        name string
+        call func(tc *TestController, w http.ResponseWriter, req *http.Request, id models.ID)
+        req  func() *http.Request
+    }{
+        {"Update", (*TestController).Update, func() *http.Request {
+            req := httptest.NewRequest(http.MethodPut, "/api/test/1", strings.NewReader(`{"Name":"final"}`))
+            req.Header.Set("Content-Type", "application/json")
+            return req
+        }},
+        {"Patch", (*TestController).Patch, func() *http.Request {
+            req := httptest.NewRequest(http.MethodPatch, "/api/test/1", strings.NewReader(`{"Name":"final"}`))
+            req.Header.Set("Content-Type", mergePatchMediaType)
+            return req
+        }},
+        {"Delete", (*TestController).Delete, func() *http.Request {
+            return httptest.NewRequest(http.MethodDelete, "/api/test/1", nil)
+        }},
+    }
+    cases := []struct {
+        name    string
+        ifMatch func(current string) string
+        want    int
+    }{
+        {"current", func(current string) string { return current }, http.StatusOK},
+        {"one of several", func(current string) string { return `"0000000000000000", ` + current }, http.StatusOK},
+        {"wildcard", func(string) string { return "*" }, http.StatusOK},
+        {"stale", func(string) string { return `"0000000000000000"` }, http.StatusPreconditionFailed},
+        {"weak", func(current string) string { return "W/" + current }, http.StatusPreconditionFailed},
+        {"absent", func(string) string { return "" }, http.StatusOK},
+    }
+    
+    for _, write := range writes {
+        for _, c := range cases {
+            t.Run(write.name+"/"+c.name, func(t *testing.T) {
+                pt := &projectTable{}
+                id := pt.add("draft", "")
+                tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+                current := projectETag(models.TestProjects{Id: models.ID(id), Name: "draft"})
+                
+                req := write.req()
+                if ifMatch := c.ifMatch(current); ifMatch != "" {
+                    req.Header.Set("If-Match", ifMatch)
+                }
+                w := httptest.NewRecorder()
+                write.call(tc, w, req, models.ID(id))
+                if w.Code != c.want {
+                    t.Fatalf("status = %d, want %d: %s", w.Code, c.want, w.Body)
+                }
+                
+                unchanged := len(pt.rows) == 1 && pt.rows[0].name == "draft"
+                if c.want == http.StatusPreconditionFailed && !unchanged {
+                    t.Errorf("project changed despite 412: %+v", pt.rows)
+                }
+                if c.want == http.StatusOK && unchanged {
+                    t.Errorf("project unchanged after %d", w.Code)
+                }
+            })
+        }
+    }
+}
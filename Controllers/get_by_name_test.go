@@ -0,0 +1,78 @@
+package controllers
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "reflect"
+    "testing"
+    
+    "backend/Models"
+)
+
+func getByName(tc *TestController, name string) *httptest.ResponseRecorder {
+    w := httptest.NewRecorder()
+    tc.GetByName(w, httptest.NewRequest(http.MethodGet, "/api/test/by-name?name="+url.QueryEscape(name), nil))
+    return w
+}
+
+func TestGetByNameFindsProject(t *testing.T) {
+    pt := &projectTable{}
+    pt.add("alpha", "")
+    id := pt.add("roadmap", "")
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    
+    w := getByName(tc, "roadmap")
+    if w.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+    }
+    var project models.TestProjects
+    if err := json.Unmarshal(w.Body.Bytes(), &project); err != nil {
+        t.Fatalf("body %s: %v", w.Body, err)
+    }
+    if project.Id != models.ID(id) || project.Name != "roadmap" {
+        t.Errorf("got %+v, want project %d named roadmap", project, id)
+    }
+    if w.Header().Get("ETag") == "" {
+        t.Error("no ETag on the found project")
+    }
+}
+
+func TestGetByNameMissingIsNotFound(t *testing.T) {
+    pt := &projectTable{}
+    pt.add("alpha", "")
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    
+    w := getByName(tc, "roadmap")
+    if w.Code != http.StatusNotFound {
+        t.Fatalf("status = %d, want 404: %s", w.Code, w.Body)
+    }
+    var body errorBody
+    if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error.Code != "not_found" {
+        t.Errorf("body %s, want a not_found error", w.Body)
+    }
+}
+
+func TestGetByNameAmbiguousListsIds(t *testing.T) {
+    pt := &projectTable{}
+    first := pt.add("roadmap", "")
+    pt.add("alpha", "")
+    second := pt.add("roadmap", "")
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    
+    w := getByName(tc, "roadmap")
+    if w.Code != http.StatusConflict {
+        t.Fatalf("status = %d, want 409: %s", w.Code, w.Body)
+    }
+    var body ambiguousNameBody
+    if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+        t.Fatalf("body %s: %v", w.Body, err)
+    }
+    if body.Error.Code != "ambiguous" {
+        t.Errorf("error code = %q, want ambiguous", body.Error.Code)
+    }
+    if want := []models.ID{models.ID(first), models.ID(second)}; !reflect.DeepEqual(body.Ids, want) {
+        t.Errorf("ids = %v, want %v", body.Ids, want)
+    }
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+    "encoding/csv"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "strings"
+
+    "backend/Models"
+    "github.com/lib/pq"
+)
+
+// maxImportBytes caps the size of an import body
+const maxImportBytes = 32 << 20
+
+// importRowError describes a rejected row; Line is the 1-based CSV line
+type importRowError struct {
+    Line  int    `json:"line"`
+    Error string `json:"error"`
+}
+
+// importResult is the response body of an import
+type importResult struct {
+    Imported int              `json:"imported"`
+    Errors   []importRowError `json:"errors,omitempty"`
+}
+
+// importRow is a parsed CSV row awaiting insertion
+type importRow struct {
+    line int
+    name string
+}
+
+// Import bulk-loads projects from a CSV body with one Name per row (an optional
+// "Name" header row is skipped).
+//
+// By default the import is all-or-nothing: every row is validated first and the valid
+// set is streamed in with COPY inside one transaction, which is what makes imports of
+// tens of thousands of rows practical. With ?report=true rows are instead inserted one
+// by one so invalid or failing rows can be skipped and reported individually; only
+// that mode produces per-row audit entries, since COPY doesn't return the new ids.
+func (tc *TestController) Import(w http.ResponseWriter, r *http.Request) {
+    w, done := tc.track("Import", w)
+    defer done()
+    
+    rows, rowErrors, err := parseImport(http.MaxBytesReader(w, r.Body, maxImportBytes))
+    var maxBytesErr *http.MaxBytesError
+    if errors.As(err, &maxBytesErr) {
+        http.Error(w, fmt.Sprintf("Import body exceeds %d bytes", maxImportBytes), http.StatusRequestEntityTooLarge)
+        return
+    }
+    if err != nil {
+        http.Error(w, "Invalid CSV: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    
+    if r.URL.Query().Get("report") == "true" {
+        tc.importRowByRow(w, r, rows, rowErrors)
+        return
+    }
+    
+    if len(rowErrors) > 0 {
+        WriteJSON(w, http.StatusBadRequest, importResult{Errors: rowErrors})
+        return
+    }
+    if err := tc.copyIn(r, rows); err != nil {
+        writeDBError(w, r, err)
+        return
+    }
+    WriteJSON(w, http.StatusCreated, importResult{Imported: len(rows)})
+}
+
+// parseImport reads the CSV body into the rows to insert and the rows rejected by
+// validation. The error is the read or CSV syntax error that stops the whole import.
+func parseImport(body io.Reader) ([]importRow, []importRowError, error) {
+    reader := csv.NewReader(body)
+    reader.FieldsPerRecord = -1
+    
+    var rows []importRow
+    var rowErrors []importRowError
+    for {
+        record, err := reader.Read()
+        if err == io.EOF {
+            return rows, rowErrors, nil
+        }
+        if err != nil {
+            return nil, nil, err
+        }
+        
+        line, _ := reader.FieldPos(0)
+        name := strings.TrimSpace(record[0])
+        if len(rows) == 0 && len(rowErrors) == 0 && strings.EqualFold(name, "Name") {
+            continue
+        }
+        if err := validateName(name); err != nil {
+            rowErrors = append(rowErrors, importRowError{Line: line, Error: err.Error()})
+            continue
+        }
+        rows = append(rows, importRow{line: line, name: name})
+    }
+}
+
+// copyIn loads rows with COPY in a single transaction. The COPY target is
+// schema-qualified (public."TestProjects"), so like the prepared statements it
+// doesn't depend on the connection's search_path.
+func (tc *TestController) copyIn(r *http.Request, rows []importRow) error {
+    ctx, cancel := tc.dbContext(r.Context())
+    defer cancel()
+    tx, err := tc.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    
+    columns := []string{"Name"}
+    if tc.NameUniquePerBoard {
+        columns = append(columns, "BoardId")
+    }
+    stmt, err := tx.PrepareContext(ctx, pq.CopyInSchema("public", "TestProjects", columns...))
+    if err != nil {
+        return err
+    }
+    for _, row := range rows {
+        args := []interface{}{row.name}
+        if tc.NameUniquePerBoard {
+            // COPY has no NULLIF; a nil value is written as NULL
+            var board interface{}
+            if id := BoardIDFromContext(ctx); id != "" {
+                board = id
+            }
+            args = append(args, board)
+        }
+        if _, err := stmt.ExecContext(ctx, args...); err != nil {
+            stmt.Close()
+            return err
+        }
+    }
+    // The final Exec without arguments flushes the COPY
+    if _, err := stmt.ExecContext(ctx); err != nil {
+        stmt.Close()
+        return err
+    }
+    if err := stmt.Close(); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// importRowByRow inserts each valid row on its own, collecting the rows that fail
+// alongside those that were already rejected by validation
+func (tc *TestController) importRowByRow(w http.ResponseWriter, r *http.Request, rows []importRow, rowErrors []importRowError) {
+    result := importResult{Errors: rowErrors}
+    for _, row := range rows {
+        var id models.ID
+        var name string
+        ctx, cancel := tc.dbContext(r.Context())
+        err := tc.stmts.insert.QueryRowContext(ctx, tc.insertArgs(r, row.name)...).Scan(&id, &name)
+        cancel()
+        if r.Context().Err() != nil {
+            writeDBError(w, r, err)
+            return
+        }
+        if err != nil {
+            result.Errors = append(result.Errors, importRowError{Line: row.line, Error: err.Error()})
+            continue
+        }
+        tc.Audit.Record(r, "import", id)
+        result.Imported++
+    }
+    
+    status := http.StatusCreated
+    if len(result.Errors) > 0 {
+        status = http.StatusMultiStatus
+    }
+    WriteJSON(w, status, result)
+}
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+    "context"
+    "database/sql/driver"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestParseImport(t *testing.T) {
+    body := "Name\nalpha\n  beta  \n\n\"multi\nline\"\n" + strings.Repeat("x", MaxNameLength+1) + "\ngamma,ignored\n"
+    rows, rowErrors, err := parseImport(strings.NewReader(body))
+    if err != nil {
+        t.Fatalf("parseImport: %v", err)
+    }
+    
+    wantRows := []importRow{{line: 2, name: "alpha"}, {line: 3, name: "beta"}, {line: 8, name: "gamma"}}
+    if !reflect.DeepEqual(rows, wantRows) {
+        t.Errorf("rows = %+v, want %+v", rows, wantRows)
+    }
+    // The quoted name spans lines 5-6 and is rejected for its newline; the blank
+    // line 4 is skipped by the CSV reader
+    if len(rowErrors) != 2 || rowErrors[0].Line != 5 || rowErrors[1].Line != 7 {
+        t.Fatalf("rowErrors = %+v, want lines 5 and 7", rowErrors)
+    }
+    if !strings.Contains(rowErrors[0].Error, "control characters") {
+        t.Errorf("line 5 error = %q, want the control character problem", rowErrors[0].Error)
+    }
+    if !strings.Contains(rowErrors[1].Error, "at most") {
+        t.Errorf("line 7 error = %q, want the length problem", rowErrors[1].Error)
+    }
+}
+
+func TestParseImportSkipsHeaderOnlyOnFirstRow(t *testing.T) {
+    rows, _, err := parseImport(strings.NewReader("alpha\nName\n"))
+    if err != nil {
+        t.Fatalf("parseImport: %v", err)
+    }
+    if len(rows) != 2 || rows[1].name != "Name" {
+        t.Errorf("rows = %+v, want a later \"Name\" row kept as data", rows)
+    }
+}
+
+func TestParseImportRejectsMalformedCSV(t *testing.T) {
+    if _, _, err := parseImport(strings.NewReader("alpha\n\"unterminated\n")); err == nil {
+        t.Error("parseImport accepted an unterminated quote")
+    }
+}
+
+func importRequest(body, query string) *http.Request {
+    return httptest.NewRequest(http.MethodPost, "/api/test/import"+query, strings.NewReader(body))
+}
+
+func decodeImportResult(t *testing.T, w *httptest.ResponseRecorder) importResult {
+    t.Helper()
+    var result importResult
+    if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+        t.Fatalf("decode: %v", err)
+    }
+    return result
+}
+
+func TestImportRejectsInvalidRowsWithoutTouchingTheDatabase(t *testing.T) {
+    stub := &stubConnector{}
+    tc := newStubController(t, stub, 1)
+    before := stub.queries.Load()
+    
+    w := httptest.NewRecorder()
+    tc.Import(w, importRequest("alpha\n\"\"\n", ""))
+    
+    if w.Code != http.StatusBadRequest {
+        t.Fatalf("status = %d, want 400", w.Code)
+    }
+    if result := decodeImportResult(t, w); len(result.Errors) != 1 || result.Errors[0].Line != 2 {
+        t.Errorf("errors = %+v, want line 2", result.Errors)
+    }
+    if n := stub.queries.Load() - before; n != 0 {
+        t.Errorf("%d database calls, want none", n)
+    }
+}
+
+func TestImportCopiesValidRows(t *testing.T) {
+    stub := &stubConnector{}
+    tc := newStubController(t, stub, 1)
+    before := stub.queries.Load()
+    
+    w := httptest.NewRecorder()
+    tc.Import(w, importRequest("Name\nalpha\nbeta\n", ""))
+    
+    if w.Code != http.StatusCreated {
+        t.Fatalf("status = %d, want 201", w.Code)
+    }
+    if result := decodeImportResult(t, w); result.Imported != 2 {
+        t.Errorf("imported = %d, want 2", result.Imported)
+    }
+    // One COPY row per name plus the flushing Exec
+    if n := stub.queries.Load() - before; n != 3 {
+        t.Errorf("%d database calls, want 3", n)
+    }
+}
+
+func TestImportReportModeInsertsRowByRow(t *testing.T) {
+    stub := &stubConnector{rows: [][]driver.Value{{int64(1), "alpha"}}}
+    tc := newStubController(t, stub, 1)
+    before := stub.queries.Load()
+    
+    w := httptest.NewRecorder()
+    tc.Import(w, importRequest("alpha\n\"\"\nbeta\n", "?report=true"))
+    
+    if w.Code != http.StatusMultiStatus {
+        t.Fatalf("status = %d, want 207", w.Code)
+    }
+    result := decodeImportResult(t, w)
+    if result.Imported != 2 {
+        t.Errorf("imported = %d, want 2", result.Imported)
+    }
+    if len(result.Errors) != 1 || result.Errors[0].Line != 2 {
+        t.Errorf("errors = %+v, want line 2", result.Errors)
+    }
+    if n := stub.queries.Load() - before; n != 2 {
+        t.Errorf("%d inserts, want one per valid row", n)
+    }
+}
+
+// importBenchRows is the size of the benchmarked imports
+const importBenchRows = 10000
+
+// benchImportBody is a CSV of importBenchRows names unique to this run
+func benchImportBody(run int) string {
+    var body strings.Builder
+    for i := 0; i < importBenchRows; i++ {
+        fmt.Fprintf(&body, "bench-%d-%d\n", run, i)
+    }
+    return body.String()
+}
+
+// benchmarkImport imports importBenchRows rows per iteration into the Postgres at
+// TEST_DATABASE_URL, rolling the table back to its starting size afterwards
+func benchmarkImport(b *testing.B, query string) {
+    db := openBenchDB(b)
+    tc := NewTestController(db)
+    if err := tc.PrepareStatements(context.Background()); err != nil {
+        b.Fatalf("PrepareStatements: %v", err)
+    }
+    defer tc.Close()
+    b.Cleanup(func() {
+        db.Exec(`DELETE FROM public."TestProjects" WHERE "Name" LIKE 'bench-%'`)
+    })
+    
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        b.StopTimer()
+        r := importRequest(benchImportBody(i), query)
+        w := httptest.NewRecorder()
+        b.StartTimer()
+        
+        tc.Import(w, r)
+        if w.Code != http.StatusCreated {
+            b.Fatalf("status = %d: %s", w.Code, w.Body.String())
+        }
+    }
+}
+
+func BenchmarkImportCopy(b *testing.B) {
+    benchmarkImport(b, "")
+}
+
+func BenchmarkImportRowByRow(b *testing.B) {
+    benchmarkImport(b, "?report=true")
+}
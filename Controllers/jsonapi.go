@@ -0,0 +1,83 @@
+package controllers
+
+import (
+    "strings"
+
+    "backend/Models"
+)
+
+// jsonAPIType is the JSON:API resource type for TestProjects
+const jsonAPIType = "test"
+
+// jsonAPIResource is a single JSON:API resource object
+type jsonAPIResource struct {
+    Type       string                 `json:"type"`
+    Id         string                 `json:"id"`
+    Attributes map[string]interface{} `json:"attributes"`
+}
+
+// toJSONAPI wraps a response value in the JSON:API document envelope:
+// a single project becomes {"data":{...}}, a page becomes {"data":[...],"meta":{...}}
+func toJSONAPI(v interface{}) interface{} {
+    switch value := v.(type) {
+    case models.TestProjects:
+        return map[string]interface{}{"data": projectResource(value)}
+    case []models.TestProjects:
+        return map[string]interface{}{"data": projectResources(value)}
+    case pageEnvelope:
+        var data []jsonAPIResource
+        switch items := value.Items.(type) {
+        case []models.TestProjects:
+            data = projectResources(items)
+        case []map[string]interface{}:
+            data = fieldResources(items)
+        }
+        meta := map[string]interface{}{"hasMore": value.HasMore, "limit": value.Limit, "offset": value.Offset}
+        if value.NextCursor != "" {
+            meta["nextCursor"] = value.NextCursor
+        }
+        if value.Total != nil {
+            meta["total"] = *value.Total
+            if value.Estimated {
+                meta["estimated"] = true
+            }
+        }
+        return map[string]interface{}{"data": data, "meta": meta}
+    }
+    return v
+}
+
+func projectResource(project models.TestProjects) jsonAPIResource {
+    return jsonAPIResource{
+        Type:       jsonAPIType,
+        Id:         project.Id.String(),
+        Attributes: map[string]interface{}{"name": project.Name},
+    }
+}
+
+func projectResources(projects []models.TestProjects) []jsonAPIResource {
+    data := make([]jsonAPIResource, 0, len(projects))
+    for _, project := range projects {
+        data = append(data, projectResource(project))
+    }
+    return data
+}
+
+// fieldResources converts field-selected items (see selectFields) into resources,
+// keeping only the selected attributes. selectFields keys the items with the
+// configured JSON field names, so the id is looked up the same way.
+func fieldResources(items []map[string]interface{}) []jsonAPIResource {
+    data := make([]jsonAPIResource, 0, len(items))
+    for _, item := range items {
+        resource := jsonAPIResource{Type: jsonAPIType, Attributes: map[string]interface{}{}}
+        for field, value := range item {
+            if field == models.JSONFieldName("Id") {
+                resource.Id = value.(models.ID).String()
+                continue
+            }
+            resource.Attributes[strings.ToLower(field)] = value
+        }
+        data = append(data, resource)
+    }
+    return data
+}
@@ -0,0 +1,29 @@
+package controllers
+
+import (
+    "reflect"
+    "testing"
+
+    "backend/Models"
+)
+
+func TestFieldResourcesTakesIdInEitherCase(t *testing.T) {
+    defer func(camel bool) { models.CamelCaseJSON = camel }(models.CamelCaseJSON)
+    
+    projects := []models.TestProjects{{Id: 42, Name: "answer"}}
+    for _, camel := range []bool{false, true} {
+        models.CamelCaseJSON = camel
+        
+        resources := fieldResources(selectFields(projects, []string{"Id", "Name"}))
+        if len(resources) != 1 {
+            t.Fatalf("camel=%v: %d resources, want 1", camel, len(resources))
+        }
+        got := resources[0]
+        if got.Id != "42" {
+            t.Errorf("camel=%v: id = %q, want \"42\"", camel, got.Id)
+        }
+        if want := map[string]interface{}{"name": "answer"}; !reflect.DeepEqual(got.Attributes, want) {
+            t.Errorf("camel=%v: attributes = %v, want %v", camel, got.Attributes, want)
+        }
+    }
+}
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+    "encoding/xml"
+    "fmt"
+    "net/http"
+    "net/url"
+    "strconv"
+    "strings"
+
+    "backend/Models"
+)
+
+// Built-in page sizes for list endpoints, used when the controller isn't configured otherwise
+const (
+    DefaultPageSize = 50
+    MaxPageSize     = 500
+)
+
+// MaxFields is the built-in cap on the number of entries in a fields parameter
+const MaxFields = 20
+
+// sortableColumns whitelists the columns clients may sort or select by,
+// mapping the public field name to its quoted SQL identifier
+var sortableColumns = map[string]string{
+    "Id":   `"Id"`,
+    "Name": `"Name"`,
+}
+
+// Count modes for list endpoints (the countMode query parameter). Every mode reports
+// hasMore; exact adds a COUNT(*) total and estimate a planner-estimated one.
+const (
+    countModeNone     = "none"
+    countModeExact    = "exact"
+    countModeEstimate = "estimate"
+)
+
+// listParams are the query parameters accepted by GetAll
+type listParams struct {
+    Pagination
+    Query    string
+    Sort     string
+    Dir      string
+    Fields   []string
+    Envelope bool
+}
+
+// pageEnvelope wraps a page of results with the pagination metadata. Total is only
+// present when a count was requested; Estimated marks a planner estimate. NextCursor
+// (the cursor parameter for the following page) is empty on the last page.
+type pageEnvelope struct {
+    XMLName    xml.Name    `json:"-" xml:"TestProjectsPage"`
+    Items      interface{} `json:"items" xml:"Items>TestProjects"`
+    Total      *int        `json:"total,omitempty" xml:"Total,omitempty"`
+    Estimated  bool        `json:"estimated,omitempty" xml:"Estimated,omitempty"`
+    HasMore    bool        `json:"hasMore" xml:"HasMore"`
+    Limit      int         `json:"limit" xml:"Limit"`
+    Offset     int         `json:"offset" xml:"Offset"`
+    NextCursor string      `json:"nextCursor,omitempty" xml:"NextCursor,omitempty"`
+}
+
+// singleValueListParams are the list parameters that take one value. Repeating one
+// (?limit=10&limit=20) is rejected unless LenientQuery is set, since only the first
+// value would be used and the client's mistake would pass silently.
+var singleValueListParams = []string{"limit", "offset", "cursor", "countMode", "q", "sort", "dir", "fields", "envelope"}
+
+// duplicateParam returns the first of names that appears more than once in query
+func duplicateParam(query url.Values, names []string) string {
+    for _, name := range names {
+        if len(query[name]) > 1 {
+            return name
+        }
+    }
+    return ""
+}
+
+// parseListParams reads the pagination parameters (see ParsePagination) plus q, sort,
+// dir, fields and envelope from the query string
+func (tc *TestController) parseListParams(r *http.Request) (listParams, error) {
+    if !tc.LenientQuery {
+        if name := duplicateParam(r.URL.Query(), singleValueListParams); name != "" {
+            return listParams{}, fmt.Errorf("duplicate query parameter: %s", name)
+        }
+    }
+    
+    pagination, err := tc.ParsePagination(r)
+    if err != nil {
+        return listParams{}, err
+    }
+    
+    query := r.URL.Query()
+    params := listParams{
+        Pagination: pagination,
+        Query:      strings.TrimSpace(query.Get("q")),
+        Sort:       "Id",
+        Dir:        "asc",
+    }
+
+    if raw := query.Get("sort"); raw != "" {
+        column, ok := lookupColumn(raw)
+        if !ok {
+            return params, fmt.Errorf("sort must be one of Id, Name")
+        }
+        params.Sort = column
+    }
+
+    if raw := query.Get("dir"); raw != "" {
+        dir := strings.ToLower(raw)
+        if dir != "asc" && dir != "desc" {
+            return params, fmt.Errorf("dir must be asc or desc")
+        }
+        params.Dir = dir
+    }
+
+    if raw := query.Get("fields"); raw != "" {
+        fields, err := tc.parseFields(raw)
+        if err != nil {
+            return params, err
+        }
+        params.Fields = fields
+    }
+
+    if raw := query.Get("envelope"); raw != "" {
+        envelope, err := strconv.ParseBool(raw)
+        if err != nil {
+            return params, fmt.Errorf("envelope must be true or false")
+        }
+        params.Envelope = envelope
+    }
+
+    return params, nil
+}
+
+// parseFields validates a comma-separated fields list. Every unknown, empty or
+// duplicated entry is reported in one error, and lists longer than MaxFields are
+// refused before any of them is looked at.
+func (tc *TestController) parseFields(raw string) ([]string, error) {
+    maxFields := tc.MaxFields
+    if maxFields <= 0 {
+        maxFields = MaxFields
+    }
+    entries := strings.Split(raw, ",")
+    if len(entries) > maxFields {
+        return nil, fmt.Errorf("at most %d fields can be requested", maxFields)
+    }
+    
+    var fields, invalid []string
+    seen := map[string]bool{}
+    for _, entry := range entries {
+        name := strings.TrimSpace(entry)
+        column, ok := lookupColumn(name)
+        switch {
+        case !ok:
+            invalid = append(invalid, fmt.Sprintf("unknown field %q", name))
+        case seen[column]:
+            invalid = append(invalid, fmt.Sprintf("duplicate field %q", name))
+        default:
+            seen[column] = true
+            fields = append(fields, column)
+        }
+    }
+    if len(invalid) > 0 {
+        return nil, fmt.Errorf("invalid fields: %s", strings.Join(invalid, ", "))
+    }
+    return fields, nil
+}
+
+// lookupColumn matches a client-supplied field name against the whitelist (case-insensitive)
+func lookupColumn(name string) (string, bool) {
+    for column := range sortableColumns {
+        if strings.EqualFold(column, name) {
+            return column, true
+        }
+    }
+    return "", false
+}
+
+// escapeLike escapes LIKE wildcards so q is matched as a literal substring
+func escapeLike(s string) string {
+    return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// selectFields projects each project down to the requested fields
+func selectFields(projects []models.TestProjects, fields []string) []map[string]interface{} {
+    selected := make([]map[string]interface{}, 0, len(projects))
+    for _, project := range projects {
+        item := make(map[string]interface{}, len(fields))
+        for _, field := range fields {
+            switch field {
+            case "Id":
+                item[models.JSONFieldName("Id")] = project.Id
+            case "Name":
+                item[models.JSONFieldName("Name")] = project.Name
+            }
+        }
+        selected = append(selected, item)
+    }
+    return selected
+}
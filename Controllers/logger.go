@@ -0,0 +1,45 @@
+package controllers
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "fmt"
+    "log"
+    "net/http"
+)
+
+type loggerKey struct{}
+
+// WithLogger returns a copy of ctx carrying logger
+func WithLogger(ctx context.Context, logger *log.Logger) context.Context {
+    return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFromContext returns the request-scoped logger stored by WithLogger, or the
+// standard logger when there is none (e.g. outside a request)
+func LoggerFromContext(ctx context.Context) *log.Logger {
+    if logger, ok := ctx.Value(loggerKey{}).(*log.Logger); ok {
+        return logger
+    }
+    return log.Default()
+}
+
+// NewRequestLogger returns a logger writing to the standard log output whose every
+// line carries the request id, method, path and board id after the timestamp
+func NewRequestLogger(r *http.Request, requestId, boardId string) *log.Logger {
+    if boardId == "" {
+        boardId = "-"
+    }
+    prefix := fmt.Sprintf("[request_id=%s method=%s path=%q board_id=%s] ", requestId, r.Method, r.URL.Path, boardId)
+    return log.New(log.Writer(), prefix, log.Flags()|log.Lmsgprefix)
+}
+
+// NewRequestId returns a random request id (16 hex characters)
+func NewRequestId() string {
+    buf := make([]byte, 8)
+    if _, err := rand.Read(buf); err != nil {
+        return ""
+    }
+    return hex.EncodeToString(buf)
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "mime"
+    "net/http"
+    "sort"
+    "strings"
+
+    "backend/Dberr"
+    "backend/Models"
+)
+
+// mergePatchMediaType is the only body PATCH accepts (JSON Merge Patch, RFC 7386)
+const mergePatchMediaType = "application/merge-patch+json"
+
+// errPatchNotObject is returned for a merge patch whose top level isn't a JSON object.
+// RFC 7386 would have such a patch replace the whole resource, which PUT already does.
+var errPatchNotObject = errors.New("merge patch must be a JSON object")
+
+// decodeMergePatch reads a merge patch body as its top-level members. A member whose
+// value is null is kept (as "null") since it means "clear this field".
+func decodeMergePatch(r *http.Request) (map[string]json.RawMessage, error) {
+    mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+    if err != nil || mediaType != mergePatchMediaType {
+        return nil, errUnsupportedMediaType
+    }
+    
+    var raw json.RawMessage
+    if err := decodeJSON(r, &raw); err != nil {
+        return nil, err
+    }
+    var patch map[string]json.RawMessage
+    if err := json.Unmarshal(raw, &patch); err != nil || patch == nil {
+        return nil, errPatchNotObject
+    }
+    return patch, nil
+}
+
+// applyMergePatch applies patch to project. Members absent from the patch are left
+// alone; keys match the JSON field names case-insensitively, as decoding does
+// elsewhere. Name is required, so null (clearing it) is a validation error, as are
+// changing Id and unknown members.
+func applyMergePatch(project *models.TestProjects, patch map[string]json.RawMessage) []FieldError {
+    // Sorted so the reported problems come out in a stable order
+    keys := make([]string, 0, len(patch))
+    for key := range patch {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    
+    var errs []FieldError
+    for _, key := range keys {
+        value := patch[key]
+        isNull := string(value) == "null"
+        switch {
+        case strings.EqualFold(key, "Name"):
+            field := models.JSONFieldName("Name")
+            if isNull {
+                errs = append(errs, FieldError{Field: field, Message: field + " is required and cannot be cleared"})
+                continue
+            }
+            if err := json.Unmarshal(value, &project.Name); err != nil {
+                errs = append(errs, FieldError{Field: field, Message: field + " must be a string"})
+                continue
+            }
+            errs = append(errs, fieldErrors(field, project.Name)...)
+        case strings.EqualFold(key, "Id"):
+            var id models.ID
+            if isNull || json.Unmarshal(value, &id) != nil || id != project.Id {
+                field := models.JSONFieldName("Id")
+                errs = append(errs, FieldError{Field: field, Message: field + " is read-only"})
+            }
+        default:
+            errs = append(errs, FieldError{Field: key, Message: key + " is not a known field"})
+        }
+    }
+    return errs
+}
+
+// Patch applies a JSON Merge Patch to a project. The current row is read and updated
+// in one transaction with the row locked, so concurrent patches to different fields
+// can't overwrite each other; If-Match is honoured as for Update.
+func (tc *TestController) Patch(w http.ResponseWriter, r *http.Request, id models.ID) {
+    w, done := tc.track("Patch", w)
+    defer done()
+    
+    patch, err := decodeMergePatch(r)
+    if err == errUnsupportedMediaType {
+        http.Error(w, "Unsupported Content-Type: use "+mergePatchMediaType, http.StatusUnsupportedMediaType)
+        return
+    }
+    if err != nil {
+        http.Error(w, "Invalid body: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    
+    var project models.TestProjects
+    var invalid []FieldError
+    err = tc.inTx(r, func(ctx context.Context, tx *sql.Tx) error {
+        err := tx.QueryRowContext(ctx, `SELECT "Id", "Name" FROM public."TestProjects" WHERE "Id" = $1 FOR UPDATE`, id).
+            Scan(&project.Id, &project.Name)
+        if err != nil {
+            return err
+        }
+        if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && !ifMatchSatisfied(ifMatch, projectETag(project)) {
+            return errPreconditionFailed
+        }
+        if invalid = applyMergePatch(&project, patch); len(invalid) > 0 {
+            return nil
+        }
+        _, err = tx.StmtContext(ctx, tc.stmts.update).ExecContext(ctx, project.Name, id)
+        return err
+    })
+    if dberr.IsNotFound(err) {
+        writeNotFound(w, id)
+        return
+    }
+    if err == errPreconditionFailed {
+        writePreconditionFailed(w, id)
+        return
+    }
+    if err != nil {
+        writeDBError(w, r, err)
+        return
+    }
+    if len(invalid) > 0 {
+        writeValidationErrors(w, invalid)
+        return
+    }
+    
+    tc.Audit.Record(r, "update", id)
+    w.Header().Set("ETag", projectETag(project))
+    WriteJSON(w, http.StatusOK, project)
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    
+    "backend/Models"
+)
+
+// patchProject sends body as a PATCH of project id with the given Content-Type
+func patchProject(tc *TestController, id int64, contentType, body string) *httptest.ResponseRecorder {
+    w := httptest.NewRecorder()
+    req := httptest.NewRequest(http.MethodPatch, "/api/test/1", strings.NewReader(body))
+    req.Header.Set("Content-Type", contentType)
+    tc.Patch(w, req, models.ID(id))
+    return w
+}
+
+func TestPatchSetsName(t *testing.T) {
+    pt := &projectTable{}
+    id := pt.add("draft", "")
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    
+    w := patchProject(tc, id, mergePatchMediaType, `{"Name":"final"}`)
+    if w.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+    }
+    var project models.TestProjects
+    if err := json.Unmarshal(w.Body.Bytes(), &project); err != nil || project.Name != "final" {
+        t.Errorf("body %s, want the renamed project", w.Body)
+    }
+    if pt.rows[0].name != "final" {
+        t.Errorf("stored name = %q, want %q", pt.rows[0].name, "final")
+    }
+}
+
+func TestPatchLeavesAbsentMembersAlone(t *testing.T) {
+    pt := &projectTable{}
+    id := pt.add("draft", "")
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    
+    w := patchProject(tc, id, mergePatchMediaType, `{}`)
+    if w.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+    }
+    if pt.rows[0].name != "draft" {
+        t.Errorf("stored name = %q after an empty patch, want %q", pt.rows[0].name, "draft")
+    }
+}
+
+func TestPatchRejectsClearingName(t *testing.T) {
+    pt := &projectTable{}
+    id := pt.add("draft", "")
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    
+    w := patchProject(tc, id, mergePatchMediaType, `{"Name":null}`)
+    if w.Code != http.StatusUnprocessableEntity {
+        t.Fatalf("status = %d, want 422: %s", w.Code, w.Body)
+    }
+    var body errorBody
+    if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || len(body.Error.Fields) != 1 {
+        t.Fatalf("body %s, want one field error", w.Body)
+    }
+    if field := body.Error.Fields[0].Field; field != models.JSONFieldName("Name") {
+        t.Errorf("field error is for %q, want the name", field)
+    }
+    if pt.rows[0].name != "draft" {
+        t.Errorf("stored name = %q after a rejected patch", pt.rows[0].name)
+    }
+}
+
+func TestPatchNeedsMergePatchContentType(t *testing.T) {
+    pt := &projectTable{}
+    id := pt.add("draft", "")
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    
+    for _, contentType := range []string{"application/json", "text/plain", ""} {
+        if w := patchProject(tc, id, contentType, `{"Name":"final"}`); w.Code != http.StatusUnsupportedMediaType {
+            t.Errorf("Content-Type %q: status = %d, want 415", contentType, w.Code)
+        }
+    }
+    if pt.rows[0].name != "draft" {
+        t.Errorf("stored name = %q after rejected patches", pt.rows[0].name)
+    }
+}
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "sort"
+    "sync"
+)
+
+// OperationMetrics counts controller operations by outcome
+// (success, not_found, validation_error, conflict, cancelled, db_error)
+type OperationMetrics struct {
+    mu     sync.Mutex
+    counts map[operationOutcome]uint64
+}
+
+type operationOutcome struct {
+    operation string
+    outcome   string
+}
+
+func NewOperationMetrics() *OperationMetrics {
+    return &OperationMetrics{counts: map[operationOutcome]uint64{}}
+}
+
+// Inc increments the counter for operation/outcome
+func (m *OperationMetrics) Inc(operation, outcome string) {
+    if m == nil {
+        return
+    }
+    m.mu.Lock()
+    m.counts[operationOutcome{operation, outcome}]++
+    m.mu.Unlock()
+}
+
+// Count returns the current value of an operation/outcome counter
+func (m *OperationMetrics) Count(operation, outcome string) uint64 {
+    if m == nil {
+        return 0
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return m.counts[operationOutcome{operation, outcome}]
+}
+
+// WritePrometheus writes the counters in the Prometheus text exposition format
+func (m *OperationMetrics) WritePrometheus(w io.Writer) {
+    if m == nil {
+        return
+    }
+    m.mu.Lock()
+    keys := make([]operationOutcome, 0, len(m.counts))
+    for key := range m.counts {
+        keys = append(keys, key)
+    }
+    values := make(map[operationOutcome]uint64, len(m.counts))
+    for key, value := range m.counts {
+        values[key] = value
+    }
+    m.mu.Unlock()
+
+    sort.Slice(keys, func(i, j int) bool {
+        if keys[i].operation != keys[j].operation {
+            return keys[i].operation < keys[j].operation
+        }
+        return keys[i].outcome < keys[j].outcome
+    })
+
+    fmt.Fprintln(w, "# HELP backend_operations_total Controller operations by outcome.")
+    fmt.Fprintln(w, "# TYPE backend_operations_total counter")
+    for _, key := range keys {
+        fmt.Fprintf(w, "backend_operations_total{operation=%q,outcome=%q} %d\n", key.operation, key.outcome, values[key])
+    }
+}
+
+// outcomeForStatus classifies a response status into a metrics outcome
+func outcomeForStatus(status int) string {
+    switch {
+    case status == 0:
+        // Nothing was written - the handler panicked
+        return "db_error"
+    case status < 400:
+        return "success"
+    case status == http.StatusNotFound:
+        return "not_found"
+    case status == http.StatusConflict || status == http.StatusPreconditionFailed:
+        return "conflict"
+    case status == statusClientClosedRequest:
+        return "cancelled"
+    case status < 500:
+        return "validation_error"
+    default:
+        return "db_error"
+    }
+}
+
+// statusRecorder remembers the status code written through it
+type statusRecorder struct {
+    http.ResponseWriter
+    status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+    if s.status == 0 {
+        s.status = status
+    }
+    s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+    if s.status == 0 {
+        s.status = http.StatusOK
+    }
+    return s.ResponseWriter.Write(b)
+}
+
+// Flush passes through to the underlying writer when it supports streaming
+func (s *statusRecorder) Flush() {
+    if s.status == 0 {
+        s.status = http.StatusOK
+    }
+    if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+        flusher.Flush()
+    }
+}
+
+// Hijack passes through to the underlying writer, failing if it can't be hijacked. A
+// hijacked connection is recorded as 101 Switching Protocols: nothing is written
+// through the recorder afterwards, and status 0 would count it as a panic.
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+    hijacker, ok := s.ResponseWriter.(http.Hijacker)
+    if !ok {
+        return nil, nil, fmt.Errorf("%T does not support hijacking", s.ResponseWriter)
+    }
+    conn, rw, err := hijacker.Hijack()
+    if err == nil && s.status == 0 {
+        s.status = http.StatusSwitchingProtocols
+    }
+    return conn, rw, err
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+    return s.ResponseWriter
+}
+
+// track wraps w so the operation's outcome is recorded when the returned func runs
+// (use with defer at the top of a handler)
+func (tc *TestController) track(operation string, w http.ResponseWriter) (http.ResponseWriter, func()) {
+    if tc.Metrics == nil {
+        return w, func() {}
+    }
+    recorder := &statusRecorder{ResponseWriter: w}
+    return recorder, func() {
+        tc.Metrics.Inc(operation, outcomeForStatus(recorder.status))
+    }
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+    "bufio"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "regexp"
+    "strings"
+    "testing"
+)
+
+func TestTrackCountsOperationOutcome(t *testing.T) {
+    tests := []struct {
+        status  int
+        outcome string
+    }{
+        {http.StatusOK, "success"},
+        {http.StatusCreated, "success"},
+        {http.StatusNotFound, "not_found"},
+        {http.StatusConflict, "conflict"},
+        {http.StatusPreconditionFailed, "conflict"},
+        {statusClientClosedRequest, "cancelled"},
+        {http.StatusUnprocessableEntity, "validation_error"},
+        {http.StatusServiceUnavailable, "db_error"},
+    }
+    for _, tt := range tests {
+        tc := &TestController{Metrics: NewOperationMetrics()}
+        w, done := tc.track("Update", httptest.NewRecorder())
+        w.WriteHeader(tt.status)
+        done()
+        
+        if n := tc.Metrics.Count("Update", tt.outcome); n != 1 {
+            t.Errorf("status %d: Update/%s = %d, want 1", tt.status, tt.outcome, n)
+        }
+        if n := tc.Metrics.Count("Create", tt.outcome); n != 0 {
+            t.Errorf("status %d counted against Create", tt.status)
+        }
+    }
+}
+
+func TestTrackCountsImplicitOKAndPanics(t *testing.T) {
+    tc := &TestController{Metrics: NewOperationMetrics()}
+    
+    w, done := tc.track("GetAll", httptest.NewRecorder())
+    w.Write([]byte("[]"))
+    done()
+    if n := tc.Metrics.Count("GetAll", "success"); n != 1 {
+        t.Errorf("Write without WriteHeader: GetAll/success = %d, want 1", n)
+    }
+    
+    // A handler that panics writes nothing
+    _, done = tc.track("GetAll", httptest.NewRecorder())
+    done()
+    if n := tc.Metrics.Count("GetAll", "db_error"); n != 1 {
+        t.Errorf("nothing written: GetAll/db_error = %d, want 1", n)
+    }
+}
+
+func TestTrackWithoutMetricsLeavesWriterAlone(t *testing.T) {
+    rec := httptest.NewRecorder()
+    w, done := (&TestController{}).track("GetAll", rec)
+    done()
+    if w != rec {
+        t.Errorf("track wrapped the writer (%T) with metrics disabled", w)
+    }
+}
+
+func TestStatusRecorderFlushPassesThrough(t *testing.T) {
+    rec := httptest.NewRecorder()
+    s := &statusRecorder{ResponseWriter: rec}
+    s.Flush()
+    if !rec.Flushed {
+        t.Error("Flush not passed to the underlying writer")
+    }
+    if s.status != http.StatusOK {
+        t.Errorf("status after Flush = %d, want 200", s.status)
+    }
+}
+
+// hijackRecorder is a ResponseRecorder whose connection can be hijacked
+type hijackRecorder struct {
+    *httptest.ResponseRecorder
+    hijacked bool
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+    h.hijacked = true
+    client, server := net.Pipe()
+    client.Close()
+    return server, bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)), nil
+}
+
+func TestStatusRecorderHijackPassesThrough(t *testing.T) {
+    tc := &TestController{Metrics: NewOperationMetrics()}
+    under := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+    w, done := tc.track("GetAll", under)
+    
+    conn, _, err := w.(http.Hijacker).Hijack()
+    if err != nil {
+        t.Fatalf("Hijack: %v", err)
+    }
+    conn.Close()
+    done()
+    if !under.hijacked {
+        t.Error("Hijack not passed to the underlying writer")
+    }
+    if n := tc.Metrics.Count("GetAll", "success"); n != 1 {
+        t.Errorf("hijacked request: GetAll/success = %d, want 1", n)
+    }
+    
+    // A writer that can't be hijacked makes Hijack fail rather than panic
+    s := &statusRecorder{ResponseWriter: httptest.NewRecorder()}
+    if _, _, err := s.Hijack(); err == nil {
+        t.Error("Hijack succeeded on a writer that doesn't support it")
+    }
+}
+
+func TestWritePrometheusIsWellFormed(t *testing.T) {
+    m := NewOperationMetrics()
+    m.Inc("Update", "success")
+    m.Inc("Update", "success")
+    m.Inc("Create", "conflict")
+    m.Inc("Create", "success")
+    
+    var b strings.Builder
+    m.WritePrometheus(&b)
+    lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+    
+    want := []string{
+        "# HELP backend_operations_total Controller operations by outcome.",
+        "# TYPE backend_operations_total counter",
+        `backend_operations_total{operation="Create",outcome="conflict"} 1`,
+        `backend_operations_total{operation="Create",outcome="success"} 1`,
+        `backend_operations_total{operation="Update",outcome="success"} 2`,
+    }
+    if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+        t.Fatalf("output:\n%s\nwant:\n%s", b.String(), strings.Join(want, "\n"))
+    }
+    
+    sample := regexp.MustCompile(`^[a-z_]+\{operation="[A-Za-z]+",outcome="[a-z_]+"\} [0-9]+$`)
+    for _, line := range lines[2:] {
+        if !sample.MatchString(line) {
+            t.Errorf("malformed sample line %q", line)
+        }
+    }
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+    "strconv"
+    "strings"
+)
+
+// mediaRange is one entry of an Accept header
+type mediaRange struct {
+    typ     string
+    subtype string
+    q       float64
+}
+
+// parseAccept splits an Accept header into media ranges. Entries that can't be
+// parsed are skipped; a missing or malformed q defaults to 1.
+func parseAccept(acceptHeader string) []mediaRange {
+    var ranges []mediaRange
+    for _, part := range strings.Split(acceptHeader, ",") {
+        params := strings.Split(part, ";")
+        mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+        slash := strings.IndexByte(mediaType, '/')
+        if slash <= 0 || slash == len(mediaType)-1 {
+            continue
+        }
+        
+        mr := mediaRange{typ: mediaType[:slash], subtype: mediaType[slash+1:], q: 1}
+        for _, param := range params[1:] {
+            key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+            if !ok || strings.ToLower(strings.TrimSpace(key)) != "q" {
+                continue
+            }
+            if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && q >= 0 && q <= 1 {
+                mr.q = q
+            }
+        }
+        ranges = append(ranges, mr)
+    }
+    return ranges
+}
+
+// qualityFor returns the q-value the ranges assign to mediaType, taken from the most
+// specific matching range (type/subtype beats type/* beats */*). ok is false when no
+// range matches.
+func qualityFor(ranges []mediaRange, mediaType string) (q float64, ok bool) {
+    typ, subtype, _ := strings.Cut(mediaType, "/")
+    best := -1
+    for _, mr := range ranges {
+        specificity := -1
+        switch {
+        case mr.typ == typ && mr.subtype == subtype:
+            specificity = 2
+        case mr.typ == typ && mr.subtype == "*":
+            specificity = 1
+        case mr.typ == "*" && mr.subtype == "*":
+            specificity = 0
+        }
+        if specificity > best {
+            best = specificity
+            q = mr.q
+        }
+    }
+    return q, best >= 0
+}
+
+// negotiate picks the best of the available media types for acceptHeader, honouring
+// q-values. Ties go to the type listed first in available, and an empty header
+// selects available[0]. Returns "" when nothing is acceptable (caller should send 406).
+//
+// Browsers send headers like "text/html,application/xml;q=0.9,*/*;q=0.8": the
+// down-weighted types rank what they can render, and */* says anything will do. So
+// when the header has */*, a type it names below q=1 doesn't displace the default
+// (available[0]); only an unqualified (q=1) type does.
+func negotiate(acceptHeader string, available []string) string {
+    if len(available) == 0 {
+        return ""
+    }
+    if strings.TrimSpace(acceptHeader) == "" {
+        return available[0]
+    }
+    
+    ranges := parseAccept(acceptHeader)
+    best, bestQ := "", 0.0
+    for _, mediaType := range available {
+        q, ok := qualityFor(ranges, strings.ToLower(mediaType))
+        if ok && q > bestQ {
+            best, bestQ = mediaType, q
+        }
+    }
+    if best != available[0] && bestQ < 1 && acceptsAnything(ranges) {
+        if q, ok := qualityFor(ranges, strings.ToLower(available[0])); ok && q > 0 {
+            return available[0]
+        }
+    }
+    return best
+}
+
+// acceptsAnything reports whether the ranges include */* with a non-zero q
+func acceptsAnything(ranges []mediaRange) bool {
+    for _, mr := range ranges {
+        if mr.typ == "*" && mr.subtype == "*" && mr.q > 0 {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestParseAccept(t *testing.T) {
+    tests := []struct {
+        header string
+        want   []mediaRange
+    }{
+        {"application/json", []mediaRange{{"application", "json", 1}}},
+        {"Application/XML; q=0.5", []mediaRange{{"application", "xml", 0.5}}},
+        {"text/*;level=1;q=0", []mediaRange{{"text", "*", 0}}},
+        {"*/*;q=abc", []mediaRange{{"*", "*", 1}}},
+        {"*/*;q=2", []mediaRange{{"*", "*", 1}}},
+        {"garbage, /json, application/, application/json", []mediaRange{{"application", "json", 1}}},
+        {"", nil},
+    }
+    for _, tt := range tests {
+        if got := parseAccept(tt.header); !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("parseAccept(%q) = %v, want %v", tt.header, got, tt.want)
+        }
+    }
+}
+
+func TestNegotiate(t *testing.T) {
+    tests := []struct {
+        name   string
+        accept string
+        want   string
+    }{
+        {"no header", "", formatJSON},
+        {"json", "application/json", formatJSON},
+        {"xml", "application/xml", formatXML},
+        {"text/xml alias", "text/xml", formatXML},
+        {"json:api", "application/vnd.api+json", formatJSONAPI},
+        {"higher q wins", "application/json;q=0.5, application/xml", formatXML},
+        {"tie goes to the preferred type", "application/xml, application/json", formatJSON},
+        {"q=0 excludes", "application/json;q=0, application/*", formatXML},
+        {"type wildcard", "application/*", formatJSON},
+        {"any", "*/*", formatJSON},
+        {"wildcard with json excluded", "*/*, application/json;q=0", formatXML},
+        {"browser header", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", formatJSON},
+        {"down-weighted xml next to */*", "application/xml;q=0.9,*/*;q=0.8", formatJSON},
+        {"unqualified xml next to */*", "application/xml, */*;q=0.1", formatXML},
+        {"nothing acceptable", "text/html", ""},
+        {"everything refused", "*/*;q=0", ""},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := negotiate(tt.accept, availableFormats)
+            if got == "text/xml" {
+                got = formatXML
+            }
+            if got != tt.want {
+                t.Errorf("negotiate(%q) = %q, want %q", tt.accept, got, tt.want)
+            }
+        })
+    }
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+    "encoding/base64"
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+)
+
+// Pagination is the validated paging part of a list request, shared by every
+// endpoint that returns pages so they all accept the same parameters
+type Pagination struct {
+    Limit     int
+    Offset    int
+    CountMode string
+}
+
+// PaginationError is returned by ParsePagination for invalid parameters; callers map
+// it to 400
+type PaginationError struct {
+    Message string
+}
+
+func (e *PaginationError) Error() string {
+    return e.Message
+}
+
+// ParsePagination reads limit, offset, cursor and countMode from the query string. An
+// absent limit uses the controller's default page size and a limit above the maximum
+// is clamped. cursor is the opaque nextCursor of a previous page and stands in for
+// offset; sending both is an error.
+func (tc *TestController) ParsePagination(r *http.Request) (Pagination, error) {
+    maxLimit := tc.MaxPageSize
+    if maxLimit <= 0 {
+        maxLimit = MaxPageSize
+    }
+    defaultLimit := tc.DefaultPageSize
+    if defaultLimit <= 0 {
+        defaultLimit = DefaultPageSize
+    }
+    if defaultLimit > maxLimit {
+        defaultLimit = maxLimit
+    }
+    
+    query := r.URL.Query()
+    page := Pagination{Limit: defaultLimit, CountMode: countModeNone}
+    
+    if raw := query.Get("limit"); raw != "" {
+        limit, err := strconv.Atoi(raw)
+        if err != nil || limit < 1 {
+            return page, &PaginationError{"limit must be a positive integer"}
+        }
+        if limit > maxLimit {
+            limit = maxLimit
+        }
+        page.Limit = limit
+    }
+    
+    rawOffset, rawCursor := query.Get("offset"), query.Get("cursor")
+    if rawOffset != "" && rawCursor != "" {
+        return page, &PaginationError{"offset and cursor cannot be combined"}
+    }
+    if rawOffset != "" {
+        offset, err := strconv.Atoi(rawOffset)
+        if err != nil || offset < 0 {
+            return page, &PaginationError{"offset must be a non-negative integer"}
+        }
+        page.Offset = offset
+    }
+    if rawCursor != "" {
+        offset, err := decodeCursor(rawCursor)
+        if err != nil {
+            return page, &PaginationError{"cursor is invalid"}
+        }
+        page.Offset = offset
+    }
+    
+    if raw := query.Get("countMode"); raw != "" {
+        mode := strings.ToLower(raw)
+        if mode != countModeNone && mode != countModeExact && mode != countModeEstimate {
+            return page, &PaginationError{"countMode must be exact, estimate or none"}
+        }
+        page.CountMode = mode
+    }
+    
+    return page, nil
+}
+
+// cursorPrefix versions the cursor format so it can change without misreading old cursors
+const cursorPrefix = "o1:"
+
+// encodeCursor returns the opaque cursor for the page starting at offset
+func encodeCursor(offset int) string {
+    return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the offset a cursor from encodeCursor points at
+func decodeCursor(cursor string) (int, error) {
+    raw, err := base64.RawURLEncoding.DecodeString(cursor)
+    if err != nil {
+        return 0, err
+    }
+    value, ok := strings.CutPrefix(string(raw), cursorPrefix)
+    if !ok {
+        return 0, fmt.Errorf("unknown cursor format")
+    }
+    offset, err := strconv.Atoi(value)
+    if err != nil || offset < 0 {
+        return 0, fmt.Errorf("invalid cursor offset")
+    }
+    return offset, nil
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+    "net/http/httptest"
+    "testing"
+)
+
+func TestParsePagination(t *testing.T) {
+    tc := &TestController{DefaultPageSize: 20, MaxPageSize: 100}
+    tests := []struct {
+        query   string
+        want    Pagination
+        wantErr bool
+    }{
+        {"", Pagination{Limit: 20, CountMode: countModeNone}, false},
+        {"limit=5&offset=10", Pagination{Limit: 5, Offset: 10, CountMode: countModeNone}, false},
+        {"limit=1000", Pagination{Limit: 100, CountMode: countModeNone}, false},
+        {"limit=100", Pagination{Limit: 100, CountMode: countModeNone}, false},
+        {"countMode=EXACT", Pagination{Limit: 20, CountMode: countModeExact}, false},
+        {"cursor=" + encodeCursor(40), Pagination{Limit: 20, Offset: 40, CountMode: countModeNone}, false},
+        {"limit=0", Pagination{}, true},
+        {"limit=-3", Pagination{}, true},
+        {"limit=ten", Pagination{}, true},
+        {"offset=-1", Pagination{}, true},
+        {"offset=x", Pagination{}, true},
+        {"offset=1&cursor=" + encodeCursor(1), Pagination{}, true},
+        {"cursor=not-a-cursor", Pagination{}, true},
+        {"countMode=sometimes", Pagination{}, true},
+    }
+    for _, tt := range tests {
+        got, err := tc.ParsePagination(httptest.NewRequest("GET", "/api/test?"+tt.query, nil))
+        if tt.wantErr {
+            if _, ok := err.(*PaginationError); !ok {
+                t.Errorf("%q: error = %v, want a *PaginationError", tt.query, err)
+            }
+            continue
+        }
+        if err != nil || got != tt.want {
+            t.Errorf("%q: got %+v, %v; want %+v", tt.query, got, err, tt.want)
+        }
+    }
+}
+
+func TestParsePaginationDefaults(t *testing.T) {
+    tests := []struct {
+        name        string
+        tc          *TestController
+        wantDefault int
+    }{
+        {"unset sizes use the built-ins", &TestController{}, DefaultPageSize},
+        {"default above the max is clamped", &TestController{DefaultPageSize: 50, MaxPageSize: 10}, 10},
+    }
+    for _, tt := range tests {
+        got, err := tt.tc.ParsePagination(httptest.NewRequest("GET", "/api/test", nil))
+        if err != nil || got.Limit != tt.wantDefault {
+            t.Errorf("%s: limit = %d, %v; want %d", tt.name, got.Limit, err, tt.wantDefault)
+        }
+    }
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+    for _, offset := range []int{0, 1, 250} {
+        got, err := decodeCursor(encodeCursor(offset))
+        if err != nil || got != offset {
+            t.Errorf("decodeCursor(encodeCursor(%d)) = %d, %v", offset, got, err)
+        }
+    }
+}
@@ -0,0 +1,161 @@
+package controllers
+
+import (
+    "database/sql/driver"
+    "net/http"
+    "strings"
+    "sync"
+    
+    "github.com/lib/pq"
+)
+
+// projectTable is an in-memory "TestProjects" for the stub driver's answer hook. It
+// understands the single-row insert, the lookups and writes by id, the lookups by name
+// and the rename UPDATE, with or without the board condition, and enforces the
+// ("Name", "BoardId") unique index the way Postgres does: rows without a board never
+// collide.
+type projectTable struct {
+    mu     sync.Mutex
+    rows   []projectRow
+    nextId int64
+}
+
+type projectRow struct {
+    id    int64
+    name  string
+    board string
+}
+
+func (pt *projectTable) add(name, board string) int64 {
+    pt.mu.Lock()
+    defer pt.mu.Unlock()
+    return pt.insert(name, board)
+}
+
+func (pt *projectTable) insert(name, board string) int64 {
+    pt.nextId++
+    pt.rows = append(pt.rows, projectRow{id: pt.nextId, name: name, board: board})
+    return pt.nextId
+}
+
+// byId returns the row with id, or nil
+func (pt *projectTable) byId(id driver.Value) *projectRow {
+    for i := range pt.rows {
+        if pt.rows[i].id == id.(int64) {
+            return &pt.rows[i]
+        }
+    }
+    return nil
+}
+
+// named returns the rows called name; with board scoping (boardArg within args) only
+// the rows of that board
+func (pt *projectTable) named(name string, args []driver.Value, boardArg int) []*projectRow {
+    var rows []*projectRow
+    for i := range pt.rows {
+        row := &pt.rows[i]
+        if row.name != name {
+            continue
+        }
+        if boardArg < len(args) && row.board != args[boardArg].(string) {
+            continue
+        }
+        rows = append(rows, row)
+    }
+    return rows
+}
+
+func (pt *projectTable) answer(query string, args []driver.Value) ([][]driver.Value, error) {
+    pt.mu.Lock()
+    defer pt.mu.Unlock()
+    
+    switch {
+    case query == `DELETE FROM public."TestProjects"`:
+        deleted := make([][]driver.Value, len(pt.rows))
+        for i, row := range pt.rows {
+            deleted[i] = []driver.Value{row.id}
+        }
+        pt.rows = nil
+        return deleted, nil
+    case strings.HasPrefix(query, "DELETE") && strings.Contains(query, `WHERE "Id" = $1`):
+        for i, row := range pt.rows {
+            if row.id == args[0].(int64) {
+                pt.rows = append(pt.rows[:i], pt.rows[i+1:]...)
+                return [][]driver.Value{{row.id}}, nil
+            }
+        }
+        return nil, nil
+    case strings.HasPrefix(query, "UPDATE") && strings.Contains(query, `WHERE "Id" = $2`):
+        row := pt.byId(args[1])
+        if row == nil {
+            return nil, nil
+        }
+        row.name = args[0].(string)
+        return [][]driver.Value{{row.id}}, nil
+    case strings.HasPrefix(query, "SELECT") && strings.Contains(query, `WHERE "Id" = $1`):
+        row := pt.byId(args[0])
+        switch {
+        case row == nil:
+            return nil, nil
+        case strings.HasPrefix(query, "SELECT 1"):
+            return [][]driver.Value{{int64(1)}}, nil
+        case strings.HasPrefix(query, `SELECT "Name" FROM`):
+            return [][]driver.Value{{row.name}}, nil
+        }
+        return [][]driver.Value{{row.id, row.name}}, nil
+    case strings.Contains(query, `"Name" = ANY($1)`):
+        var names pq.StringArray
+        if err := names.Scan(args[0]); err != nil {
+            return nil, err
+        }
+        var taken [][]driver.Value
+        for _, name := range names {
+            for _, row := range pt.named(name, args, 1) {
+                taken = append(taken, []driver.Value{row.name})
+            }
+        }
+        return taken, nil
+    case strings.HasPrefix(query, "INSERT"):
+        name, board := args[0].(string), ""
+        if len(args) > 1 {
+            board = args[1].(string)
+        }
+        if board != "" && len(pt.named(name, args, 1)) > 0 {
+            if strings.Contains(query, "ON CONFLICT DO NOTHING") {
+                return nil, nil
+            }
+            return nil, &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"}
+        }
+        return [][]driver.Value{{pt.insert(name, board), name}}, nil
+    case strings.HasPrefix(query, "SELECT EXISTS"):
+        return [][]driver.Value{{len(pt.named(args[0].(string), args, 1)) > 0}}, nil
+    case strings.HasPrefix(query, `SELECT "Id" FROM`):
+        var ids [][]driver.Value
+        for _, row := range pt.named(args[0].(string), args, 1) {
+            ids = append(ids, []driver.Value{row.id})
+        }
+        if strings.Contains(query, "LIMIT 1") && len(ids) > 1 {
+            ids = ids[:1]
+        }
+        return ids, nil
+    case strings.HasPrefix(query, `SELECT "Id", "Name" FROM`):
+        var rows [][]driver.Value
+        for _, row := range pt.named(args[0].(string), args, 1) {
+            rows = append(rows, []driver.Value{row.id, row.name})
+        }
+        return rows, nil
+    case strings.HasPrefix(query, "UPDATE"):
+        var ids [][]driver.Value
+        for _, row := range pt.named(args[1].(string), args, 2) {
+            row.name = args[0].(string)
+            ids = append(ids, []driver.Value{row.id})
+        }
+        return ids, nil
+    }
+    return nil, nil
+}
+
+// onBoard returns req as sent from board (none when empty)
+func onBoard(req *http.Request, board string) *http.Request {
+    return req.WithContext(WithBoardID(req.Context(), board))
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+    "fmt"
+    "strings"
+)
+
+// filterableColumns whitelists the columns a WHERE condition may reference
+var filterableColumns = map[string]string{
+    "Id":        `"Id"`,
+    "Name":      `"Name"`,
+    "CreatedAt": `"CreatedAt"`,
+}
+
+// filterOperators whitelists the comparison operators a condition may use
+var filterOperators = map[string]bool{
+    "=":     true,
+    "<":     true,
+    ">":     true,
+    "<=":    true,
+    ">=":    true,
+    "ILIKE": true,
+}
+
+// QueryBuilder composes the WHERE / ORDER BY / LIMIT part of a query safely:
+// column names, operators and sort directions are checked against whitelists and
+// every value is passed as a bound parameter, never interpolated into the SQL.
+type QueryBuilder struct {
+    conditions []string
+    args       []interface{}
+    orderBy    []string
+    limit      int
+    offset     int
+    paged      bool
+}
+
+func newQueryBuilder() *QueryBuilder {
+    return &QueryBuilder{}
+}
+
+// Where adds "column op $n" bound to value
+func (b *QueryBuilder) Where(column, op string, value interface{}) error {
+    identifier, ok := filterableColumns[column]
+    if !ok {
+        return fmt.Errorf("column %q cannot be filtered on", column)
+    }
+    if !filterOperators[op] {
+        return fmt.Errorf("operator %q is not allowed", op)
+    }
+    b.args = append(b.args, value)
+    b.conditions = append(b.conditions, fmt.Sprintf("%s %s $%d", identifier, op, len(b.args)))
+    return nil
+}
+
+// WhereContains adds a case-insensitive substring match, escaping LIKE wildcards in value
+func (b *QueryBuilder) WhereContains(column, value string) error {
+    return b.Where(column, "ILIKE", "%"+escapeLike(value)+"%")
+}
+
+// OrderBy adds a sort key; column must be sortable and dir "asc" or "desc"
+func (b *QueryBuilder) OrderBy(column, dir string) error {
+    identifier, ok := sortableColumns[column]
+    if !ok {
+        return fmt.Errorf("column %q cannot be sorted on", column)
+    }
+    dir = strings.ToUpper(dir)
+    if dir != "ASC" && dir != "DESC" {
+        return fmt.Errorf("sort direction %q is not allowed", dir)
+    }
+    b.orderBy = append(b.orderBy, identifier+" "+dir)
+    return nil
+}
+
+// Page limits the result to limit rows starting at offset
+func (b *QueryBuilder) Page(limit, offset int) {
+    b.limit = limit
+    b.offset = offset
+    b.paged = true
+}
+
+// WhereSQL returns the " WHERE ..." fragment (empty when there are no conditions)
+// and its bound arguments
+func (b *QueryBuilder) WhereSQL() (string, []interface{}) {
+    args := append([]interface{}{}, b.args...)
+    if len(b.conditions) == 0 {
+        return "", args
+    }
+    return " WHERE " + strings.Join(b.conditions, " AND "), args
+}
+
+// SelectSQL appends the WHERE, ORDER BY and LIMIT/OFFSET clauses to base
+// (e.g. `SELECT "Id", "Name" FROM "TestProjects"`) and returns the bound arguments.
+// "Id" is always a sort key, added last unless already there, so paging is stable.
+func (b *QueryBuilder) SelectSQL(base string) (string, []interface{}) {
+    where, args := b.WhereSQL()
+    query := base + where
+
+    order := append([]string{}, b.orderBy...)
+    if !b.ordersById() {
+        order = append(order, `"Id"`)
+    }
+    query += " ORDER BY " + strings.Join(order, ", ")
+
+    if b.paged {
+        args = append(args, b.limit, b.offset)
+        query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+    }
+    return query, args
+}
+
+// ordersById reports whether a sort key is already "Id", which is unique and so
+// makes any later key redundant
+func (b *QueryBuilder) ordersById() bool {
+    for _, key := range b.orderBy {
+        if strings.HasPrefix(key, `"Id" `) {
+            return true
+        }
+    }
+    return false
+}
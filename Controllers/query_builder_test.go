@@ -0,0 +1,81 @@
+package controllers
+
+import (
+    "reflect"
+    "testing"
+    "time"
+)
+
+func TestQueryBuilderBindsValuesInOrder(t *testing.T) {
+    after := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+    qb := newQueryBuilder()
+    if err := qb.WhereContains("Name", "50%_off\\"); err != nil {
+        t.Fatal(err)
+    }
+    if err := qb.Where("CreatedAt", ">", after); err != nil {
+        t.Fatal(err)
+    }
+    if err := qb.OrderBy("Name", "desc"); err != nil {
+        t.Fatal(err)
+    }
+    qb.Page(10, 20)
+    
+    query, args := qb.SelectSQL(`SELECT "Id", "Name" FROM "TestProjects"`)
+    wantQuery := `SELECT "Id", "Name" FROM "TestProjects" WHERE "Name" ILIKE $1 AND "CreatedAt" > $2 ORDER BY "Name" DESC, "Id" LIMIT $3 OFFSET $4`
+    if query != wantQuery {
+        t.Errorf("query =\n  %s\nwant\n  %s", query, wantQuery)
+    }
+    wantArgs := []interface{}{`%50\%\_off\\%`, after, 10, 20}
+    if !reflect.DeepEqual(args, wantArgs) {
+        t.Errorf("args = %#v, want %#v", args, wantArgs)
+    }
+    
+    where, whereArgs := qb.WhereSQL()
+    if where != ` WHERE "Name" ILIKE $1 AND "CreatedAt" > $2` || !reflect.DeepEqual(whereArgs, wantArgs[:2]) {
+        t.Errorf("WhereSQL = %q, %#v", where, whereArgs)
+    }
+}
+
+func TestQueryBuilderKeepsInjectionOutOfSQL(t *testing.T) {
+    payload := `x'; DROP TABLE "TestProjects"; --`
+    qb := newQueryBuilder()
+    if err := qb.Where("Name", "=", payload); err != nil {
+        t.Fatal(err)
+    }
+    query, args := qb.SelectSQL(`SELECT "Id" FROM "TestProjects"`)
+    if want := `SELECT "Id" FROM "TestProjects" WHERE "Name" = $1 ORDER BY "Id"`; query != want {
+        t.Errorf("query = %s, want %s", query, want)
+    }
+    if !reflect.DeepEqual(args, []interface{}{payload}) {
+        t.Errorf("args = %#v, want the payload as the only bound value", args)
+    }
+}
+
+func TestQueryBuilderRejectsUnlistedIdentifiers(t *testing.T) {
+    qb := newQueryBuilder()
+    if err := qb.Where(`"Name"; DROP TABLE x`, "=", 1); err == nil {
+        t.Error("Where accepted an unlisted column")
+    }
+    if err := qb.Where("Name", "; DROP", 1); err == nil {
+        t.Error("Where accepted an unlisted operator")
+    }
+    if err := qb.OrderBy("CreatedAt", "asc"); err == nil {
+        t.Error("OrderBy accepted a column that isn't sortable")
+    }
+    if err := qb.OrderBy("Name", "asc; DROP"); err == nil {
+        t.Error("OrderBy accepted an unlisted direction")
+    }
+    if query, args := qb.SelectSQL("SELECT 1"); query != `SELECT 1 ORDER BY "Id"` || len(args) != 0 {
+        t.Errorf("rejected clauses leaked into %q with %v", query, args)
+    }
+}
+
+func TestQueryBuilderDoesNotRepeatIdSortKey(t *testing.T) {
+    qb := newQueryBuilder()
+    if err := qb.OrderBy("Id", "asc"); err != nil {
+        t.Fatal(err)
+    }
+    if query, _ := qb.SelectSQL("SELECT 1"); query != `SELECT 1 ORDER BY "Id" ASC` {
+        t.Errorf("query = %s, want a single \"Id\" sort key", query)
+    }
+}
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "log"
+    "net"
+    "net/http"
+    "syscall"
+
+    "backend/Dberr"
+    "backend/Models"
+)
+
+// responseCharset is the charset parameter appended to JSON responses ("" omits it)
+var responseCharset = "utf-8"
+
+// SetResponseCharset overrides the charset parameter of JSON responses; "" sends a
+// bare application/json for clients that reject the parameter. Call before serving.
+func SetResponseCharset(charset string) {
+    responseCharset = charset
+}
+
+// JSONContentType is the Content-Type used for JSON responses
+func JSONContentType() string {
+    if responseCharset == "" {
+        return "application/json"
+    }
+    return "application/json; charset=" + responseCharset
+}
+
+// WriteJSON writes v as a JSON response with the given status code. It is the single
+// place JSON responses are encoded: v is encoded in full before anything is sent, so
+// a value that fails to encode becomes a logged 500 instead of a truncated body
+// under a success status.
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+    writeJSONAs(w, JSONContentType(), status, v)
+}
+
+// writeJSONAs is WriteJSON with a specific JSON media type (e.g. JSON:API, which
+// forbids media type parameters and so never carries a charset)
+func writeJSONAs(w http.ResponseWriter, contentType string, status int, v interface{}) {
+    body, err := json.Marshal(v)
+    if err != nil {
+        log.Printf("[RESPONSE ERROR] Failed to encode %T response (status %d), sending 500 instead: %v", v, status, err)
+        writeEncodeFailure(w)
+        return
+    }
+    writeBody(w, contentType, status, append(body, '\n'), v)
+}
+
+// writeBody sends an already encoded response. A failed write can only be logged,
+// since the status is on its way to the client by then.
+func writeBody(w http.ResponseWriter, contentType string, status int, body []byte, v interface{}) {
+    w.Header().Set("Content-Type", contentType)
+    w.WriteHeader(status)
+    if _, err := w.Write(body); err != nil && !isClientGone(err) {
+        log.Printf("[RESPONSE ERROR] Failed to write %T response (status %d) after the status was sent: %v", v, status, err)
+    }
+}
+
+// writeEncodeFailure answers 500 for a response that could not be encoded
+func writeEncodeFailure(w http.ResponseWriter) {
+    body, _ := json.Marshal(errorBody{Error: errorDetail{
+        Code:    "internal_error",
+        Message: "failed to encode the response",
+    }})
+    w.Header().Set("Content-Type", JSONContentType())
+    w.WriteHeader(http.StatusInternalServerError)
+    w.Write(append(body, '\n'))
+}
+
+// isClientGone reports whether a write failed because the client closed the
+// connection. That is routine for large responses and not worth an error log line;
+// the access log still records the request.
+func isClientGone(err error) bool {
+    return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, net.ErrClosed)
+}
+
+// statusClientClosedRequest is the non-standard (nginx) status for a client that
+// disconnected before the response was ready
+const statusClientClosedRequest = 499
+
+// writeDBError maps a database error to a response. If the request's context is done
+// the failure is attributed to that rather than the database: a client disconnect
+// becomes 499 (not logged as an error) and a deadline becomes 503. Otherwise unique
+// violations become 409 and retryable failures (see dberr.IsRetryable) 503.
+func writeDBError(w http.ResponseWriter, r *http.Request, err error) {
+    // lib/pq reports a cancelled query as its own error ("canceling statement due to
+    // user request"), so look at the request context instead of only at err
+    if ctxErr := r.Context().Err(); ctxErr != nil {
+        err = ctxErr
+    }
+    
+    switch {
+    case errors.Is(err, context.Canceled):
+        w.WriteHeader(statusClientClosedRequest)
+    case errors.Is(err, context.DeadlineExceeded), dberr.IsQueryCanceled(err):
+        // With the request context still live, either statement_timeout fired or the
+        // call's own deadline (DB_ACQUIRE_TIMEOUT_MS) passed, typically waiting for a
+        // pool connection
+        LoggerFromContext(r.Context()).Printf("[DB TIMEOUT] %v", err)
+        SetRetryAfter(w)
+        http.Error(w, "Database timeout, please retry later", http.StatusServiceUnavailable)
+    case dberr.IsUniqueViolation(err):
+        LoggerFromContext(r.Context()).Printf("[DB CONFLICT] %v", err)
+        WriteJSON(w, http.StatusConflict, errorBody{Error: errorDetail{
+            Code:    "conflict",
+            Message: "a conflicting record already exists",
+        }})
+    case dberr.IsRetryable(err):
+        LoggerFromContext(r.Context()).Printf("[DB UNAVAILABLE] %v", err)
+        SetRetryAfter(w)
+        http.Error(w, "Database temporarily unavailable, please retry later", http.StatusServiceUnavailable)
+    default:
+        LoggerFromContext(r.Context()).Printf("[DB ERROR] %v", err)
+        http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+    }
+}
+
+// errorDetail is the structured error envelope: {"error":{"code":...,"message":...}};
+// validation failures also list every problem under fields
+type errorDetail struct {
+    Code    string       `json:"code"`
+    Message string       `json:"message"`
+    Id      *models.ID   `json:"id,omitempty"`
+    Fields  []FieldError `json:"fields,omitempty"`
+}
+
+type errorBody struct {
+    Error errorDetail `json:"error"`
+}
+
+// writeNotFound reports a missing project, including the id that was requested
+func writeNotFound(w http.ResponseWriter, id models.ID) {
+    WriteJSON(w, http.StatusNotFound, errorBody{Error: errorDetail{
+        Code:    "not_found",
+        Message: "project not found",
+        Id:      &id,
+    }})
+}
+
+// writePreconditionFailed reports that If-Match no longer matches the project's ETag
+func writePreconditionFailed(w http.ResponseWriter, id models.ID) {
+    WriteJSON(w, http.StatusPreconditionFailed, errorBody{Error: errorDetail{
+        Code:    "precondition_failed",
+        Message: "project has been modified; re-fetch it and retry with the new ETag",
+        Id:      &id,
+    }})
+}
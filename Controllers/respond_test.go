@@ -0,0 +1,114 @@
+package controllers
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "log"
+    "math"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "strings"
+    "testing"
+    
+    "github.com/lib/pq"
+)
+
+func TestWriteNotFoundNamesTheId(t *testing.T) {
+    w := httptest.NewRecorder()
+    writeNotFound(w, 42)
+    
+    if w.Code != http.StatusNotFound {
+        t.Errorf("status = %d, want 404", w.Code)
+    }
+    var body struct {
+        Error map[string]interface{} `json:"error"`
+    }
+    if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+        t.Fatalf("body %s: %v", w.Body, err)
+    }
+    if body.Error["code"] != "not_found" {
+        t.Errorf("error.code = %v, want not_found", body.Error["code"])
+    }
+    if body.Error["message"] != "project not found" {
+        t.Errorf("error.message = %v, want project not found", body.Error["message"])
+    }
+    if body.Error["id"] != float64(42) {
+        t.Errorf("error.id = %v, want 42", body.Error["id"])
+    }
+}
+
+func TestWriteDBErrorAfterClientDisconnectIs499(t *testing.T) {
+    var logged bytes.Buffer
+    ctx, cancel := context.WithCancel(WithLogger(context.Background(), log.New(&logged, "", 0)))
+    cancel()
+    req := httptest.NewRequest(http.MethodGet, "/api/test", nil).WithContext(ctx)
+    
+    // lib/pq's own error for a cancelled query, which on its own would read as a timeout
+    w := httptest.NewRecorder()
+    writeDBError(w, req, &pq.Error{Code: "57014", Message: "canceling statement due to user request"})
+    if w.Code != statusClientClosedRequest {
+        t.Errorf("status = %d, want 499", w.Code)
+    }
+    if logged.Len() != 0 {
+        t.Errorf("a client disconnect was logged: %q", logged.String())
+    }
+}
+
+// captureLog sends the standard logger to a buffer for the rest of the test
+func captureLog(t *testing.T) *bytes.Buffer {
+    var logged bytes.Buffer
+    flags := log.Flags()
+    log.SetOutput(&logged)
+    log.SetFlags(0)
+    t.Cleanup(func() {
+        log.SetOutput(os.Stderr)
+        log.SetFlags(flags)
+    })
+    return &logged
+}
+
+func TestWriteJSONLogsEncodeFailures(t *testing.T) {
+    logged := captureLog(t)
+    WriteJSON(httptest.NewRecorder(), http.StatusOK, map[string]interface{}{"updates": make(chan int)})
+    
+    if !strings.Contains(logged.String(), "[RESPONSE ERROR]") || !strings.Contains(logged.String(), "status 200") {
+        t.Errorf("log %q does not report the failed encode and its status", logged.String())
+    }
+}
+
+func TestWriteJSONAnswers500ForUnencodableValues(t *testing.T) {
+    captureLog(t)
+    for name, v := range map[string]interface{}{
+        "channel": map[string]interface{}{"updates": make(chan int)},
+        "NaN":     map[string]float64{"ratio": math.NaN()},
+    } {
+        w := httptest.NewRecorder()
+        WriteJSON(w, http.StatusCreated, v)
+        if w.Code != http.StatusInternalServerError {
+            t.Errorf("%s: status = %d, want 500", name, w.Code)
+        }
+        var body errorBody
+        if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error.Code != "internal_error" {
+            t.Errorf("%s: body %q, want an internal_error body", name, w.Body.String())
+        }
+    }
+}
+
+func TestJSONContentTypeCharset(t *testing.T) {
+    if got := JSONContentType(); got != "application/json; charset=utf-8" {
+        t.Errorf("default JSONContentType() = %q", got)
+    }
+    
+    SetResponseCharset("")
+    defer SetResponseCharset("utf-8")
+    if got := JSONContentType(); got != "application/json" {
+        t.Errorf("JSONContentType() without a charset = %q, want application/json", got)
+    }
+    w := httptest.NewRecorder()
+    WriteJSON(w, http.StatusOK, map[string]string{})
+    if got := w.Header().Get("Content-Type"); got != "application/json" {
+        t.Errorf("WriteJSON Content-Type = %q, want application/json", got)
+    }
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+    "math/rand"
+    "net/http"
+    "strconv"
+)
+
+// retryAfterMin and retryAfterMax bound the Retry-After value (in seconds) sent with
+// 503 responses. Each response picks a value in the range so clients that were
+// rejected together don't all retry in the same second.
+var (
+    retryAfterMin = 1
+    retryAfterMax = 1
+)
+
+// SetRetryAfterRange sets the range Retry-After values are drawn from; min == max
+// disables jitter. Call before serving.
+func SetRetryAfterRange(min, max int) {
+    retryAfterMin, retryAfterMax = min, max
+}
+
+// RetryAfterSeconds returns a Retry-After value drawn uniformly from the configured range
+func RetryAfterSeconds() int {
+    if retryAfterMax <= retryAfterMin {
+        return retryAfterMin
+    }
+    return retryAfterMin + rand.Intn(retryAfterMax-retryAfterMin+1)
+}
+
+// SetRetryAfter sets a jittered Retry-After header on w
+func SetRetryAfter(w http.ResponseWriter) {
+    w.Header().Set("Retry-After", strconv.Itoa(RetryAfterSeconds()))
+}
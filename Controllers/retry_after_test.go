@@ -0,0 +1,59 @@
+package controllers
+
+import (
+    "net/http/httptest"
+    "strconv"
+    "testing"
+)
+
+// withRetryAfterRange sets the Retry-After range for one test
+func withRetryAfterRange(t *testing.T, min, max int) {
+    t.Helper()
+    oldMin, oldMax := retryAfterMin, retryAfterMax
+    SetRetryAfterRange(min, max)
+    t.Cleanup(func() { SetRetryAfterRange(oldMin, oldMax) })
+}
+
+func TestRetryAfterSecondsStaysInRange(t *testing.T) {
+    withRetryAfterRange(t, 2, 5)
+    
+    seen := map[int]bool{}
+    for i := 0; i < 1000; i++ {
+        s := RetryAfterSeconds()
+        if s < 2 || s > 5 {
+            t.Fatalf("RetryAfterSeconds = %d, want 2..5", s)
+        }
+        seen[s] = true
+    }
+    // 1000 uniform draws over 4 values miss one with negligible probability
+    for s := 2; s <= 5; s++ {
+        if !seen[s] {
+            t.Errorf("%d never drawn; the range ends should be inclusive", s)
+        }
+    }
+}
+
+func TestRetryAfterSecondsWithoutJitter(t *testing.T) {
+    withRetryAfterRange(t, 3, 3)
+    for i := 0; i < 10; i++ {
+        if s := RetryAfterSeconds(); s != 3 {
+            t.Fatalf("RetryAfterSeconds = %d, want 3", s)
+        }
+    }
+    
+    // An inverted range falls back to min rather than panicking in rand.Intn
+    withRetryAfterRange(t, 4, 1)
+    if s := RetryAfterSeconds(); s != 4 {
+        t.Errorf("RetryAfterSeconds = %d, want 4", s)
+    }
+}
+
+func TestSetRetryAfterHeader(t *testing.T) {
+    withRetryAfterRange(t, 7, 9)
+    w := httptest.NewRecorder()
+    SetRetryAfter(w)
+    s, err := strconv.Atoi(w.Header().Get("Retry-After"))
+    if err != nil || s < 7 || s > 9 {
+        t.Errorf("Retry-After = %q, want 7..9", w.Header().Get("Retry-After"))
+    }
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+    "context"
+    "database/sql"
+    "net/http"
+)
+
+// preparedStatements holds the statements prepared once by PrepareStatements and
+// reused by the fixed-shape queries. GetAll/Search build their SQL dynamically
+// (filters, sort) and stay ad-hoc.
+//
+// search_path: a *sql.Stmt is transparently re-prepared on whichever pool connection
+// ends up running it, so a SET search_path issued earlier on some other connection
+// can't be relied on. These statements therefore schema-qualify the table as
+// public."TestProjects" and don't depend on the session search_path at all.
+type preparedStatements struct {
+    selectById  *sql.Stmt
+    selectByIds *sql.Stmt
+    exists      *sql.Stmt
+    insert      *sql.Stmt
+    update      *sql.Stmt
+    delete      *sql.Stmt
+}
+
+// PrepareStatements prepares the controller's fixed queries. Reads are prepared on
+// the read pool and writes on the primary. Call Close on shutdown to release them.
+func (tc *TestController) PrepareStatements(ctx context.Context) error {
+    stmts := &preparedStatements{}
+
+    prepare := func(db *sql.DB, target **sql.Stmt, query string) error {
+        stmt, err := db.PrepareContext(ctx, query)
+        if err != nil {
+            return err
+        }
+        *target = stmt
+        return nil
+    }
+
+    steps := []struct {
+        db     *sql.DB
+        target **sql.Stmt
+        query  string
+    }{
+        {tc.reader(), &stmts.selectById, `SELECT "Id", "Name" FROM public."TestProjects" WHERE "Id" = $1`},
+        {tc.reader(), &stmts.selectByIds, `SELECT "Id", "Name" FROM public."TestProjects" WHERE "Id" = ANY($1)`},
+        {tc.reader(), &stmts.exists, `SELECT 1 FROM public."TestProjects" WHERE "Id" = $1`},
+        {tc.DB, &stmts.insert, tc.insertSQL("")},
+        {tc.DB, &stmts.update, `UPDATE public."TestProjects" SET "Name" = $1 WHERE "Id" = $2`},
+        {tc.DB, &stmts.delete, `DELETE FROM public."TestProjects" WHERE "Id" = $1`},
+    }
+    for _, step := range steps {
+        if err := prepare(step.db, step.target, step.query); err != nil {
+            stmts.close()
+            return err
+        }
+    }
+
+    tc.stmts = stmts
+    return nil
+}
+
+// insertSQL is the single-row INSERT, with an optional ON CONFLICT clause; with
+// board-scoped names it also takes the board id ($2, empty for none) so the unique
+// index applies per board
+func (tc *TestController) insertSQL(onConflict string) string {
+    insert := `INSERT INTO public."TestProjects" ("Name") VALUES ($1)`
+    if tc.NameUniquePerBoard {
+        insert = `INSERT INTO public."TestProjects" ("Name", "BoardId") VALUES ($1, NULLIF($2, ''))`
+    }
+    if onConflict != "" {
+        insert += " " + onConflict
+    }
+    return insert + ` RETURNING "Id", "Name"`
+}
+
+// insertArgs returns the arguments of the insert statement for a project named name
+func (tc *TestController) insertArgs(r *http.Request, name string) []interface{} {
+    if tc.NameUniquePerBoard {
+        return []interface{}{name, BoardIDFromContext(r.Context())}
+    }
+    return []interface{}{name}
+}
+
+// Close releases the prepared statements
+func (tc *TestController) Close() error {
+    if tc.stmts == nil {
+        return nil
+    }
+    err := tc.stmts.close()
+    tc.stmts = nil
+    return err
+}
+
+func (s *preparedStatements) close() error {
+    var firstErr error
+    for _, stmt := range []*sql.Stmt{s.selectById, s.selectByIds, s.exists, s.insert, s.update, s.delete} {
+        if stmt == nil {
+            continue
+        }
+        if err := stmt.Close(); err != nil && firstErr == nil {
+            firstErr = err
+        }
+    }
+    return firstErr
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "os"
+    "testing"
+
+    _ "github.com/lib/pq"
+)
+
+// selectByIdSQL is the query the selectById statement prepares
+const selectByIdSQL = `SELECT "Id", "Name" FROM public."TestProjects" WHERE "Id" = $1`
+
+// openBenchDB connects to the Postgres at TEST_DATABASE_URL, skipping the benchmark
+// when it isn't set; the parse cost being measured only exists on a real server
+func openBenchDB(b *testing.B) *sql.DB {
+    b.Helper()
+    url := os.Getenv("TEST_DATABASE_URL")
+    if url == "" {
+        b.Skip("TEST_DATABASE_URL not set")
+    }
+    db, err := sql.Open("postgres", url)
+    if err != nil {
+        b.Fatalf("open: %v", err)
+    }
+    b.Cleanup(func() { db.Close() })
+    if err := db.Ping(); err != nil {
+        b.Skipf("database unavailable: %v", err)
+    }
+    return db
+}
+
+// scanProject runs a single-project lookup, treating a missing row as success
+func scanProject(b *testing.B, row *sql.Row) {
+    var id int
+    var name string
+    if err := row.Scan(&id, &name); err != nil && !errors.Is(err, sql.ErrNoRows) {
+        b.Fatalf("scan: %v", err)
+    }
+}
+
+func BenchmarkSelectByIdPrepared(b *testing.B) {
+    tc := NewTestController(openBenchDB(b))
+    if err := tc.PrepareStatements(context.Background()); err != nil {
+        b.Fatalf("PrepareStatements: %v", err)
+    }
+    defer tc.Close()
+    
+    ctx := context.Background()
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        scanProject(b, tc.stmts.selectById.QueryRowContext(ctx, i%100+1))
+    }
+}
+
+func BenchmarkSelectByIdAdHoc(b *testing.B) {
+    db := openBenchDB(b)
+    
+    ctx := context.Background()
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        scanProject(b, db.QueryRowContext(ctx, selectByIdSQL, i%100+1))
+    }
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+    "net/http"
+
+    "backend/Dberr"
+    "backend/Models"
+)
+
+// projectStats is the body of GET /api/test/stats. Latest is the most recently
+// created project (updates aren't timestamped), or null when there are none.
+type projectStats struct {
+    Total          int                  `json:"total"`
+    CreatedLast24h int                  `json:"createdLast24h"`
+    Latest         *models.TestProjects `json:"latest"`
+}
+
+// Stats returns summary numbers for dashboards: the project count, how many were
+// created in the last 24 hours and the newest project. Both queries are single
+// aggregate/LIMIT 1 scans, limited to the request's board when names are scoped per
+// board (to the projects without a board when the request has none, as inserts
+// record them). Needs the "CreatedAt" column (migration 001).
+func (tc *TestController) Stats(w http.ResponseWriter, r *http.Request) {
+    w, done := tc.track("Stats", w)
+    defer done()
+    
+    where := ""
+    var args []interface{}
+    if tc.NameUniquePerBoard {
+        where = ` WHERE "BoardId" IS NOT DISTINCT FROM NULLIF($1, '')`
+        args = append(args, BoardIDFromContext(r.Context()))
+    }
+    
+    ctx, cancel := tc.dbContext(r.Context())
+    defer cancel()
+    db := tc.reader()
+    var stats projectStats
+    err := db.QueryRowContext(ctx,
+        `SELECT COUNT(*), COUNT(*) FILTER (WHERE "CreatedAt" > now() - interval '24 hours') FROM public."TestProjects"`+where,
+        args...).Scan(&stats.Total, &stats.CreatedLast24h)
+    if err != nil {
+        writeDBError(w, r, err)
+        return
+    }
+    
+    var latest models.TestProjects
+    err = db.QueryRowContext(ctx,
+        `SELECT "Id", "Name" FROM public."TestProjects"`+where+` ORDER BY "CreatedAt" DESC, "Id" DESC LIMIT 1`,
+        args...).Scan(&latest.Id, &latest.Name)
+    switch {
+    case dberr.IsNotFound(err):
+    case err != nil:
+        writeDBError(w, r, err)
+        return
+    default:
+        stats.Latest = &latest
+    }
+    
+    WriteJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+    "database/sql/driver"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestStatsScopesToBoardlessProjectsWithoutBoard(t *testing.T) {
+    stub := &stubConnector{rows: [][]driver.Value{{int64(3), int64(1)}}}
+    tc := newStubController(t, stub, 1)
+    tc.NameUniquePerBoard = true
+    
+    w := httptest.NewRecorder()
+    tc.Stats(w, httptest.NewRequest(http.MethodGet, "/api/test/stats", nil))
+    if w.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+    }
+    
+    queries := stub.ran()
+    for _, query := range queries[len(queries)-2:] {
+        if !strings.Contains(query, `"BoardId" IS NOT DISTINCT FROM NULLIF($1, '')`) {
+            t.Errorf("query = %s, want boardless projects matched as NULL", query)
+        }
+    }
+}
+
+func TestStatsUnscopedWithoutBoardUniqueness(t *testing.T) {
+    stub := &stubConnector{rows: [][]driver.Value{{int64(3), int64(1)}}}
+    tc := newStubController(t, stub, 1)
+    
+    w := httptest.NewRecorder()
+    tc.Stats(w, httptest.NewRequest(http.MethodGet, "/api/test/stats", nil))
+    if w.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+    }
+    queries := stub.ran()
+    for _, query := range queries[len(queries)-2:] {
+        if strings.Contains(query, "BoardId") {
+            t.Errorf("query = %s, want no board filter", query)
+        }
+    }
+}
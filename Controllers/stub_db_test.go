@@ -0,0 +1,185 @@
+package controllers
+
+import (
+    "context"
+    "database/sql"
+    "database/sql/driver"
+    "io"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// stubConnector is a database/sql driver with no database behind it, for exercising
+// the pool and the handlers' query paths. Every query returns rows; every exec
+// affects one row.
+type stubConnector struct {
+    // rows are returned by every query; there are as many columns as the first row
+    // has values, or "Id" and "Name" without rows
+    rows [][]driver.Value
+    // rowsFor, when set, picks the rows of each query from its SQL instead
+    rowsFor func(query string) [][]driver.Value
+    // answer, when set, answers every query and exec from its SQL and arguments,
+    // taking over from rows and rowsFor; an exec affects as many rows as it returns
+    answer func(query string, args []driver.Value) ([][]driver.Value, error)
+    // delay is how long each query takes (honouring cancellation)
+    delay time.Duration
+    // onRow, when set, is called before each row of a result is read, with the
+    // number of rows read so far
+    onRow func(read int)
+    // queries counts the queries and execs run
+    queries atomic.Int64
+    
+    mu sync.Mutex
+    // sql is every query and exec run, in order
+    sql []string
+}
+
+// ran returns the SQL of every query and exec run so far
+func (c *stubConnector) ran() []string {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return append([]string{}, c.sql...)
+}
+
+// openStubDB opens a pool on c capped at maxOpen connections
+func openStubDB(c *stubConnector, maxOpen int) *sql.DB {
+    db := sql.OpenDB(c)
+    db.SetMaxOpenConns(maxOpen)
+    return db
+}
+
+func (c *stubConnector) Connect(context.Context) (driver.Conn, error) {
+    return &stubConn{connector: c}, nil
+}
+
+func (c *stubConnector) Driver() driver.Driver {
+    return stubDriver{c}
+}
+
+type stubDriver struct {
+    connector *stubConnector
+}
+
+func (d stubDriver) Open(string) (driver.Conn, error) {
+    return d.connector.Connect(context.Background())
+}
+
+type stubConn struct {
+    connector *stubConnector
+}
+
+func (c *stubConn) Prepare(query string) (driver.Stmt, error) {
+    return &stubStmt{connector: c.connector, query: query}, nil
+}
+
+func (c *stubConn) Close() error {
+    return nil
+}
+
+func (c *stubConn) Begin() (driver.Tx, error) {
+    return stubTx{}, nil
+}
+
+type stubTx struct{}
+
+func (stubTx) Commit() error   { return nil }
+func (stubTx) Rollback() error { return nil }
+
+type stubStmt struct {
+    connector *stubConnector
+    query     string
+}
+
+func (s *stubStmt) Close() error {
+    return nil
+}
+
+func (s *stubStmt) NumInput() int {
+    return -1
+}
+
+// run counts the call and waits out the connector's delay
+func (s *stubStmt) run(ctx context.Context) error {
+    s.connector.queries.Add(1)
+    s.connector.mu.Lock()
+    s.connector.sql = append(s.connector.sql, s.query)
+    s.connector.mu.Unlock()
+    select {
+    case <-time.After(s.connector.delay):
+        return nil
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
+
+func (s *stubStmt) Exec(args []driver.Value) (driver.Result, error) {
+    if err := s.run(context.Background()); err != nil {
+        return nil, err
+    }
+    if s.connector.answer == nil {
+        return driver.RowsAffected(1), nil
+    }
+    rows, err := s.connector.answer(s.query, args)
+    if err != nil {
+        return nil, err
+    }
+    return driver.RowsAffected(len(rows)), nil
+}
+
+func (s *stubStmt) Query(args []driver.Value) (driver.Rows, error) {
+    return s.rowsOf(context.Background(), args)
+}
+
+func (s *stubStmt) QueryContext(ctx context.Context, named []driver.NamedValue) (driver.Rows, error) {
+    args := make([]driver.Value, len(named))
+    for i, nv := range named {
+        args[i] = nv.Value
+    }
+    return s.rowsOf(ctx, args)
+}
+
+func (s *stubStmt) rowsOf(ctx context.Context, args []driver.Value) (driver.Rows, error) {
+    if err := s.run(ctx); err != nil {
+        return nil, err
+    }
+    rows := s.connector.rows
+    if s.connector.answer != nil {
+        var err error
+        if rows, err = s.connector.answer(s.query, args); err != nil {
+            return nil, err
+        }
+    } else if s.connector.rowsFor != nil {
+        rows = s.connector.rowsFor(s.query)
+    }
+    return &stubRows{rows: rows, onRow: s.connector.onRow}, nil
+}
+
+type stubRows struct {
+    rows  [][]driver.Value
+    next  int
+    onRow func(read int)
+}
+
+func (r *stubRows) Columns() []string {
+    if len(r.rows) == 0 {
+        return []string{"Id", "Name"}
+    }
+    return make([]string, len(r.rows[0]))
+}
+
+func (r *stubRows) Close() error {
+    return nil
+}
+
+func (r *stubRows) Next(dest []driver.Value) error {
+    if r.next >= len(r.rows) {
+        return io.EOF
+    }
+    if r.onRow != nil {
+        r.onRow(r.next)
+    }
+    copy(dest, r.rows[r.next])
+    r.next++
+    return nil
+}
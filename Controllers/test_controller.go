@@ -1,180 +1,943 @@
 package controllers
 
 import (
+    "context"
     "database/sql"
     "encoding/json"
+    "encoding/xml"
+    "errors"
+    "fmt"
+    "log"
     "net/http"
     "strconv"
+    "strings"
+    "time"
+    "unicode/utf8"
     
+    "backend/Dberr"
     "backend/Models"
-    _ "github.com/lib/pq"
+    "github.com/lib/pq"
+    "golang.org/x/sync/singleflight"
 )
 
 type TestController struct {
     DB *sql.DB
+    // ReadDB serves read-only queries (a replica pool); nil means reads use DB
+    ReadDB *sql.DB
+    // Audit records mutations when audit logging is enabled (nil disables it)
+    Audit *AuditLogger
+    // Metrics counts each operation's outcome (nil disables it)
+    Metrics *OperationMetrics
+    // AllowDestructive enables DeleteAll; only for test environments
+    AllowDestructive bool
+    // DefaultPageSize is used when a list request has no limit
+    DefaultPageSize int
+    // MaxPageSize is the largest limit a list request can get; larger values are clamped
+    MaxPageSize int
+    // MaxFields caps the entries in a fields parameter; 0 uses the built-in MaxFields
+    MaxFields int
+    // NameUniquePerBoard scopes name uniqueness to the request's board: inserts record
+    // it in "BoardId", which is uniquely indexed with "Name" (migration 003)
+    NameUniquePerBoard bool
+    // LenientQuery restores taking the first value of a repeated list parameter
+    // instead of rejecting the request
+    LenientQuery bool
+    // AcquireTimeout bounds a handler's database calls, the wait for a pool
+    // connection included (see dbContext); 0 leaves them unbounded
+    AcquireTimeout time.Duration
+    
+    // byIdGroup coalesces concurrent GetById lookups for the same id
+    byIdGroup singleflight.Group
+    // stmts are the prepared fixed-shape queries (see PrepareStatements)
+    stmts *preparedStatements
 }
 
+// NewTestController creates a controller for db. PrepareStatements must be
+// called before it serves requests.
 func NewTestController(db *sql.DB) *TestController {
-    return &TestController{DB: db}
+    return &TestController{
+        DB:              db,
+        DefaultPageSize: DefaultPageSize,
+        MaxPageSize:     MaxPageSize,
+    }
 }
 
-func (tc *TestController) setSearchPath() error {
-    // Set search_path to public schema (required because isolated role has restricted search_path)
-    // Using string concatenation to avoid C# string interpolation issues
-    _, err := tc.DB.Exec(`SET search_path = public, "$` + `user"`)
-    return err
+// reader returns the pool used for read-only queries, falling back to the primary
+func (tc *TestController) reader() *sql.DB {
+    if tc.ReadDB != nil {
+        return tc.ReadDB
+    }
+    return tc.DB
+}
+
+// dbContext bounds a handler's database calls by AcquireTimeout (DB_ACQUIRE_TIMEOUT_MS).
+// Once every pool connection is in use database/sql queues callers indefinitely,
+// which looks like a hang; with the deadline on the calls themselves a request that
+// can't get a connection in time fails fast with 503 (writeDBError). The deadline
+// covers running the queries too, so it must exceed the slowest of them.
+func (tc *TestController) dbContext(ctx context.Context) (context.Context, context.CancelFunc) {
+    if tc.AcquireTimeout <= 0 {
+        return ctx, func() {}
+    }
+    return context.WithTimeout(ctx, tc.AcquireTimeout)
 }
 
 func (tc *TestController) GetAll(w http.ResponseWriter, r *http.Request) {
-    // This will cause a runtime panic (nil pointer dereference)
-    var nilSlice []int
-    _ = nilSlice[0]  // Panic: runtime error: index out of range
+    w, done := tc.track("GetAll", w)
+    defer done()
     
-    if err := tc.setSearchPath(); err != nil {
-        http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+    format := negotiateFormat(r)
+    if format == "" {
+        http.Error(w, "Not Acceptable", http.StatusNotAcceptable)
         return
     }
     
-    // ... rest of the code
+    params, err := tc.parseListParams(r)
+    if err != nil {
+        http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    if len(params.Fields) > 0 && format == formatXML {
+        http.Error(w, "Invalid query: fields is not supported for XML responses", http.StatusBadRequest)
+        return
+    }
+    
+    ctx, cancel := tc.dbContext(r.Context())
+    defer cancel()
+    db := tc.reader()
+    
+    qb := newQueryBuilder()
+    if params.Query != "" {
+        if err := qb.WhereContains("Name", params.Query); err != nil {
+            http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+            return
+        }
+    }
+    
+    page, err := queryPage(ctx, db, qb, params)
+    if err != nil {
+        writeDBError(w, r, err)
+        return
+    }
+    writePage(w, format, params, page)
+}
 
+// Search filters projects by any combination of name (substring), createdAfter
+// and createdBefore (RFC3339), paginated the same way as GetAll
+func (tc *TestController) Search(w http.ResponseWriter, r *http.Request) {
+    w, done := tc.track("Search", w)
+    defer done()
+    
+    format := negotiateFormat(r)
+    if format == "" {
+        http.Error(w, "Not Acceptable", http.StatusNotAcceptable)
+        return
+    }
+    
+    params, err := tc.parseListParams(r)
+    if err != nil {
+        http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    if len(params.Fields) > 0 && format == formatXML {
+        http.Error(w, "Invalid query: fields is not supported for XML responses", http.StatusBadRequest)
+        return
+    }
+    
+    query := r.URL.Query()
+    qb := newQueryBuilder()
+    
+    if name := strings.TrimSpace(query.Get("name")); name != "" {
+        if err := qb.WhereContains("Name", name); err != nil {
+            http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+            return
+        }
+    }
     
-    rows, err := tc.DB.Query(`SELECT "Id", "Name" FROM "TestProjects" ORDER BY "Id"`)
+    var createdAfter, createdBefore time.Time
+    if raw := query.Get("createdAfter"); raw != "" {
+        if createdAfter, err = time.Parse(time.RFC3339, raw); err != nil {
+            http.Error(w, "Invalid query: createdAfter must be an RFC3339 timestamp", http.StatusBadRequest)
+            return
+        }
+        if err := qb.Where("CreatedAt", ">", createdAfter); err != nil {
+            http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+            return
+        }
+    }
+    if raw := query.Get("createdBefore"); raw != "" {
+        if createdBefore, err = time.Parse(time.RFC3339, raw); err != nil {
+            http.Error(w, "Invalid query: createdBefore must be an RFC3339 timestamp", http.StatusBadRequest)
+            return
+        }
+        if err := qb.Where("CreatedAt", "<", createdBefore); err != nil {
+            http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+            return
+        }
+    }
+    if !createdAfter.IsZero() && !createdBefore.IsZero() && !createdAfter.Before(createdBefore) {
+        http.Error(w, "Invalid query: createdAfter must be before createdBefore", http.StatusBadRequest)
+        return
+    }
+    
+    ctx, cancel := tc.dbContext(r.Context())
+    defer cancel()
+    db := tc.reader()
+    
+    page, err := queryPage(ctx, db, qb, params)
     if err != nil {
-        http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, err)
+        return
+    }
+    writePage(w, format, params, page)
+}
+
+// writePage writes a list response. The envelope is opt-in (?envelope=true) so
+// clients written against the original bare-array GetAll keep working; they get the
+// paging metadata as X-Has-More / X-Total-Count headers instead. JSON:API responses
+// always use the envelope, since the document format has its own place for it (meta).
+func writePage(w http.ResponseWriter, format string, params listParams, page pageEnvelope) {
+    if params.Envelope || format == formatJSONAPI {
+        writeNegotiated(w, format, http.StatusOK, page)
         return
     }
+    
+    w.Header().Set("X-Has-More", strconv.FormatBool(page.HasMore))
+    if page.NextCursor != "" {
+        w.Header().Set("X-Next-Cursor", page.NextCursor)
+    }
+    if page.Total != nil {
+        w.Header().Set("X-Total-Count", strconv.Itoa(*page.Total))
+    }
+    writeNegotiated(w, format, http.StatusOK, page.Items)
+}
+
+// queryPage runs the paged SELECT for the filters in qb, applying the sort and page
+// from params. One extra row is fetched to set hasMore without counting; a total is
+// only computed when params.CountMode asks for one.
+func queryPage(ctx context.Context, db *sql.DB, qb *QueryBuilder, params listParams) (pageEnvelope, error) {
+    page := pageEnvelope{Limit: params.Limit, Offset: params.Offset}
+    
+    switch params.CountMode {
+    case countModeExact:
+        where, whereArgs := qb.WhereSQL()
+        var total int
+        if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM "TestProjects"`+where, whereArgs...).Scan(&total); err != nil {
+            return page, err
+        }
+        page.Total = &total
+    case countModeEstimate:
+        total, err := estimateCount(ctx, db, qb)
+        if err != nil {
+            return page, err
+        }
+        page.Total = &total
+        page.Estimated = true
+    }
+    
+    if err := qb.OrderBy(params.Sort, params.Dir); err != nil {
+        return page, err
+    }
+    qb.Page(params.Limit+1, params.Offset)
+    query, args := qb.SelectSQL(`SELECT "Id", "Name" FROM "TestProjects"`)
+    rows, err := db.QueryContext(ctx, query, args...)
+    if err != nil {
+        return page, err
+    }
     defer rows.Close()
     
-    var projects []models.TestProjects
+    projects := []models.TestProjects{}
     for rows.Next() {
+        // Stop as soon as the client is gone rather than scanning a page nobody will
+        // read; the deferred Close releases the cursor and the caller answers 499
+        if err := ctx.Err(); err != nil {
+            return page, err
+        }
         var project models.TestProjects
         if err := rows.Scan(&project.Id, &project.Name); err != nil {
-            http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
-            return
+            return page, err
         }
         projects = append(projects, project)
     }
+    if err := rows.Err(); err != nil {
+        return page, err
+    }
+    
+    if len(projects) > params.Limit {
+        projects = projects[:params.Limit]
+        page.HasMore = true
+        page.NextCursor = encodeCursor(params.Offset + params.Limit)
+    }
     
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(projects)
+    page.Items = projects
+    if len(params.Fields) > 0 {
+        page.Items = selectFields(projects, params.Fields)
+    }
+    return page, nil
 }
 
-func (tc *TestController) GetById(w http.ResponseWriter, r *http.Request, id int) {
-    if err := tc.setSearchPath(); err != nil {
-        http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+// estimateCount returns the planner's row estimate for the filters in qb. Unfiltered
+// it reads the table statistics (pg_class.reltuples); filtered it asks EXPLAIN.
+// Both are only as fresh as the last ANALYZE.
+func estimateCount(ctx context.Context, db *sql.DB, qb *QueryBuilder) (int, error) {
+    where, whereArgs := qb.WhereSQL()
+    if where == "" {
+        var estimate float64
+        err := db.QueryRowContext(ctx, `SELECT reltuples FROM pg_class WHERE oid = 'public."TestProjects"'::regclass`).Scan(&estimate)
+        if err != nil {
+            return 0, err
+        }
+        // -1 means the table has never been analyzed
+        if estimate < 0 {
+            estimate = 0
+        }
+        return int(estimate), nil
+    }
+    
+    var plan []byte
+    if err := db.QueryRowContext(ctx, `EXPLAIN (FORMAT JSON) SELECT 1 FROM "TestProjects"`+where, whereArgs...).Scan(&plan); err != nil {
+        return 0, err
+    }
+    var explained []struct {
+        Plan struct {
+            Rows float64 `json:"Plan Rows"`
+        } `json:"Plan"`
+    }
+    if err := json.Unmarshal(plan, &explained); err != nil || len(explained) == 0 {
+        return 0, fmt.Errorf("unexpected EXPLAIN output: %s", plan)
+    }
+    return int(explained[0].Plan.Rows), nil
+}
+
+func (tc *TestController) GetById(w http.ResponseWriter, r *http.Request, id models.ID) {
+    w, done := tc.track("GetById", w)
+    defer done()
+    
+    format := negotiateFormat(r)
+    if format == "" {
+        http.Error(w, "Not Acceptable", http.StatusNotAcceptable)
         return
     }
     
-    var project models.TestProjects
-    err := tc.DB.QueryRow(`SELECT "Id", "Name" FROM "TestProjects" WHERE "Id" = $1`, id).
-        Scan(&project.Id, &project.Name)
+    // Concurrent requests for the same id share a single DB round-trip. The shared
+    // lookup runs on its own bounded context so a client that goes away doesn't fail
+    // it for everyone else waiting on it; each caller still stops waiting at its own
+    // deadline (MAX_RESPONSE_MS) or disconnect.
+    ch := tc.byIdGroup.DoChan(id.String(), func() (interface{}, error) {
+        ctx, cancel := context.WithTimeout(context.Background(), sharedLookupTimeout)
+        defer cancel()
+        ctx, cancelAcquire := tc.dbContext(ctx)
+        defer cancelAcquire()
+        return tc.fetchById(ctx, id)
+    })
+    var result interface{}
+    var err error
+    select {
+    case res := <-ch:
+        result, err = res.Val, res.Err
+    case <-r.Context().Done():
+        err = r.Context().Err()
+    }
+    
+    if dberr.IsNotFound(err) {
+        writeNotFound(w, id)
+        return
+    }
+    if err != nil {
+        writeDBError(w, r, err)
+        return
+    }
+    
+    project := result.(models.TestProjects)
+    w.Header().Set("ETag", projectETag(project))
+    writeNegotiated(w, format, http.StatusOK, project)
+}
 
-    if err == sql.ErrNoRows {
-        http.Error(w, "Project not found", http.StatusNotFound)
+// sharedLookupTimeout bounds a coalesced GetById lookup, which no single request's
+// deadline applies to
+const sharedLookupTimeout = 10 * time.Second
+
+// GetByName returns the project whose name is exactly ?name= (case-sensitive). Names
+// are only guaranteed unique per board with NAME_UNIQUE_PER_BOARD, in which case the
+// lookup is limited to the request's board; when more than one project matches the
+// response is 409 with the matching ids instead of an arbitrary pick.
+func (tc *TestController) GetByName(w http.ResponseWriter, r *http.Request) {
+    w, done := tc.track("GetByName", w)
+    defer done()
+    
+    format := negotiateFormat(r)
+    if format == "" {
+        http.Error(w, "Not Acceptable", http.StatusNotAcceptable)
         return
     }
+    name := r.URL.Query().Get("name")
+    if !utf8.ValidString(name) {
+        http.Error(w, "Invalid query: name "+errInvalidUTF8.Error(), http.StatusBadRequest)
+        return
+    }
+    if errs := fieldErrors("name", name); len(errs) > 0 {
+        writeValidationErrors(w, errs)
+        return
+    }
+    
+    query := `SELECT "Id", "Name" FROM public."TestProjects" WHERE "Name" = $1`
+    args := []interface{}{name}
+    if board := BoardIDFromContext(r.Context()); tc.NameUniquePerBoard && board != "" {
+        query += ` AND "BoardId" = $2`
+        args = append(args, board)
+    }
+    // A few matches are enough to tell the client which ids are ambiguous
+    query += ` ORDER BY "Id" LIMIT ` + strconv.Itoa(maxAmbiguousMatches)
+    
+    ctx, cancel := tc.dbContext(r.Context())
+    defer cancel()
+    rows, err := tc.reader().QueryContext(ctx, query, args...)
     if err != nil {
-        http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, err)
+        return
+    }
+    defer rows.Close()
+    
+    var matches []models.TestProjects
+    for rows.Next() {
+        var project models.TestProjects
+        if err := rows.Scan(&project.Id, &project.Name); err != nil {
+            writeDBError(w, r, err)
+            return
+        }
+        matches = append(matches, project)
+    }
+    if err := rows.Err(); err != nil {
+        writeDBError(w, r, err)
         return
     }
     
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(project)
+    switch len(matches) {
+    case 0:
+        WriteJSON(w, http.StatusNotFound, errorBody{Error: errorDetail{
+            Code:    "not_found",
+            Message: "no project named " + strconv.Quote(name),
+        }})
+    case 1:
+        w.Header().Set("ETag", projectETag(matches[0]))
+        writeNegotiated(w, format, http.StatusOK, matches[0])
+    default:
+        ids := make([]models.ID, len(matches))
+        for i, project := range matches {
+            ids[i] = project.Id
+        }
+        WriteJSON(w, http.StatusConflict, ambiguousNameBody{
+            Error: errorDetail{
+                Code:    "ambiguous",
+                Message: "more than one project is named " + strconv.Quote(name),
+            },
+            Ids: ids,
+        })
+    }
 }
 
-func (tc *TestController) Create(w http.ResponseWriter, r *http.Request) {
+// maxAmbiguousMatches bounds how many matching ids a 409 from GetByName lists
+const maxAmbiguousMatches = 10
+
+// ambiguousNameBody is the 409 body of GetByName: the error plus (some of) the ids
+// that share the name
+type ambiguousNameBody struct {
+    Error errorDetail `json:"error"`
+    Ids   []models.ID `json:"ids"`
+}
+
+// fetchById loads a single project from the read pool
+func (tc *TestController) fetchById(ctx context.Context, id models.ID) (models.TestProjects, error) {
     var project models.TestProjects
-    if err := json.NewDecoder(r.Body).Decode(&project); err != nil {
-        http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+    err := tc.stmts.selectById.QueryRowContext(ctx, id).Scan(&project.Id, &project.Name)
+    return project, err
+}
+
+// Exists answers 204 if the project exists and 404 if not, without a body,
+// so clients can check presence without transferring the row
+func (tc *TestController) Exists(w http.ResponseWriter, r *http.Request, id models.ID) {
+    w, done := tc.track("Exists", w)
+    defer done()
+    
+    ctx, cancel := tc.dbContext(r.Context())
+    defer cancel()
+    var one int
+    err := tc.stmts.exists.QueryRowContext(ctx, id).Scan(&one)
+    if dberr.IsNotFound(err) {
+        w.WriteHeader(http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        writeDBError(w, r, err)
+        return
+    }
+    
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// maxIdsPerRequest caps how many ids can be resolved in a single GetByIds call
+const maxIdsPerRequest = 100
+
+// GetByIds resolves a comma-separated list of ids (?ids=1,2,3) in one query.
+// Rows are returned in the requested order; ids that don't exist are omitted,
+// or listed under "missing" when ?reportMissing=true is set.
+func (tc *TestController) GetByIds(w http.ResponseWriter, r *http.Request) {
+    w, done := tc.track("GetByIds", w)
+    defer done()
+    
+    ids, err := parseIds(r.URL.Query().Get("ids"))
+    if err != nil {
+        http.Error(w, "Invalid ids: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    ctx, cancel := tc.dbContext(r.Context())
+    defer cancel()
+    rows, err := tc.stmts.selectByIds.QueryContext(ctx, pq.Array(idValues(ids)))
+    if err != nil {
+        writeDBError(w, r, err)
         return
     }
+    defer rows.Close()
+
+    found := make(map[models.ID]models.TestProjects, len(ids))
+    for rows.Next() {
+        var project models.TestProjects
+        if err := rows.Scan(&project.Id, &project.Name); err != nil {
+            writeDBError(w, r, err)
+            return
+        }
+        found[project.Id] = project
+    }
+    if err := rows.Err(); err != nil {
+        writeDBError(w, r, err)
+        return
+    }
+
+    // Preserve the order the ids were requested in rather than the DB order
+    projects := make([]models.TestProjects, 0, len(ids))
+    missing := []models.ID{}
+    for _, id := range ids {
+        if project, ok := found[id]; ok {
+            projects = append(projects, project)
+        } else {
+            missing = append(missing, id)
+        }
+    }
+
+    if r.URL.Query().Get("reportMissing") == "true" {
+        WriteJSON(w, http.StatusOK, map[string]interface{}{"items": projects, "missing": missing})
+        return
+    }
+    WriteJSON(w, http.StatusOK, projects)
+}
+
+// idValues converts ids for pq.Array, which only knows the built-in integer types
+func idValues(ids []models.ID) []int64 {
+    values := make([]int64, len(ids))
+    for i, id := range ids {
+        values[i] = int64(id)
+    }
+    return values
+}
+
+// parseIds parses and de-duplicates a comma-separated id list, keeping the first
+// occurrence of each id so the caller's ordering is preserved
+func parseIds(raw string) ([]models.ID, error) {
+    parts := strings.Split(raw, ",")
+    if len(parts) > maxIdsPerRequest {
+        return nil, fmt.Errorf("at most %d ids are allowed", maxIdsPerRequest)
+    }
+
+    ids := make([]models.ID, 0, len(parts))
+    seen := make(map[models.ID]bool, len(parts))
+    for _, part := range parts {
+        part = strings.TrimSpace(part)
+        if part == "" {
+            return nil, fmt.Errorf("empty id in list")
+        }
+        id, err := models.ParseID(part)
+        if err != nil || id <= 0 {
+            return nil, fmt.Errorf("%q is not a valid id", part)
+        }
+        if seen[id] {
+            continue
+        }
+        seen[id] = true
+        ids = append(ids, id)
+    }
+    return ids, nil
+}
+
+func (tc *TestController) Create(w http.ResponseWriter, r *http.Request) {
+    w, done := tc.track("Create", w)
+    defer done()
     
-    if err := tc.setSearchPath(); err != nil {
-        http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+    project, err := decodeProject(r)
+    if err != nil {
+        writeDecodeError(w, err)
+        return
+    }
+    if errs := validateProject(project); len(errs) > 0 {
+        writeValidationErrors(w, errs)
         return
     }
     
-    err := tc.DB.QueryRow(
-        `INSERT INTO "TestProjects" ("Name") VALUES ($1) RETURNING "Id", "Name"`,
-        project.Name,
-    ).Scan(&project.Id, &project.Name)
+    if raw := r.URL.Query().Get("getOrCreate"); raw != "" {
+        getOrCreate, err := strconv.ParseBool(raw)
+        if err != nil {
+            http.Error(w, "Invalid query: getOrCreate must be true or false", http.StatusBadRequest)
+            return
+        }
+        if getOrCreate {
+            // Without board-scoped unique names nothing makes a name "taken", so the
+            // insert would always go ahead and the flag would silently do nothing
+            if !tc.NameUniquePerBoard || BoardIDFromContext(r.Context()) == "" {
+                http.Error(w, "Invalid query: getOrCreate needs project names to be unique per board and a board id", http.StatusBadRequest)
+                return
+            }
+            tc.getOrCreate(w, r, project)
+            return
+        }
+    }
+    
+    ctx, cancel := tc.dbContext(r.Context())
+    defer cancel()
+    err = tc.stmts.insert.QueryRowContext(ctx, tc.insertArgs(r, project.Name)...).Scan(&project.Id, &project.Name)
 
     if err != nil {
-        http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, err)
         return
     }
     
-    w.Header().Set("Content-Type", "application/json")
-    w.WriteHeader(http.StatusCreated)
-    json.NewEncoder(w).Encode(project)
+    tc.Audit.Record(r, "create", project.Id)
+    
+    WriteJSON(w, http.StatusCreated, project)
 }
 
-func (tc *TestController) Update(w http.ResponseWriter, r *http.Request, id int) {
-    var project models.TestProjects
-    if err := json.NewDecoder(r.Body).Decode(&project); err != nil {
-        http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+// getOrCreate is Create with ?getOrCreate=true: when the name is already taken the
+// existing project is returned with 200 instead of a 409. "Taken" means the insert hit
+// the unique index, which only exists per board (NAME_UNIQUE_PER_BOARD) and only
+// for projects with a board id; Create rejects the flag with 400 otherwise.
+//
+// The insert skips conflicts (ON CONFLICT DO NOTHING) and, if it inserted nothing,
+// the row it conflicted with is read back in the same transaction. Under READ
+// COMMITTED that row is visible even when a concurrent request just created it.
+func (tc *TestController) getOrCreate(w http.ResponseWriter, r *http.Request, project models.TestProjects) {
+    created := true
+    err := tc.inTx(r, func(ctx context.Context, tx *sql.Tx) error {
+        insert := tc.insertSQL("ON CONFLICT DO NOTHING")
+        err := tx.QueryRowContext(ctx, insert, tc.insertArgs(r, project.Name)...).Scan(&project.Id, &project.Name)
+        if !dberr.IsNotFound(err) {
+            return err
+        }
+        
+        created = false
+        query := `SELECT "Id", "Name" FROM public."TestProjects" WHERE "Name" = $1 ORDER BY "Id" LIMIT 1`
+        args := []interface{}{project.Name}
+        if tc.NameUniquePerBoard {
+            query = `SELECT "Id", "Name" FROM public."TestProjects" WHERE "Name" = $1 AND "BoardId" = NULLIF($2, '')`
+            args = append(args, BoardIDFromContext(ctx))
+        }
+        return tx.QueryRowContext(ctx, query, args...).Scan(&project.Id, &project.Name)
+    })
+    if err != nil {
+        writeDBError(w, r, err)
+        return
+    }
+    
+    if !created {
+        WriteJSON(w, http.StatusOK, project)
         return
     }
+    tc.Audit.Record(r, "create", project.Id)
+    WriteJSON(w, http.StatusCreated, project)
+}
+
+func (tc *TestController) Update(w http.ResponseWriter, r *http.Request, id models.ID) {
+    w, done := tc.track("Update", w)
+    defer done()
     
-    if err := tc.setSearchPath(); err != nil {
-        http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+    project, err := decodeProject(r)
+    if err != nil {
+        writeDecodeError(w, err)
+        return
+    }
+    if errs := validateProject(project); len(errs) > 0 {
+        writeValidationErrors(w, errs)
         return
     }
     
-    result, err := tc.DB.Exec(
-        `UPDATE "TestProjects" SET "Name" = $1 WHERE "Id" = $2`,
-        project.Name, id,
-    )
+    // With If-Match the update only proceeds if the client's ETag is still current
+    ctx, cancel := tc.dbContext(r.Context())
+    defer cancel()
+    var result sql.Result
+    if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+        result, err = tc.execIfMatch(ctx, ifMatch, id, tc.stmts.update, project.Name, id)
+    } else {
+        result, err = tc.stmts.update.ExecContext(ctx, project.Name, id)
+    }
+    if dberr.IsNotFound(err) {
+        writeNotFound(w, id)
+        return
+    }
+    if err == errPreconditionFailed {
+        writePreconditionFailed(w, id)
+        return
+    }
     if err != nil {
-        http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, err)
         return
     }
     
     rowsAffected, err := result.RowsAffected()
     if err != nil {
-        http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, err)
         return
     }
     
     if rowsAffected == 0 {
-        http.Error(w, "Project not found", http.StatusNotFound)
+        writeNotFound(w, id)
         return
     }
     
+    tc.Audit.Record(r, "update", id)
+    
     project.Id = id
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(project)
+    w.Header().Set("ETag", projectETag(project))
+    WriteJSON(w, http.StatusOK, project)
 }
 
-func (tc *TestController) Delete(w http.ResponseWriter, r *http.Request, id int) {
-    if err := tc.setSearchPath(); err != nil {
-        http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+// renameRequest is the body of PUT /api/test/rename
+type renameRequest struct {
+    From string `json:"from"`
+    To   string `json:"to"`
+}
+
+// Rename renames the project(s) currently called From to To. Both the lookup and the
+// update run in one transaction with the source rows locked, so a concurrent rename
+// can't slip in between the checks and the UPDATE.
+func (tc *TestController) Rename(w http.ResponseWriter, r *http.Request) {
+    w, done := tc.track("Rename", w)
+    defer done()
+    
+    var req renameRequest
+    if err := decodeJSON(r, &req); err != nil {
+        http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    if errs := append(fieldErrors("from", req.From), fieldErrors("to", req.To)...); len(errs) > 0 {
+        writeValidationErrors(w, errs)
+        return
+    }
+    
+    ctx, cancel := tc.dbContext(r.Context())
+    defer cancel()
+    tx, err := tc.DB.BeginTx(ctx, nil)
+    if err != nil {
+        writeDBError(w, r, err)
+        return
+    }
+    defer tx.Rollback()
+    
+    // With board-scoped names the source rows, the target that would collide and
+    // the rows renamed are all the request's board's
+    scope, board := tc.boardScope(ctx)
+    
+    // Lock every row the UPDATE will rename, not only the first, so none of them can
+    // be renamed or deleted by another request before it runs
+    locked, err := tx.QueryContext(ctx, `SELECT "Id" FROM public."TestProjects" WHERE "Name" = $1`+scope(2)+` ORDER BY "Id" FOR UPDATE`, append([]interface{}{req.From}, board...)...)
+    if err != nil {
+        writeDBError(w, r, err)
+        return
+    }
+    sourceIds, err := scanIds(locked)
+    if err != nil {
+        writeDBError(w, r, err)
+        return
+    }
+    if len(sourceIds) == 0 {
+        WriteJSON(w, http.StatusNotFound, errorBody{Error: errorDetail{
+            Code:    "not_found",
+            Message: "no project named " + strconv.Quote(req.From),
+        }})
+        return
+    }
+    
+    if req.To != req.From {
+        var targetExists bool
+        err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM public."TestProjects" WHERE "Name" = $1`+scope(2)+`)`, append([]interface{}{req.To}, board...)...).Scan(&targetExists)
+        if err != nil {
+            writeDBError(w, r, err)
+            return
+        }
+        if targetExists {
+            WriteJSON(w, http.StatusConflict, errorBody{Error: errorDetail{
+                Code:    "conflict",
+                Message: "a project named " + strconv.Quote(req.To) + " already exists",
+            }})
+            return
+        }
+    }
+    
+    rows, err := tx.QueryContext(ctx, `UPDATE public."TestProjects" SET "Name" = $1 WHERE "Name" = $2`+scope(3)+` RETURNING "Id"`, append([]interface{}{req.To, req.From}, board...)...)
+    if err != nil {
+        writeDBError(w, r, err)
         return
     }
+    ids, err := scanIds(rows)
+    if err != nil {
+        writeDBError(w, r, err)
+        return
+    }
+    
+    if err := tx.Commit(); err != nil {
+        writeDBError(w, r, err)
+        return
+    }
+    
+    for _, id := range ids {
+        tc.Audit.Record(r, "rename", id)
+    }
+    
+    project := models.TestProjects{Id: sourceIds[0], Name: req.To}
+    w.Header().Set("ETag", projectETag(project))
+    WriteJSON(w, http.StatusOK, project)
+}
+
+// scanIds reads a single-column result of ids and closes rows
+func scanIds(rows *sql.Rows) ([]models.ID, error) {
+    defer rows.Close()
+    var ids []models.ID
+    for rows.Next() {
+        var id models.ID
+        if err := rows.Scan(&id); err != nil {
+            return nil, err
+        }
+        ids = append(ids, id)
+    }
+    return ids, rows.Err()
+}
+
+// boardScope returns, when names are unique per board (NAME_UNIQUE_PER_BOARD), a
+// function giving the condition that limits a query to the request's board as
+// parameter $n, and the board id argument for it; otherwise the condition is empty
+// and there is no argument
+func (tc *TestController) boardScope(ctx context.Context) (func(n int) string, []interface{}) {
+    if !tc.NameUniquePerBoard {
+        return func(int) string { return "" }, nil
+    }
+    return func(n int) string {
+        return fmt.Sprintf(` AND "BoardId" IS NOT DISTINCT FROM NULLIF($%d, '')`, n)
+    }, []interface{}{BoardIDFromContext(ctx)}
+}
+
+func (tc *TestController) Delete(w http.ResponseWriter, r *http.Request, id models.ID) {
+    w, done := tc.track("Delete", w)
+    defer done()
     
-    result, err := tc.DB.Exec(`DELETE FROM "TestProjects" WHERE "Id" = $1`, id)
+    // With If-Match the delete only proceeds if the client's ETag is still current
+    ctx, cancel := tc.dbContext(r.Context())
+    defer cancel()
+    var result sql.Result
+    var err error
+    if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+        result, err = tc.execIfMatch(ctx, ifMatch, id, tc.stmts.delete, id)
+    } else {
+        result, err = tc.stmts.delete.ExecContext(ctx, id)
+    }
+    if dberr.IsNotFound(err) {
+        writeNotFound(w, id)
+        return
+    }
+    if err == errPreconditionFailed {
+        writePreconditionFailed(w, id)
+        return
+    }
+    // foreign_key_violation: other rows still reference this project. The constraint
+    // name and detail stay in the server log rather than leaking to the client.
+    if dberr.IsForeignKeyViolation(err) {
+        var pqErr *pq.Error
+        errors.As(err, &pqErr)
+        LoggerFromContext(r.Context()).Printf("[DB CONFLICT] Delete of project %d blocked by %s: %s", id, pqErr.Constraint, pqErr.Detail)
+        WriteJSON(w, http.StatusConflict, map[string]string{"error": "cannot delete: project is referenced by other records"})
+        return
+    }
     if err != nil {
-        http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, err)
         return
     }
     
     rowsAffected, err := result.RowsAffected()
     if err != nil {
-        http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, err)
         return
     }
     
     if rowsAffected == 0 {
-        http.Error(w, "Project not found", http.StatusNotFound)
+        writeNotFound(w, id)
         return
     }
     
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(map[string]string{"message": "Deleted successfully"})
+    tc.Audit.Record(r, "delete", id)
+    
+    WriteJSON(w, http.StatusOK, map[string]string{"message": "Deleted successfully"})
 }
 
-func ExtractId(path string) (int, error) {
+func ExtractId(path string) (models.ID, error) {
     // Extract ID from path like /api/test/123
     idStr := path[len("/api/test/"):]
-    return strconv.Atoi(idStr)
+    return models.ParseID(idStr)
+}
+
+const (
+    formatJSON    = "application/json"
+    formatXML     = "application/xml"
+    formatJSONAPI = "application/vnd.api+json"
+)
+
+// testProjectsList is the XML root element for collection responses, since
+// encoding/xml can't marshal a bare slice as a single document
+type testProjectsList struct {
+    XMLName  xml.Name              `xml:"TestProjectsList"`
+    Projects []models.TestProjects `xml:"TestProjects"`
+}
+
+// availableFormats are the response encodings in order of preference; text/xml is
+// accepted as an alias for application/xml
+var availableFormats = []string{formatJSON, formatXML, "text/xml", formatJSONAPI}
+
+// negotiateFormat picks the response encoding from the Accept header (see negotiate).
+// JSON is the default; XML and JSON:API are opt-in. Returns "" when the client
+// explicitly asks only for types we can't produce (caller should send 406).
+func negotiateFormat(r *http.Request) string {
+    format := negotiate(r.Header.Get("Accept"), availableFormats)
+    if format == "text/xml" {
+        return formatXML
+    }
+    return format
+}
+
+// writeNegotiated encodes v as JSON, JSON:API or XML depending on the negotiated format
+func writeNegotiated(w http.ResponseWriter, format string, status int, v interface{}) {
+    if format == formatJSONAPI {
+        writeJSONAs(w, formatJSONAPI, status, toJSONAPI(v))
+        return
+    }
+    
+    if format == formatXML {
+        if projects, ok := v.([]models.TestProjects); ok {
+            v = testProjectsList{Projects: projects}
+        }
+        body, err := xml.Marshal(v)
+        if err != nil {
+            log.Printf("[RESPONSE ERROR] Failed to encode %T XML response (status %d), sending 500 instead: %v", v, status, err)
+            writeEncodeFailure(w)
+            return
+        }
+        writeBody(w, "application/xml", status, append([]byte(xml.Header), body...), v)
+        return
+    }
+    
+    WriteJSON(w, status, v)
 }
@@ -0,0 +1,416 @@
+package controllers
+
+import (
+    "bytes"
+    "context"
+    "database/sql/driver"
+    "encoding/json"
+    "log"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync/atomic"
+    "testing"
+    "time"
+    
+    "backend/Models"
+    "github.com/lib/pq"
+)
+
+// newStubController returns a controller on a stub pool of maxOpen connections with
+// its statements prepared
+func newStubController(t testing.TB, c *stubConnector, maxOpen int) *TestController {
+    t.Helper()
+    tc := NewTestController(openStubDB(c, maxOpen))
+    if err := tc.PrepareStatements(context.Background()); err != nil {
+        t.Fatalf("PrepareStatements: %v", err)
+    }
+    t.Cleanup(func() { tc.DB.Close() })
+    return tc
+}
+
+func TestAcquireTimeoutAnswers503WhenPoolIsExhausted(t *testing.T) {
+    tc := newStubController(t, &stubConnector{rows: [][]driver.Value{{int64(1)}}}, 1)
+    tc.AcquireTimeout = 50 * time.Millisecond
+    
+    // Hold the only connection so the handler has to wait for one
+    held, err := tc.DB.Conn(context.Background())
+    if err != nil {
+        t.Fatalf("Conn: %v", err)
+    }
+    defer held.Close()
+    
+    start := time.Now()
+    w := httptest.NewRecorder()
+    tc.Exists(w, httptest.NewRequest(http.MethodGet, "/api/test/1/exists", nil), 1)
+    elapsed := time.Since(start)
+    
+    if w.Code != http.StatusServiceUnavailable {
+        t.Fatalf("status = %d, want 503", w.Code)
+    }
+    if w.Header().Get("Retry-After") == "" {
+        t.Error("Retry-After not set")
+    }
+    if elapsed > time.Second {
+        t.Errorf("took %s, want about the 50ms acquire timeout", elapsed)
+    }
+}
+
+func TestAcquireTimeoutLetsQueriesRunWhenPoolIsFree(t *testing.T) {
+    tc := newStubController(t, &stubConnector{rows: [][]driver.Value{{int64(1)}}}, 1)
+    tc.AcquireTimeout = 50 * time.Millisecond
+    
+    w := httptest.NewRecorder()
+    tc.Exists(w, httptest.NewRequest(http.MethodGet, "/api/test/1/exists", nil), 1)
+    if w.Code != http.StatusNoContent {
+        t.Fatalf("status = %d, want 204", w.Code)
+    }
+}
+
+func TestGetByIdCoalescesConcurrentLookups(t *testing.T) {
+    stub := &stubConnector{rows: [][]driver.Value{{int64(7), "seven"}}, delay: 200 * time.Millisecond}
+    tc := newStubController(t, stub, 10)
+    
+    const callers = 8
+    codes := make(chan int, callers)
+    for i := 0; i < callers; i++ {
+        go func() {
+            w := httptest.NewRecorder()
+            tc.GetById(w, httptest.NewRequest(http.MethodGet, "/api/test/7", nil), 7)
+            codes <- w.Code
+        }()
+    }
+    for i := 0; i < callers; i++ {
+        if code := <-codes; code != http.StatusOK {
+            t.Errorf("status = %d, want 200", code)
+        }
+    }
+    if n := stub.queries.Load(); n != 1 {
+        t.Errorf("%d queries for %d concurrent lookups, want 1", n, callers)
+    }
+}
+
+func TestGetByIdStopsWaitingAtCallerDeadline(t *testing.T) {
+    stub := &stubConnector{rows: [][]driver.Value{{int64(7), "seven"}}, delay: time.Second}
+    tc := newStubController(t, stub, 1)
+    
+    ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+    defer cancel()
+    start := time.Now()
+    w := httptest.NewRecorder()
+    tc.GetById(w, httptest.NewRequest(http.MethodGet, "/api/test/7", nil).WithContext(ctx), 7)
+    
+    if w.Code != http.StatusServiceUnavailable {
+        t.Errorf("status = %d, want 503", w.Code)
+    }
+    if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+        t.Errorf("took %s, want about the 50ms request deadline", elapsed)
+    }
+}
+
+// projectRows returns n stub rows of projects 1..n
+func projectRows(n int) [][]driver.Value {
+    rows := make([][]driver.Value, n)
+    for i := range rows {
+        rows[i] = []driver.Value{int64(i + 1), "project"}
+    }
+    return rows
+}
+
+func getAll(tc *TestController, target string) *httptest.ResponseRecorder {
+    w := httptest.NewRecorder()
+    tc.GetAll(w, httptest.NewRequest(http.MethodGet, target, nil))
+    return w
+}
+
+func TestGetAllReturnsPageWithHeaders(t *testing.T) {
+    // The stub returns every row; the handler asks for limit+1 to detect more
+    stub := &stubConnector{rows: projectRows(3)}
+    tc := newStubController(t, stub, 1)
+    
+    w := getAll(tc, "/api/test?limit=2")
+    if w.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+    }
+    var items []map[string]interface{}
+    if err := json.Unmarshal(w.Body.Bytes(), &items); err != nil {
+        t.Fatalf("body %q is not a JSON array: %v", w.Body.String(), err)
+    }
+    if len(items) != 2 {
+        t.Errorf("%d items, want 2", len(items))
+    }
+    if w.Header().Get("X-Has-More") != "true" || w.Header().Get("X-Next-Cursor") == "" {
+        t.Errorf("paging headers = %v, want X-Has-More true and a cursor", w.Header())
+    }
+    
+    queries := stub.ran()
+    last := queries[len(queries)-1]
+    if !strings.Contains(last, `ORDER BY "Id" ASC LIMIT $1 OFFSET $2`) {
+        t.Errorf("query = %s, want the default sort and page", last)
+    }
+}
+
+func TestGetAllLastPage(t *testing.T) {
+    tc := newStubController(t, &stubConnector{rows: projectRows(2)}, 1)
+    w := getAll(tc, "/api/test?limit=5")
+    if w.Header().Get("X-Has-More") != "false" || w.Header().Get("X-Next-Cursor") != "" {
+        t.Errorf("paging headers = %v, want no more pages", w.Header())
+    }
+}
+
+func TestGetAllEnvelopeWithExactCount(t *testing.T) {
+    stub := &stubConnector{rowsFor: func(query string) [][]driver.Value {
+        if strings.Contains(query, "COUNT(*)") {
+            return [][]driver.Value{{int64(42)}}
+        }
+        return projectRows(1)
+    }}
+    tc := newStubController(t, stub, 1)
+    
+    w := getAll(tc, "/api/test?envelope=true&countMode=exact")
+    if w.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+    }
+    var page struct {
+        Items   []interface{} `json:"items"`
+        Total   *int          `json:"total"`
+        HasMore bool          `json:"hasMore"`
+    }
+    if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+        t.Fatalf("decode: %v", err)
+    }
+    if len(page.Items) != 1 || page.Total == nil || *page.Total != 42 || page.HasMore {
+        t.Errorf("page = %+v, want 1 item of 42 and no more", page)
+    }
+}
+
+func TestGetAllSelectsFields(t *testing.T) {
+    tc := newStubController(t, &stubConnector{rows: projectRows(1)}, 1)
+    w := getAll(tc, "/api/test?fields=Name")
+    if w.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+    }
+    var items []map[string]interface{}
+    if err := json.Unmarshal(w.Body.Bytes(), &items); err != nil {
+        t.Fatalf("decode: %v", err)
+    }
+    if len(items) != 1 || len(items[0]) != 1 || items[0]["Name"] != "project" {
+        t.Errorf("items = %v, want only Name", items)
+    }
+}
+
+func TestGetAllSearchBindsTheQuery(t *testing.T) {
+    stub := &stubConnector{}
+    tc := newStubController(t, stub, 1)
+    if w := getAll(tc, "/api/test?q=50%25_off"); w.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+    }
+    queries := stub.ran()
+    if last := queries[len(queries)-1]; !strings.Contains(last, `WHERE "Name" ILIKE $1`) {
+        t.Errorf("query = %s, want a bound ILIKE", last)
+    }
+}
+
+func TestGetAllRejectsBadQueries(t *testing.T) {
+    tests := []struct {
+        name   string
+        target string
+        accept string
+        // message lists what the 400 must mention, when anything
+        message []string
+    }{
+        {"unknown sort", "/api/test?sort=Password", "", nil},
+        {"bad dir", "/api/test?dir=sideways", "", nil},
+        {"bad limit", "/api/test?limit=0", "", nil},
+        {"repeated limit", "/api/test?limit=1&limit=2", "", []string{"duplicate query parameter: limit"}},
+        {"repeated offset", "/api/test?offset=0&offset=10", "", []string{"duplicate query parameter: offset"}},
+        {"repeated sort", "/api/test?sort=Id&sort=Name", "", []string{"duplicate query parameter: sort"}},
+        {"repeated q", "/api/test?q=a&q=b", "", []string{"duplicate query parameter: q"}},
+        {"fields with XML", "/api/test?fields=Name", "application/xml", nil},
+        {"too many fields", "/api/test?fields=" + strings.TrimSuffix(strings.Repeat("Id,", MaxFields+1), ","), "", []string{"at most 20 fields"}},
+        {"duplicate field", "/api/test?fields=Name,Id,name", "", []string{`duplicate field "name"`}},
+        {"unknown field", "/api/test?fields=Name,Password", "", []string{`unknown field "Password"`}},
+        {"every invalid field", "/api/test?fields=Password,Id,,id,Secret", "", []string{`unknown field "Password"`, `unknown field ""`, `duplicate field "id"`, `unknown field "Secret"`}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            stub := &stubConnector{}
+            tc := newStubController(t, stub, 1)
+            before := stub.queries.Load()
+            
+            r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+            if tt.accept != "" {
+                r.Header.Set("Accept", tt.accept)
+            }
+            w := httptest.NewRecorder()
+            tc.GetAll(w, r)
+            
+            if w.Code != http.StatusBadRequest {
+                t.Errorf("status = %d, want 400", w.Code)
+            }
+            for _, want := range tt.message {
+                if !strings.Contains(w.Body.String(), want) {
+                    t.Errorf("body %q does not mention %s", w.Body.String(), want)
+                }
+            }
+            if n := stub.queries.Load() - before; n != 0 {
+                t.Errorf("%d database calls, want none", n)
+            }
+        })
+    }
+}
+
+func TestRenameRenamesEveryProjectWithTheName(t *testing.T) {
+    pt := &projectTable{}
+    first := pt.add("draft", "")
+    pt.add("draft", "")
+    pt.add("other", "")
+    stub := &stubConnector{answer: pt.answer}
+    tc := newStubController(t, stub, 1)
+    
+    w := rename(tc, "", `{"from":"draft","to":"final"}`)
+    if w.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+    }
+    var body map[string]interface{}
+    if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+        t.Fatalf("body %q is not JSON: %v", w.Body, err)
+    }
+    if body["Id"] != float64(first) || body["Name"] != "final" {
+        t.Errorf("body = %v, want the first renamed project, Id %d named final", body, first)
+    }
+    if w.Header().Get("ETag") == "" {
+        t.Error("no ETag on the renamed project")
+    }
+    
+    names := map[string]int{}
+    for _, row := range pt.rows {
+        names[row.name]++
+    }
+    if names["final"] != 2 || names["draft"] != 0 || names["other"] != 1 {
+        t.Errorf("names after rename = %v, want both drafts renamed", names)
+    }
+    
+    // The lock covers every source row, not just the first
+    for _, query := range stub.ran() {
+        if strings.Contains(query, "FOR UPDATE") && strings.Contains(query, "LIMIT") {
+            t.Errorf("source rows locked with a LIMIT: %s", query)
+        }
+    }
+}
+
+func TestRenameUnknownSourceIs404(t *testing.T) {
+    pt := &projectTable{}
+    pt.add("draft", "")
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    
+    w := rename(tc, "", `{"from":"missing","to":"final"}`)
+    if w.Code != http.StatusNotFound {
+        t.Fatalf("status = %d, want 404: %s", w.Code, w.Body)
+    }
+    if !strings.Contains(w.Body.String(), `"not_found"`) {
+        t.Errorf("body = %s, want a not_found error", w.Body)
+    }
+}
+
+func TestRenameOntoExistingNameIs409(t *testing.T) {
+    pt := &projectTable{}
+    pt.add("draft", "")
+    pt.add("final", "")
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    
+    w := rename(tc, "", `{"from":"draft","to":"final"}`)
+    if w.Code != http.StatusConflict {
+        t.Fatalf("status = %d, want 409: %s", w.Code, w.Body)
+    }
+    if pt.rows[0].name != "draft" {
+        t.Errorf("source renamed to %q despite the conflict", pt.rows[0].name)
+    }
+}
+
+func TestDeleteReferencedProjectIsConflict(t *testing.T) {
+    tc := newStubController(t, &stubConnector{answer: func(query string, args []driver.Value) ([][]driver.Value, error) {
+        return nil, &pq.Error{Code: "23503", Constraint: "tasks_project_fk", Detail: "Key (Id)=(7) is still referenced"}
+    }}, 1)
+    var logged bytes.Buffer
+    req := httptest.NewRequest(http.MethodDelete, "/api/test/7", nil)
+    req = req.WithContext(WithLogger(req.Context(), log.New(&logged, "", 0)))
+    
+    w := httptest.NewRecorder()
+    tc.Delete(w, req, 7)
+    if w.Code != http.StatusConflict {
+        t.Fatalf("status = %d, want 409: %s", w.Code, w.Body)
+    }
+    if strings.Contains(w.Body.String(), "tasks_project_fk") {
+        t.Errorf("response leaks the constraint: %s", w.Body)
+    }
+    if !strings.Contains(logged.String(), "tasks_project_fk") {
+        t.Errorf("log %q does not name the constraint", logged.String())
+    }
+}
+
+func TestExistsAnswersWithoutBody(t *testing.T) {
+    pt := &projectTable{}
+    id := pt.add("roadmap", "")
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    
+    for _, method := range []string{http.MethodGet, http.MethodHead} {
+        for _, c := range []struct {
+            id   int64
+            want int
+        }{{id, http.StatusNoContent}, {id + 1, http.StatusNotFound}} {
+            w := httptest.NewRecorder()
+            tc.Exists(w, httptest.NewRequest(method, "/api/test/1/exists", nil), models.ID(c.id))
+            if w.Code != c.want {
+                t.Errorf("%s of project %d = %d, want %d", method, c.id, w.Code, c.want)
+            }
+            if w.Body.Len() != 0 {
+                t.Errorf("%s of project %d has a body: %s", method, c.id, w.Body)
+            }
+        }
+    }
+}
+
+func TestGetAllStopsScanningWhenClientDisconnects(t *testing.T) {
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    var read atomic.Int64
+    stub := &stubConnector{rows: projectRows(10000), onRow: func(n int) {
+        read.Store(int64(n))
+        if n == 10 {
+            cancel()
+        }
+    }}
+    tc := newStubController(t, stub, 1)
+    
+    w := httptest.NewRecorder()
+    tc.GetAll(w, httptest.NewRequest(http.MethodGet, "/api/test?limit=100", nil).WithContext(ctx))
+    if w.Code != statusClientClosedRequest {
+        t.Errorf("status = %d, want 499", w.Code)
+    }
+    if n := read.Load(); n > 11 {
+        t.Errorf("%d rows read after the client left at row 10", n)
+    }
+}
+
+func TestGetAllLenientQueryUsesFirstOfRepeatedParams(t *testing.T) {
+    stub := &stubConnector{rows: projectRows(3)}
+    tc := newStubController(t, stub, 1)
+    tc.LenientQuery = true
+    
+    w := getAll(tc, "/api/test?envelope=true&limit=2&limit=1&sort=Name&sort=Id&q=a&q=b&offset=0&offset=5")
+    if w.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+    }
+    var page pageEnvelope
+    if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+        t.Fatalf("body %s: %v", w.Body, err)
+    }
+    if page.Limit != 2 || page.Offset != 0 {
+        t.Errorf("limit %d offset %d, want the first values 2 and 0", page.Limit, page.Offset)
+    }
+    query := strings.Join(stub.ran(), "\n")
+    if !strings.Contains(query, `ORDER BY "Name"`) {
+        t.Errorf("queries %q do not sort by the first sort value", query)
+    }
+}
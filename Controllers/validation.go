@@ -0,0 +1,77 @@
+package controllers
+
+import (
+    "fmt"
+    "net/http"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+
+    "backend/Models"
+)
+
+// MaxNameLength is the longest project name accepted, in characters
+const MaxNameLength = 200
+
+// FieldError is one validation problem with one field of a request body
+type FieldError struct {
+    Field   string `json:"field"`
+    Message string `json:"message"`
+}
+
+// nameProblems lists everything wrong with a project name supplied in a body, query
+// or path, rather than stopping at the first problem. Names are always passed to SQL
+// as bound parameters; this only enforces presence, length, valid UTF-8 and printable
+// characters. The last two also keep out what Postgres would refuse in a text column
+// (invalid UTF-8, NUL, a control character), which would otherwise surface as a 500.
+// JSON bodies and the ?name= query are checked for invalid UTF-8 before this, with a
+// 400 (see decodeJSON), since decoding would already have replaced the bad bytes.
+func nameProblems(name string) []string {
+    var problems []string
+    if strings.TrimSpace(name) == "" {
+        problems = append(problems, "is required")
+    }
+    if !utf8.ValidString(name) {
+        problems = append(problems, "must be valid UTF-8")
+    }
+    if utf8.RuneCountInString(name) > MaxNameLength {
+        problems = append(problems, fmt.Sprintf("must be at most %d characters", MaxNameLength))
+    }
+    if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+        problems = append(problems, "must not contain control characters")
+    }
+    return problems
+}
+
+// validateName reports every problem with name as a single error, for callers that
+// report one message per item (bulk and import)
+func validateName(name string) error {
+    problems := nameProblems(name)
+    if len(problems) == 0 {
+        return nil
+    }
+    return fmt.Errorf("name %s", strings.Join(problems, "; "))
+}
+
+// fieldErrors collects the problems with a named field
+func fieldErrors(field, value string) []FieldError {
+    var errs []FieldError
+    for _, problem := range nameProblems(value) {
+        errs = append(errs, FieldError{Field: field, Message: field + " " + problem})
+    }
+    return errs
+}
+
+// validateProject returns every validation problem with a project body
+func validateProject(project models.TestProjects) []FieldError {
+    return fieldErrors(models.JSONFieldName("Name"), project.Name)
+}
+
+// writeValidationErrors reports all field problems at once with 422
+func writeValidationErrors(w http.ResponseWriter, errs []FieldError) {
+    WriteJSON(w, http.StatusUnprocessableEntity, errorBody{Error: errorDetail{
+        Code:    "validation_failed",
+        Message: fmt.Sprintf("%d validation problem(s)", len(errs)),
+        Fields:  errs,
+    }})
+}
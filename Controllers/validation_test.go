@@ -0,0 +1,72 @@
+package controllers
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+// fieldErrorsOf decodes the fields of a 422 response
+func fieldErrorsOf(t *testing.T, w *httptest.ResponseRecorder) []FieldError {
+    t.Helper()
+    if w.Code != http.StatusUnprocessableEntity {
+        t.Fatalf("status = %d, want 422: %s", w.Code, w.Body)
+    }
+    var body errorBody
+    if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+        t.Fatalf("body %s: %v", w.Body, err)
+    }
+    return body.Error.Fields
+}
+
+func TestValidationReportsEveryProblem(t *testing.T) {
+    pt := &projectTable{}
+    id := pt.add("draft", "")
+    tc := newStubController(t, &stubConnector{answer: pt.answer}, 1)
+    // Too long and with a control character (escaped, as raw control characters are
+    // invalid JSON)
+    tooLongWithControl := strings.Repeat("x", MaxNameLength) + `\u0007`
+    
+    t.Run("create", func(t *testing.T) {
+        w := httptest.NewRecorder()
+        req := httptest.NewRequest(http.MethodPost, "/api/test", strings.NewReader(`{"Name":"`+tooLongWithControl+`"}`))
+        req.Header.Set("Content-Type", "application/json")
+        tc.Create(w, req)
+        
+        fields := fieldErrorsOf(t, w)
+        if len(fields) != 2 {
+            t.Fatalf("fields = %+v, want the length and the control character", fields)
+        }
+        if !strings.Contains(fields[0].Message, "at most") || !strings.Contains(fields[1].Message, "control characters") {
+            t.Errorf("fields = %+v", fields)
+        }
+    })
+    
+    t.Run("rename", func(t *testing.T) {
+        w := rename(tc, "", `{"from":"","to":"`+tooLongWithControl+`"}`)
+        got := map[string]int{}
+        for _, field := range fieldErrorsOf(t, w) {
+            got[field.Field]++
+        }
+        if got["from"] != 1 || got["to"] != 2 {
+            t.Errorf("problems per field = %v, want from 1 and to 2", got)
+        }
+    })
+    
+    t.Run("patch", func(t *testing.T) {
+        w := patchProject(tc, id, mergePatchMediaType, `{"Name":"","Id":999,"Color":"red"}`)
+        got := map[string]bool{}
+        for _, field := range fieldErrorsOf(t, w) {
+            got[field.Field] = true
+        }
+        if len(got) != 3 || !got["Color"] {
+            t.Errorf("fields with problems = %v, want Name, Id and Color", got)
+        }
+    })
+    
+    if pt.rows[0].name != "draft" || len(pt.rows) != 1 {
+        t.Errorf("invalid requests changed the table: %+v", pt.rows)
+    }
+}
@@ -0,0 +1,99 @@
+// Package dberr classifies database errors (lib/pq and database/sql) so callers
+// can map them to responses without matching on error strings.
+package dberr
+
+import (
+    "context"
+    "database/sql"
+    "database/sql/driver"
+    "errors"
+    "io"
+    "net"
+
+    "github.com/lib/pq"
+)
+
+// Postgres SQLSTATE codes used below (https://www.postgresql.org/docs/current/errcodes-appendix.html)
+const (
+    codeUniqueViolation      = "23505"
+    codeForeignKeyViolation  = "23503"
+    codeSerializationFailure = "40001"
+    codeDeadlockDetected     = "40P01"
+    codeLockNotAvailable     = "55P03"
+    codeQueryCanceled        = "57014"
+    codeAdminShutdown        = "57P01"
+    codeCrashShutdown        = "57P02"
+    codeCannotConnectNow     = "57P03"
+
+    // classConnectionException covers every 08xxx code
+    classConnectionException = "08"
+)
+
+// Code returns the SQLSTATE of a Postgres error, or "" if err isn't one
+func Code(err error) string {
+    var pqErr *pq.Error
+    if errors.As(err, &pqErr) {
+        return string(pqErr.Code)
+    }
+    return ""
+}
+
+// IsNotFound reports whether a query returned no rows
+func IsNotFound(err error) bool {
+    return errors.Is(err, sql.ErrNoRows)
+}
+
+// IsUniqueViolation reports whether err is a unique constraint violation
+func IsUniqueViolation(err error) bool {
+    return Code(err) == codeUniqueViolation
+}
+
+// IsForeignKeyViolation reports whether err is a foreign key violation, e.g. deleting
+// a row other rows still reference
+func IsForeignKeyViolation(err error) bool {
+    return Code(err) == codeForeignKeyViolation
+}
+
+// IsQueryCanceled reports a statement cancelled by the server, typically because it
+// ran past statement_timeout
+func IsQueryCanceled(err error) bool {
+    return Code(err) == codeQueryCanceled
+}
+
+// IsConnectionError reports whether err means the connection to the database failed
+// or was lost, rather than the statement itself being rejected
+func IsConnectionError(err error) bool {
+    // context.DeadlineExceeded satisfies net.Error; the caller's deadline isn't the
+    // connection failing
+    if err == nil || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+        return false
+    }
+    if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+        errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+        return true
+    }
+    var netErr net.Error
+    if errors.As(err, &netErr) {
+        return true
+    }
+    
+    code := Code(err)
+    if len(code) == 5 && code[:2] == classConnectionException {
+        return true
+    }
+    switch code {
+    case codeAdminShutdown, codeCrashShutdown, codeCannotConnectNow:
+        return true
+    }
+    return false
+}
+
+// IsRetryable reports whether the same operation may succeed if simply retried:
+// serialization failures, deadlocks, lock timeouts and connection errors
+func IsRetryable(err error) bool {
+    switch Code(err) {
+    case codeSerializationFailure, codeDeadlockDetected, codeLockNotAvailable:
+        return true
+    }
+    return IsConnectionError(err)
+}
@@ -0,0 +1,123 @@
+package dberr
+
+import (
+    "context"
+    "database/sql"
+    "database/sql/driver"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "testing"
+
+    "github.com/lib/pq"
+)
+
+// pqErr returns a Postgres error with the given SQLSTATE
+func pqErr(code string) error {
+    return &pq.Error{Code: pq.ErrorCode(code)}
+}
+
+// wrapped wraps err the way callers do before it reaches the classifiers
+func wrapped(err error) error {
+    return fmt.Errorf("insert project: %w", err)
+}
+
+func TestCode(t *testing.T) {
+    tests := []struct {
+        name string
+        err  error
+        want string
+    }{
+        {"pq error", pqErr("23505"), "23505"},
+        {"wrapped pq error", wrapped(pqErr("40001")), "40001"},
+        {"other error", errors.New("boom"), ""},
+        {"nil", nil, ""},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := Code(tt.err); got != tt.want {
+                t.Errorf("Code = %q, want %q", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestConstraintClassifiers(t *testing.T) {
+    if !IsUniqueViolation(wrapped(pqErr("23505"))) {
+        t.Error("IsUniqueViolation(23505) = false")
+    }
+    if IsUniqueViolation(pqErr("23503")) {
+        t.Error("IsUniqueViolation(23503) = true")
+    }
+    if !IsForeignKeyViolation(wrapped(pqErr("23503"))) {
+        t.Error("IsForeignKeyViolation(23503) = false")
+    }
+    if !IsQueryCanceled(pqErr("57014")) {
+        t.Error("IsQueryCanceled(57014) = false")
+    }
+    if !IsNotFound(wrapped(sql.ErrNoRows)) {
+        t.Error("IsNotFound(wrapped ErrNoRows) = false")
+    }
+}
+
+func TestIsConnectionError(t *testing.T) {
+    tests := []struct {
+        name string
+        err  error
+        want bool
+    }{
+        {"nil", nil, false},
+        {"bad conn", driver.ErrBadConn, true},
+        {"wrapped conn done", wrapped(sql.ErrConnDone), true},
+        {"eof", io.EOF, true},
+        {"unexpected eof", wrapped(io.ErrUnexpectedEOF), true},
+        {"net error", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true},
+        {"wrapped net error", wrapped(&net.DNSError{Err: "no such host", Name: "db"}), true},
+        {"connection exception", pqErr("08006"), true},
+        {"wrapped connection exception", wrapped(pqErr("08001")), true},
+        {"connection exception class only", pqErr("08"), false},
+        {"admin shutdown", pqErr("57P01"), true},
+        {"crash shutdown", pqErr("57P02"), true},
+        {"cannot connect now", pqErr("57P03"), true},
+        {"query canceled", pqErr("57014"), false},
+        {"unique violation", pqErr("23505"), false},
+        {"no rows", sql.ErrNoRows, false},
+        {"context deadline", context.DeadlineExceeded, false},
+        {"wrapped context canceled", wrapped(context.Canceled), false},
+        {"plain error", errors.New("boom"), false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := IsConnectionError(tt.err); got != tt.want {
+                t.Errorf("IsConnectionError = %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestIsRetryable(t *testing.T) {
+    tests := []struct {
+        name string
+        err  error
+        want bool
+    }{
+        {"nil", nil, false},
+        {"serialization failure", pqErr("40001"), true},
+        {"wrapped deadlock", wrapped(pqErr("40P01")), true},
+        {"lock not available", pqErr("55P03"), true},
+        {"connection exception", pqErr("08003"), true},
+        {"bad conn", wrapped(driver.ErrBadConn), true},
+        {"unique violation", pqErr("23505"), false},
+        {"query canceled", pqErr("57014"), false},
+        {"context deadline", wrapped(context.DeadlineExceeded), false},
+        {"plain error", errors.New("boom"), false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := IsRetryable(tt.err); got != tt.want {
+                t.Errorf("IsRetryable = %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
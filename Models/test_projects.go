@@ -1,6 +1,88 @@
 package models
 
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// IdsAsStrings makes ids marshal as JSON strings ("123") instead of numbers, for
+// JavaScript clients that would lose precision on ids above 2^53 (ID_AS_STRING).
+// Set once at startup.
+var IdsAsStrings bool
+
+// CamelCaseJSON makes projects marshal with camelCase keys ("id", "name") instead of
+// the Go field names ("Id", "Name") (JSON_FIELD_CASE=camel). Decoding accepts either,
+// since encoding/json matches keys case-insensitively. Set once at startup.
+var CamelCaseJSON bool
+
+// JSONFieldName returns the key a field is emitted under in JSON responses
+func JSONFieldName(field string) string {
+    if CamelCaseJSON && field != "" {
+        return strings.ToLower(field[:1]) + field[1:]
+    }
+    return field
+}
+
+// ID is a project id (a Postgres bigint)
+type ID int64
+
+// maxIDLength is the longest string ParseID will look at: a signed 64-bit integer
+// has at most 19 digits plus a sign
+const maxIDLength = 20
+
+// ParseID parses a decimal id. Overlong input is rejected without being scanned.
+func ParseID(s string) (ID, error) {
+    if len(s) > maxIDLength {
+        return 0, &strconv.NumError{Func: "ParseInt", Num: s[:maxIDLength] + "...", Err: strconv.ErrRange}
+    }
+    id, err := strconv.ParseInt(s, 10, 64)
+    return ID(id), err
+}
+
+func (id ID) String() string {
+    return strconv.FormatInt(int64(id), 10)
+}
+
+func (id ID) MarshalJSON() ([]byte, error) {
+    if IdsAsStrings {
+        return []byte(strconv.Quote(id.String())), nil
+    }
+    return []byte(id.String()), nil
+}
+
+// UnmarshalJSON accepts both forms regardless of IdsAsStrings
+func (id *ID) UnmarshalJSON(data []byte) error {
+    if bytes.Equal(data, []byte("null")) {
+        return nil
+    }
+    raw := string(data)
+    if unquoted, err := strconv.Unquote(raw); err == nil {
+        raw = unquoted
+    }
+    parsed, err := ParseID(raw)
+    if err != nil {
+        return fmt.Errorf("invalid id %s", data)
+    }
+    *id = parsed
+    return nil
+}
+
 type TestProjects struct {
-    Id   int    `json:"Id" db:"Id"`
-    Name string `json:"Name" db:"Name"`
+    Id   ID     `json:"Id" xml:"Id" db:"Id"`
+    Name string `json:"Name" xml:"Name" db:"Name"`
+}
+
+// MarshalJSON applies CamelCaseJSON; with the default casing it is the plain struct encoding
+func (p TestProjects) MarshalJSON() ([]byte, error) {
+    if !CamelCaseJSON {
+        type plain TestProjects
+        return json.Marshal(plain(p))
+    }
+    return json.Marshal(struct {
+        Id   ID     `json:"id"`
+        Name string `json:"name"`
+    }{p.Id, p.Name})
 }
@@ -0,0 +1,118 @@
+package models
+
+import (
+    "encoding/json"
+    "math"
+    "strconv"
+    "strings"
+    "testing"
+)
+
+// largeIDs are ids past the int32 range and past the 2^53 a float64 (a JavaScript
+// number) holds exactly
+var largeIDs = []int64{
+    1<<31 + 1,
+    1<<53 + 1,
+    math.MaxInt64,
+}
+
+func TestParseIDLargeIds(t *testing.T) {
+    for _, want := range largeIDs {
+        got, err := ParseID(strconv.FormatInt(want, 10))
+        if err != nil || int64(got) != want {
+            t.Errorf("ParseID(%d) = %d, %v", want, got, err)
+        }
+    }
+}
+
+func TestParseIDRejects(t *testing.T) {
+    for _, raw := range []string{
+        "",
+        "abc",
+        "1.5",
+        "9223372036854775808", // MaxInt64 + 1
+        strings.Repeat("1", maxIDLength+1),
+    } {
+        if _, err := ParseID(raw); err == nil {
+            t.Errorf("ParseID(%q) succeeded", raw)
+        }
+    }
+}
+
+func TestIDJSONRoundTripsLargeIds(t *testing.T) {
+    defer func(old bool) { IdsAsStrings = old }(IdsAsStrings)
+    
+    for _, asStrings := range []bool{false, true} {
+        IdsAsStrings = asStrings
+        for _, want := range largeIDs {
+            data, err := json.Marshal(TestProjects{Id: ID(want), Name: "big"})
+            if err != nil {
+                t.Fatalf("Marshal(%d): %v", want, err)
+            }
+            
+            digits := strconv.FormatInt(want, 10)
+            encoded := `"Id":` + digits
+            if asStrings {
+                encoded = `"Id":"` + digits + `"`
+            }
+            if !strings.Contains(string(data), encoded) {
+                t.Errorf("IdsAsStrings=%v: %s does not contain %s", asStrings, data, encoded)
+            }
+            
+            var got TestProjects
+            if err := json.Unmarshal(data, &got); err != nil {
+                t.Fatalf("Unmarshal(%s): %v", data, err)
+            }
+            if int64(got.Id) != want {
+                t.Errorf("IdsAsStrings=%v: %d round-tripped to %d", asStrings, want, got.Id)
+            }
+        }
+    }
+}
+
+func TestIDUnmarshalAcceptsBothForms(t *testing.T) {
+    for _, data := range []string{`9007199254740993`, `"9007199254740993"`} {
+        var id ID
+        if err := json.Unmarshal([]byte(data), &id); err != nil || id != 1<<53+1 {
+            t.Errorf("Unmarshal(%s) = %d, %v", data, id, err)
+        }
+    }
+    
+    var id ID = 7
+    if err := json.Unmarshal([]byte("null"), &id); err != nil || id != 7 {
+        t.Errorf("Unmarshal(null) = %d, %v; want the id left alone", id, err)
+    }
+    for _, data := range []string{`"abc"`, `1.5`, `true`} {
+        if err := json.Unmarshal([]byte(data), &id); err == nil {
+            t.Errorf("Unmarshal(%s) succeeded", data)
+        }
+    }
+}
+
+func TestCamelCaseJSONKeys(t *testing.T) {
+    defer func(old bool) { CamelCaseJSON = old }(CamelCaseJSON)
+    project := TestProjects{Id: 42, Name: "roadmap"}
+    
+    CamelCaseJSON = true
+    data, err := json.Marshal(project)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if string(data) != `{"id":42,"name":"roadmap"}` {
+        t.Errorf("camelCase JSON = %s", data)
+    }
+    
+    // Decoding accepts either casing
+    var decoded TestProjects
+    if err := json.Unmarshal(data, &decoded); err != nil || decoded != project {
+        t.Errorf("Unmarshal(%s) = %+v, %v", data, decoded, err)
+    }
+    
+    CamelCaseJSON = false
+    if data, _ := json.Marshal(project); string(data) != `{"Id":42,"Name":"roadmap"}` {
+        t.Errorf("default JSON = %s", data)
+    }
+    if JSONFieldName("Name") != "Name" {
+        t.Errorf("JSONFieldName(Name) = %q with the default casing", JSONFieldName("Name"))
+    }
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+    "fmt"
+    "net/http"
+    "reflect"
+    "sort"
+    "strings"
+    "time"
+
+    "backend/Controllers"
+)
+
+// redactedValue replaces secret settings in /admin/config; unset secrets stay empty so
+// the output still shows whether they are configured
+const redactedValue = "***"
+
+// exportConfig returns the effective configuration keyed by environment variable, for
+// GET /admin/config. It is built from Config's env tags, so a new setting shows up
+// without being listed here. Settings tagged secret are replaced by redactedValue;
+// those tagged url keep everything but their password and the values of the
+// LOG_REDACT_PARAMS query parameters.
+func exportConfig(cfg Config) map[string]interface{} {
+    out := make(map[string]interface{})
+    exportFields(reflect.ValueOf(cfg), cfg.LogRedactParams, out)
+    return out
+}
+
+// exportFields adds the env-tagged fields of the struct v to out, descending into
+// untagged struct fields such as StackTrace
+func exportFields(v reflect.Value, redactParams map[string]bool, out map[string]interface{}) {
+    t := v.Type()
+    for i := 0; i < t.NumField(); i++ {
+        field := v.Field(i)
+        tag, ok := t.Field(i).Tag.Lookup("env")
+        if !ok {
+            if field.Kind() == reflect.Struct {
+                exportFields(field, redactParams, out)
+            }
+            continue
+        }
+        name, option, _ := strings.Cut(tag, ",")
+        out[name] = exportValue(name, option, field, redactParams)
+    }
+}
+
+// durationType is the type of the duration settings
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// exportValue renders one setting. Durations are given in the unit their variable
+// is set in (_MS, _SECONDS, otherwise Go duration syntax such as "5s").
+func exportValue(name, option string, v reflect.Value, redactParams map[string]bool) interface{} {
+    switch option {
+    case "secret":
+        if v.String() == "" {
+            return ""
+        }
+        return redactedValue
+    case "url":
+        return redactURL(v.String(), redactParams)
+    }
+    
+    if v.Type() == durationType {
+        d := time.Duration(v.Int())
+        switch {
+        case strings.HasSuffix(name, "_MS"):
+            return d.Milliseconds()
+        case strings.HasSuffix(name, "_SECONDS"):
+            return int(d.Seconds())
+        }
+        return d.String()
+    }
+    switch v.Kind() {
+    case reflect.Bool:
+        return v.Bool()
+    case reflect.Int, reflect.Int64:
+        return v.Int()
+    case reflect.Float64:
+        return v.Float()
+    case reflect.String:
+        return v.String()
+    case reflect.Map:
+        set := make(map[string]bool, v.Len())
+        for _, key := range v.MapKeys() {
+            set[key.String()] = true
+        }
+        return sortedKeys(set)
+    case reflect.Slice:
+        items := make([]string, 0, v.Len())
+        for i := 0; i < v.Len(); i++ {
+            items = append(items, fmt.Sprint(v.Index(i).Interface()))
+        }
+        return items
+    }
+    return fmt.Sprint(v.Interface())
+}
+
+// sortedKeys lists the members of a set in a stable order
+func sortedKeys(set map[string]bool) []string {
+    keys := make([]string, 0, len(set))
+    for key := range set {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+// configHandler serves GET /admin/config (admin-only): the effective configuration
+// with secrets redacted, for checking a running instance without a shell
+func configHandler(cfg Config) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if r.Method != "GET" {
+            http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+            return
+        }
+        if !requireAdmin(w, r) {
+            return
+        }
+        controllers.WriteJSON(w, http.StatusOK, exportConfig(cfg))
+    }
+}
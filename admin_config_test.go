@@ -0,0 +1,102 @@
+package main
+
+import (
+    "net"
+    "reflect"
+    "testing"
+    "time"
+)
+
+func TestExportConfigRedactsSecretsAndURLs(t *testing.T) {
+    cfg := Config{
+        DatabaseURL:             "postgres://app:s3cret@db:5432/main?sslmode=require&password=again",
+        DatabaseReplicaURL:      "host=replica user=app password=s3cret",
+        RuntimeErrorEndpointURL: "https://hooks.example.com/report?token=abc123&channel=ops",
+        AdminToken:              "admin-token",
+        LogRedactParams:         map[string]bool{"token": true, "password": true},
+    }
+    got := exportConfig(cfg)
+    
+    want := map[string]interface{}{
+        "DATABASE_URL":               "postgres://app:***@db:5432/main?sslmode=require&password=***",
+        "DATABASE_REPLICA_URL":       "host=replica user=app password=***",
+        "RUNTIME_ERROR_ENDPOINT_URL": "https://hooks.example.com/report?token=***&channel=ops",
+        "ADMIN_TOKEN":                redactedValue,
+    }
+    for key, value := range want {
+        if got[key] != value {
+            t.Errorf("%s = %v, want %v", key, got[key], value)
+        }
+    }
+    
+    if got := exportConfig(Config{})["ADMIN_TOKEN"]; got != "" {
+        t.Errorf("unset ADMIN_TOKEN = %v, want empty", got)
+    }
+}
+
+func TestExportConfigValues(t *testing.T) {
+    _, proxy, _ := net.ParseCIDR("10.0.0.0/8")
+    cfg := Config{
+        DBAcquireTimeout:      1500 * time.Millisecond,
+        DBStartupPingTimeout:  10 * time.Second,
+        ErrorReportTimeout:    5 * time.Second,
+        Port:                  8080,
+        ForceHTTPS:            true,
+        PanicReportSampleRate: 0.5,
+        TrustedProxies:        []*net.IPNet{proxy},
+        ReadyCriticalChecks:   map[string]bool{"replica_db": true, "primary_db": true},
+        StackTrace:            stackLimits{initial: 8192, max: 1 << 20, report: 64 << 10},
+    }
+    got := exportConfig(cfg)
+    
+    want := map[string]interface{}{
+        "DB_ACQUIRE_TIMEOUT_MS":           int64(1500),
+        "DB_STARTUP_PING_TIMEOUT_SECONDS": 10,
+        "ERROR_REPORT_TIMEOUT":            "5s",
+        "PORT":                            int64(8080),
+        "FORCE_HTTPS":                     true,
+        "PANIC_REPORT_SAMPLE_RATE":        0.5,
+        "TRUSTED_PROXIES":                 []string{"10.0.0.0/8"},
+        "READY_CRITICAL_CHECKS":           []string{"primary_db", "replica_db"},
+        "STACK_TRACE_BUFFER_BYTES":        int64(8192),
+        "STACK_TRACE_REPORT_BYTES":        int64(64 << 10),
+    }
+    for key, value := range want {
+        if !reflect.DeepEqual(got[key], value) {
+            t.Errorf("%s = %#v, want %#v", key, got[key], value)
+        }
+    }
+}
+
+// TestConfigFieldsAreTagged keeps /admin/config complete: a setting without an env
+// tag would silently be missing from it
+func TestConfigFieldsAreTagged(t *testing.T) {
+    var check func(typ reflect.Type)
+    check = func(typ reflect.Type) {
+        for i := 0; i < typ.NumField(); i++ {
+            field := typ.Field(i)
+            if _, ok := field.Tag.Lookup("env"); ok {
+                continue
+            }
+            if field.Type.Kind() == reflect.Struct {
+                check(field.Type)
+                continue
+            }
+            t.Errorf("%s.%s has no env tag", typ.Name(), field.Name)
+        }
+    }
+    check(reflect.TypeOf(Config{}))
+}
+
+func TestExportConfigListsEverySetting(t *testing.T) {
+    got := exportConfig(Config{})
+    for _, key := range []string{
+        "DATABASE_URL", "DATABASE_REPLICA_URL", "RUNTIME_ERROR_ENDPOINT_URL", "ADMIN_TOKEN",
+        "BOARD_ID", "FEATURE_FLAGS", "EXPOSE_PANIC_DETAILS", "PORT", "LOG_FORMAT", "LISTEN_ADDR",
+        "STACK_TRACE_BUFFER_BYTES", "STACK_TRACE_MAX_BYTES", "RETRY_AFTER_MAX_SECONDS", "HTTP_IDLE_TIMEOUT",
+    } {
+        if _, ok := got[key]; !ok {
+            t.Errorf("%s missing from the export", key)
+        }
+    }
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestIsValidHex(t *testing.T) {
+    tests := []struct {
+        in   string
+        want bool
+    }{
+        {"0123456789abcdef", true},
+        {"ABCDEF", true},
+        {"aBc1", true},
+        {"abc", true},
+        {"", false},
+        {"abcg", false},
+        {"12 34", false},
+        {"0x12", false},
+        {"é1", false},
+    }
+    for _, tt := range tests {
+        if got := isValidHex(tt.in); got != tt.want {
+            t.Errorf("isValidHex(%q) = %v, want %v", tt.in, got, tt.want)
+        }
+    }
+}
+
+func TestIsValidBoardId(t *testing.T) {
+    tests := []struct {
+        in   string
+        want bool
+    }{
+        {"0123456789abcdef01234567", true},
+        {"0123456789ABCDEF01234567", true},
+        {"0123456789abcdef0123456", false},
+        {"0123456789abcdef012345678", false},
+        {"0123456789abcdef0123456z", false},
+        {"", false},
+    }
+    for _, tt := range tests {
+        if got := isValidBoardId(tt.in); got != tt.want {
+            t.Errorf("isValidBoardId(%q) = %v, want %v", tt.in, got, tt.want)
+        }
+    }
+}
+
+func TestExtractBoardIdFromHeader(t *testing.T) {
+    t.Setenv("BOARD_ID", "")
+    const valid = "0123456789abcdef01234567"
+    tests := []struct {
+        name   string
+        header string
+        query  string
+        want   string
+    }{
+        {"valid header", valid, "", valid},
+        {"malformed header", "not-a-board'; DROP TABLE", "", ""},
+        {"too short header", valid[:23], "", ""},
+        {"empty header", "", "", ""},
+        {"malformed query falls back to header", valid, "?boardId=zzz", valid},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            req := httptest.NewRequest(http.MethodGet, "/api/test"+tt.query, nil)
+            req.Host = "api.example.com"
+            if tt.header != "" {
+                req.Header.Set("X-Board-Id", tt.header)
+            }
+            if got := extractBoardId(req); got != tt.want {
+                t.Errorf("extractBoardId = %q, want %q", got, tt.want)
+            }
+        })
+    }
+}
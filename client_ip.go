@@ -0,0 +1,92 @@
+package main
+
+import (
+    "context"
+    "log"
+    "net"
+    "net/http"
+    "strings"
+)
+
+type clientIPKey struct{}
+
+// parseTrustedProxies parses a comma-separated list of CIDRs (or bare IPs) such as
+// "10.0.0.0/8,192.168.1.10"; invalid entries are logged and skipped
+func parseTrustedProxies(raw string) []*net.IPNet {
+    var nets []*net.IPNet
+    for _, entry := range strings.Split(raw, ",") {
+        entry = strings.TrimSpace(entry)
+        if entry == "" {
+            continue
+        }
+        if !strings.Contains(entry, "/") {
+            if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+                entry += "/32"
+            } else {
+                entry += "/128"
+            }
+        }
+        _, ipNet, err := net.ParseCIDR(entry)
+        if err != nil {
+            log.Printf("[CONFIG] Ignoring invalid TRUSTED_PROXIES entry %q: %v", entry, err)
+            continue
+        }
+        nets = append(nets, ipNet)
+    }
+    return nets
+}
+
+func isTrustedProxy(ip net.IP, trusted []*net.IPNet) bool {
+    for _, ipNet := range trusted {
+        if ipNet.Contains(ip) {
+            return true
+        }
+    }
+    return false
+}
+
+// resolveClientIP returns the real client IP. X-Forwarded-For is only honored when
+// the immediate peer is a trusted proxy; it is then walked right-to-left, skipping
+// trusted proxies, so a client can't spoof its address by prepending entries.
+func resolveClientIP(r *http.Request, trusted []*net.IPNet) string {
+    peer, _, err := net.SplitHostPort(r.RemoteAddr)
+    if err != nil {
+        peer = r.RemoteAddr
+    }
+    
+    peerIP := net.ParseIP(peer)
+    if peerIP == nil || !isTrustedProxy(peerIP, trusted) {
+        return peer
+    }
+    
+    hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+    for i := len(hops) - 1; i >= 0; i-- {
+        hop := net.ParseIP(strings.TrimSpace(hops[i]))
+        if hop == nil {
+            // Malformed entry - stop trusting the chain here
+            break
+        }
+        if !isTrustedProxy(hop, trusted) {
+            return hop.String()
+        }
+    }
+    return peer
+}
+
+// clientIPMiddleware resolves the client IP once per request and stores it in the
+// context for logging and other middleware (see clientIP)
+func clientIPMiddleware(next http.Handler, trusted []*net.IPNet) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        ip := resolveClientIP(r, trusted)
+        next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip)))
+    })
+}
+
+// clientIP returns the IP resolved by clientIPMiddleware, or the peer address
+// when the middleware hasn't run
+func clientIP(r *http.Request) string {
+    if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+        return ip
+    }
+    return resolveClientIP(r, nil)
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+    "fmt"
+    "log"
+    "math"
+    "net"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+
+    "backend/Controllers"
+)
+
+// Config holds the tunable settings read from the environment at startup. Every
+// setting carries its variable in an env tag, which /admin/config is built from; the
+// tag option secret hides the value there and url masks its credentials.
+type Config struct {
+    // DBMaxIdle is the number of idle connections kept in the pool (DB_MAX_IDLE)
+    DBMaxIdle int `env:"DB_MAX_IDLE"`
+    // DBMaxOpen caps open connections per pool; 0 means unlimited (DB_MAX_OPEN)
+    DBMaxOpen int `env:"DB_MAX_OPEN"`
+    // DBStatementTimeout is the Postgres statement_timeout set on every connection; 0 leaves the server default (DB_STATEMENT_TIMEOUT_MS)
+    DBStatementTimeout time.Duration `env:"DB_STATEMENT_TIMEOUT_MS"`
+    // DBStartupPingTimeout bounds the database ping at startup; 0 waits indefinitely (DB_STARTUP_PING_TIMEOUT_SECONDS)
+    DBStartupPingTimeout time.Duration `env:"DB_STARTUP_PING_TIMEOUT_SECONDS"`
+    // DBAcquireTimeout bounds each handler's database calls, the wait for a pool connection included, before answering 503; 0 waits indefinitely (DB_ACQUIRE_TIMEOUT_MS)
+    DBAcquireTimeout time.Duration `env:"DB_ACQUIRE_TIMEOUT_MS"`
+    // PoolUtilizationThreshold is the InUse/MaxOpen ratio at which /ready reports the pool as degraded (POOL_UTILIZATION_THRESHOLD)
+    PoolUtilizationThreshold float64 `env:"POOL_UTILIZATION_THRESHOLD"`
+    // PoolWaitThreshold is the number of new connection waits between /ready checks that marks the pool as degraded (POOL_WAIT_THRESHOLD)
+    PoolWaitThreshold int `env:"POOL_WAIT_THRESHOLD"`
+    // WarmupPool opens and pings DBMaxIdle connections before serving traffic (WARMUP_POOL)
+    WarmupPool bool `env:"WARMUP_POOL"`
+    // AuditLog writes a structured audit entry for every Create/Update/Delete (AUDIT_LOG)
+    AuditLog bool `env:"AUDIT_LOG"`
+    // DefaultPageSize is the page size for list endpoints when no limit is given (DEFAULT_PAGE_SIZE)
+    DefaultPageSize int `env:"DEFAULT_PAGE_SIZE"`
+    // MaxPageSize caps the limit a client can request (MAX_PAGE_SIZE)
+    MaxPageSize int `env:"MAX_PAGE_SIZE"`
+    // MaxFields caps the number of entries in a fields query parameter (MAX_FIELDS)
+    MaxFields int `env:"MAX_FIELDS"`
+    // ForceHTTPS redirects plain-HTTP requests to https and sends HSTS (FORCE_HTTPS)
+    ForceHTTPS bool `env:"FORCE_HTTPS"`
+    // HSTSMaxAgeSeconds is the Strict-Transport-Security max-age with ForceHTTPS; 0 omits the header (HSTS_MAX_AGE_SECONDS)
+    HSTSMaxAgeSeconds int `env:"HSTS_MAX_AGE_SECONDS"`
+    // MaxPathLength is the longest request path accepted before answering 414; 0 disables the check (MAX_PATH_LENGTH)
+    MaxPathLength int `env:"MAX_PATH_LENGTH"`
+    // GzipMinBytes is the smallest response body that is gzip-compressed; negative disables compression (GZIP_MIN_BYTES)
+    GzipMinBytes int `env:"GZIP_MIN_BYTES"`
+    // BoardRateLimitRPS is the sustained /api/ request rate allowed per board; 0 disables rate limiting (BOARD_RATE_LIMIT_RPS)
+    BoardRateLimitRPS float64 `env:"BOARD_RATE_LIMIT_RPS"`
+    // BoardRateLimitBurst is how many requests a board may make at once above that rate (BOARD_RATE_LIMIT_BURST)
+    BoardRateLimitBurst int `env:"BOARD_RATE_LIMIT_BURST"`
+    // IPRateLimitRPS is the sustained /api/ request rate allowed per client IP, whatever the board; 0 uses BoardRateLimitRPS (IP_RATE_LIMIT_RPS)
+    IPRateLimitRPS float64 `env:"IP_RATE_LIMIT_RPS"`
+    // IPRateLimitBurst is how many requests a client IP may make at once above that rate; 0 uses BoardRateLimitBurst (IP_RATE_LIMIT_BURST)
+    IPRateLimitBurst int `env:"IP_RATE_LIMIT_BURST"`
+    // MaxResponseTime caps how long an /api/ request may run before it is answered with 503; 0 disables it (MAX_RESPONSE_MS)
+    MaxResponseTime time.Duration `env:"MAX_RESPONSE_MS"`
+    // MaxInflightRequests bounds concurrently handled requests; 0 disables the limit (MAX_INFLIGHT_REQUESTS)
+    MaxInflightRequests int `env:"MAX_INFLIGHT_REQUESTS"`
+    // CORSMaxAgeSeconds is how long browsers may cache a preflight response (CORS_MAX_AGE_SECONDS)
+    CORSMaxAgeSeconds int `env:"CORS_MAX_AGE_SECONDS"`
+    // PanicDbLog also records recovered panics in the panic_log table (PANIC_DB_LOG)
+    PanicDbLog bool `env:"PANIC_DB_LOG"`
+    // ExposePanicDetails returns the raw panic message to clients instead of a generic one; dev only (EXPOSE_PANIC_DETAILS)
+    ExposePanicDetails bool `env:"EXPOSE_PANIC_DETAILS"`
+    // RecentPanicsSize is how many recovered panics are kept in memory for /admin/errors/recent; 0 disables it (RECENT_PANICS_SIZE)
+    RecentPanicsSize int `env:"RECENT_PANICS_SIZE"`
+    // PanicReportSampleRate is the fraction (0.0-1.0) of panics reported to the error endpoint (PANIC_REPORT_SAMPLE_RATE)
+    PanicReportSampleRate float64 `env:"PANIC_REPORT_SAMPLE_RATE"`
+    // PanicReportBodyBytes is how much of the request body panic reports include, redacted; 0 disables it (PANIC_REPORT_BODY_BYTES)
+    PanicReportBodyBytes int `env:"PANIC_REPORT_BODY_BYTES"`
+    // StackTrace sizes the stack traces in panic reports (STACK_TRACE_BUFFER_BYTES, STACK_TRACE_MAX_BYTES, STACK_TRACE_REPORT_BYTES)
+    StackTrace stackLimits
+    // ErrorReportTimeout bounds each error report sent to the endpoint (ERROR_REPORT_TIMEOUT)
+    ErrorReportTimeout time.Duration `env:"ERROR_REPORT_TIMEOUT"`
+    // ErrorReportMaxIdleConns is the number of idle keep-alive connections kept to the endpoint (ERROR_REPORT_MAX_IDLE_CONNS)
+    ErrorReportMaxIdleConns int `env:"ERROR_REPORT_MAX_IDLE_CONNS"`
+    // ErrorReportWorkers caps the error reports being sent, or written to panic_log, at once (ERROR_REPORT_WORKERS)
+    ErrorReportWorkers int `env:"ERROR_REPORT_WORKERS"`
+    // TrustedProxies are the proxies whose X-Forwarded-For is believed (TRUSTED_PROXIES, CIDR list)
+    TrustedProxies []*net.IPNet `env:"TRUSTED_PROXIES"`
+    // LenientRequestBody accepts (and ignores) bodies on GET/DELETE /api/test requests (LENIENT_REQUEST_BODY)
+    LenientRequestBody bool `env:"LENIENT_REQUEST_BODY"`
+    // ProblemDetails sends every error as RFC 7807 application/problem+json, not only to clients that ask for it (PROBLEM_DETAILS)
+    ProblemDetails bool `env:"PROBLEM_DETAILS"`
+    // LenientQuery takes the first value of a repeated list query parameter instead of answering 400 (LENIENT_QUERY)
+    LenientQuery bool `env:"LENIENT_QUERY"`
+    // ResponseCharset is the charset parameter on JSON responses; "none" omits it (RESPONSE_CHARSET)
+    ResponseCharset string `env:"RESPONSE_CHARSET"`
+    // LogQuery includes the query string in access log lines (LOG_QUERY)
+    LogQuery bool `env:"LOG_QUERY"`
+    // LogRedactParams are query parameters whose values are masked in access logs (LOG_REDACT_PARAMS)
+    LogRedactParams map[string]bool `env:"LOG_REDACT_PARAMS"`
+    // NameUniquePerBoard makes project names unique per board rather than unconstrained (NAME_UNIQUE_PER_BOARD)
+    NameUniquePerBoard bool `env:"NAME_UNIQUE_PER_BOARD"`
+    // AllowDestructive enables DELETE /api/test/all; never set it in production (ALLOW_DESTRUCTIVE)
+    AllowDestructive bool `env:"ALLOW_DESTRUCTIVE"`
+    // IdAsString serializes ids as JSON strings for clients that can't hold 64-bit integers (ID_AS_STRING)
+    IdAsString bool `env:"ID_AS_STRING"`
+    // JSONFieldCase is the key casing of project JSON: "pascal" (Id, Name) or "camel" (id, name) (JSON_FIELD_CASE)
+    JSONFieldCase string `env:"JSON_FIELD_CASE"`
+    // SwaggerUseCDN loads the /swagger page's assets from unpkg instead of the embedded copy (SWAGGER_USE_CDN)
+    SwaggerUseCDN bool `env:"SWAGGER_USE_CDN"`
+    // BasePath is the sub-path the service is mounted under, e.g. /backend (BASE_PATH)
+    BasePath string `env:"BASE_PATH"`
+    // RetryAfterMin and RetryAfterMax bound the jittered Retry-After seconds on 503s (RETRY_AFTER_MIN_SECONDS, RETRY_AFTER_MAX_SECONDS)
+    RetryAfterMin int `env:"RETRY_AFTER_MIN_SECONDS"`
+    RetryAfterMax int `env:"RETRY_AFTER_MAX_SECONDS"`
+    // ReadyCriticalChecks are the /ready checks whose failure makes the service "down" (READY_CRITICAL_CHECKS)
+    ReadyCriticalChecks map[string]bool `env:"READY_CRITICAL_CHECKS"`
+    
+    // IdleShutdown exits the process gracefully after this long without requests; 0 disables it (IDLE_SHUTDOWN_SECONDS)
+    IdleShutdown time.Duration `env:"IDLE_SHUTDOWN_SECONDS"`
+    // IdleShutdownCountHealth makes health/ready probes count as activity (IDLE_SHUTDOWN_COUNT_HEALTH)
+    IdleShutdownCountHealth bool `env:"IDLE_SHUTDOWN_COUNT_HEALTH"`
+    // ShutdownDrainTimeout bounds how long shutdown waits for in-flight requests (SHUTDOWN_DRAIN_SECONDS)
+    ShutdownDrainTimeout time.Duration `env:"SHUTDOWN_DRAIN_SECONDS"`
+    
+    // Port is the TCP port the server listens on (PORT)
+    Port int `env:"PORT"`
+    // ListenAddr is the interface the server binds to, all of them by default (LISTEN_ADDR)
+    ListenAddr string `env:"LISTEN_ADDR"`
+    // LogFormat is the log format in effect, "text" or "json" (LOG_FORMAT, see setupLogging)
+    LogFormat string `env:"LOG_FORMAT"`
+    
+    // DatabaseURL is the primary database's connection string (DATABASE_URL)
+    DatabaseURL string `env:"DATABASE_URL,url"`
+    // DatabaseReplicaURL is the read replica's connection string; empty sends reads to the primary (DATABASE_REPLICA_URL)
+    DatabaseReplicaURL string `env:"DATABASE_REPLICA_URL,url"`
+    // RuntimeErrorEndpointURL receives panic and startup error reports (RUNTIME_ERROR_ENDPOINT_URL)
+    RuntimeErrorEndpointURL string `env:"RUNTIME_ERROR_ENDPOINT_URL,url"`
+    // AdminToken is the bearer token of the admin endpoints; empty disables them (ADMIN_TOKEN)
+    AdminToken string `env:"ADMIN_TOKEN,secret"`
+    // BoardId is the board of requests that don't name one (BOARD_ID)
+    BoardId string `env:"BOARD_ID"`
+    // FeatureFlags are the initial feature flags, e.g. search=true,foo=false (FEATURE_FLAGS)
+    FeatureFlags string `env:"FEATURE_FLAGS"`
+    
+    // HTTP server timeouts (HTTP_READ_TIMEOUT, HTTP_WRITE_TIMEOUT, HTTP_IDLE_TIMEOUT)
+    HTTPReadTimeout  time.Duration `env:"HTTP_READ_TIMEOUT"`
+    HTTPWriteTimeout time.Duration `env:"HTTP_WRITE_TIMEOUT"`
+    HTTPIdleTimeout  time.Duration `env:"HTTP_IDLE_TIMEOUT"`
+}
+
+func loadConfig() Config {
+    cfg := Config{
+        DBMaxIdle:  getEnvInt("DB_MAX_IDLE", 2),
+        DBMaxOpen:  getEnvInt("DB_MAX_OPEN", 0),
+        
+        DBAcquireTimeout:   time.Duration(getEnvInt("DB_ACQUIRE_TIMEOUT_MS", 0)) * time.Millisecond,
+        DBStatementTimeout: time.Duration(getEnvInt("DB_STATEMENT_TIMEOUT_MS", 0)) * time.Millisecond,
+        
+        DBStartupPingTimeout: time.Duration(getEnvInt("DB_STARTUP_PING_TIMEOUT_SECONDS", 10)) * time.Second,
+        WarmupPool: getEnvBool("WARMUP_POOL", false),
+        AuditLog:   getEnvBool("AUDIT_LOG", false),
+        
+        PoolUtilizationThreshold: getEnvFloat("POOL_UTILIZATION_THRESHOLD", 0.9),
+        PoolWaitThreshold:        getEnvInt("POOL_WAIT_THRESHOLD", 1),
+        
+        DefaultPageSize: getEnvInt("DEFAULT_PAGE_SIZE", controllers.DefaultPageSize),
+        MaxPageSize:     getEnvInt("MAX_PAGE_SIZE", controllers.MaxPageSize),
+        MaxFields:       getEnvInt("MAX_FIELDS", controllers.MaxFields),
+        
+        MaxInflightRequests: getEnvInt("MAX_INFLIGHT_REQUESTS", 100),
+        BoardRateLimitRPS:   getEnvFloat("BOARD_RATE_LIMIT_RPS", 0),
+        BoardRateLimitBurst: getEnvInt("BOARD_RATE_LIMIT_BURST", 20),
+        IPRateLimitRPS:      getEnvFloat("IP_RATE_LIMIT_RPS", 0),
+        IPRateLimitBurst:    getEnvInt("IP_RATE_LIMIT_BURST", 0),
+        MaxResponseTime:     time.Duration(getEnvInt("MAX_RESPONSE_MS", 0)) * time.Millisecond,
+        GzipMinBytes:        getEnvInt("GZIP_MIN_BYTES", 1024),
+        MaxPathLength:       getEnvInt("MAX_PATH_LENGTH", 2048),
+        ForceHTTPS:          getEnvBool("FORCE_HTTPS", false),
+        HSTSMaxAgeSeconds:   getEnvInt("HSTS_MAX_AGE_SECONDS", 31536000),
+        CORSMaxAgeSeconds:   getEnvInt("CORS_MAX_AGE_SECONDS", 600),
+        PanicDbLog:          getEnvBool("PANIC_DB_LOG", false),
+        ExposePanicDetails:  getEnvBool("EXPOSE_PANIC_DETAILS", false),
+        RecentPanicsSize:    getEnvInt("RECENT_PANICS_SIZE", 20),
+        TrustedProxies:      parseTrustedProxies(os.Getenv("TRUSTED_PROXIES")),
+        LenientRequestBody:  getEnvBool("LENIENT_REQUEST_BODY", false),
+        LenientQuery:        getEnvBool("LENIENT_QUERY", false),
+        ProblemDetails:      getEnvBool("PROBLEM_DETAILS", false),
+        ResponseCharset:     getEnvString("RESPONSE_CHARSET", "utf-8"),
+        LogQuery:            getEnvBool("LOG_QUERY", false),
+        LogRedactParams:     parseRedactParams(getEnvString("LOG_REDACT_PARAMS", "token,access_token,password,secret,api_key")),
+        NameUniquePerBoard:  getEnvBool("NAME_UNIQUE_PER_BOARD", false),
+        AllowDestructive:    getEnvBool("ALLOW_DESTRUCTIVE", false),
+        IdAsString:          getEnvBool("ID_AS_STRING", false),
+        JSONFieldCase:       strings.ToLower(getEnvString("JSON_FIELD_CASE", "pascal")),
+        SwaggerUseCDN:       getEnvBool("SWAGGER_USE_CDN", false),
+        BasePath:            normalizeBasePath(os.Getenv("BASE_PATH")),
+        ReadyCriticalChecks: parseCriticalChecks(getEnvString("READY_CRITICAL_CHECKS", "primary_db")),
+        
+        PanicReportSampleRate:   getEnvFloat("PANIC_REPORT_SAMPLE_RATE", 1.0),
+        PanicReportBodyBytes:    getEnvInt("PANIC_REPORT_BODY_BYTES", 0),
+        ErrorReportTimeout:      getEnvDuration("ERROR_REPORT_TIMEOUT", 5*time.Second),
+        ErrorReportMaxIdleConns: getEnvInt("ERROR_REPORT_MAX_IDLE_CONNS", 4),
+        ErrorReportWorkers:      getEnvInt("ERROR_REPORT_WORKERS", 2),
+        StackTrace: stackLimits{
+            initial: getEnvInt("STACK_TRACE_BUFFER_BYTES", 8192),
+            max:     getEnvInt("STACK_TRACE_MAX_BYTES", 1<<20),
+            report:  getEnvInt("STACK_TRACE_REPORT_BYTES", 64<<10),
+        },
+        
+        RetryAfterMin: getEnvInt("RETRY_AFTER_MIN_SECONDS", 1),
+        RetryAfterMax: getEnvInt("RETRY_AFTER_MAX_SECONDS", 5),
+        
+        IdleShutdown:            time.Duration(getEnvInt("IDLE_SHUTDOWN_SECONDS", 0)) * time.Second,
+        IdleShutdownCountHealth: getEnvBool("IDLE_SHUTDOWN_COUNT_HEALTH", false),
+        ShutdownDrainTimeout:    time.Duration(getEnvInt("SHUTDOWN_DRAIN_SECONDS", 15)) * time.Second,
+        
+        HTTPReadTimeout:  getEnvDuration("HTTP_READ_TIMEOUT", 15*time.Second),
+        HTTPWriteTimeout: getEnvDuration("HTTP_WRITE_TIMEOUT", 30*time.Second),
+        HTTPIdleTimeout:  getEnvDuration("HTTP_IDLE_TIMEOUT", 60*time.Second),
+        
+        ListenAddr: getEnvString("LISTEN_ADDR", "0.0.0.0"),
+        LogFormat:  logFormat,
+        
+        DatabaseURL:             os.Getenv("DATABASE_URL"),
+        DatabaseReplicaURL:      os.Getenv("DATABASE_REPLICA_URL"),
+        RuntimeErrorEndpointURL: os.Getenv("RUNTIME_ERROR_ENDPOINT_URL"),
+        AdminToken:              os.Getenv("ADMIN_TOKEN"),
+        BoardId:                 os.Getenv("BOARD_ID"),
+        FeatureFlags:            os.Getenv("FEATURE_FLAGS"),
+    }
+    
+    port, err := parsePort(os.Getenv("PORT"))
+    if err != nil {
+        log.Fatalf("[CONFIG] %v", err)
+    }
+    cfg.Port = port
+    
+    if cfg.MaxPageSize < 1 {
+        log.Printf("[CONFIG] MAX_PAGE_SIZE must be positive, using default %d", controllers.MaxPageSize)
+        cfg.MaxPageSize = controllers.MaxPageSize
+    }
+    if cfg.DefaultPageSize < 1 || cfg.DefaultPageSize > cfg.MaxPageSize {
+        log.Printf("[CONFIG] DEFAULT_PAGE_SIZE must be between 1 and MAX_PAGE_SIZE (%d), clamping", cfg.MaxPageSize)
+        if cfg.DefaultPageSize < 1 {
+            cfg.DefaultPageSize = 1
+        } else {
+            cfg.DefaultPageSize = cfg.MaxPageSize
+        }
+    }
+    if cfg.PanicReportSampleRate < 0 || cfg.PanicReportSampleRate > 1 {
+        log.Printf("[CONFIG] PANIC_REPORT_SAMPLE_RATE must be between 0.0 and 1.0, clamping")
+        cfg.PanicReportSampleRate = math.Max(0, math.Min(1, cfg.PanicReportSampleRate))
+    }
+    if cfg.PanicReportBodyBytes > maxPanicReportBodyBytes {
+        log.Printf("[CONFIG] PANIC_REPORT_BODY_BYTES must be at most %d, clamping", maxPanicReportBodyBytes)
+        cfg.PanicReportBodyBytes = maxPanicReportBodyBytes
+    }
+    if cfg.BoardRateLimitBurst < 1 {
+        log.Printf("[CONFIG] BOARD_RATE_LIMIT_BURST must be at least 1, using 1")
+        cfg.BoardRateLimitBurst = 1
+    }
+    if cfg.IPRateLimitRPS <= 0 {
+        cfg.IPRateLimitRPS = cfg.BoardRateLimitRPS
+    }
+    if cfg.IPRateLimitBurst < 1 {
+        cfg.IPRateLimitBurst = cfg.BoardRateLimitBurst
+    }
+    if cfg.HSTSMaxAgeSeconds < 0 {
+        log.Printf("[CONFIG] HSTS_MAX_AGE_SECONDS must not be negative, using 0 (no header)")
+        cfg.HSTSMaxAgeSeconds = 0
+    }
+    if cfg.ErrorReportWorkers < 1 {
+        log.Printf("[CONFIG] ERROR_REPORT_WORKERS must be at least 1, using 1")
+        cfg.ErrorReportWorkers = 1
+    }
+    if cfg.StackTrace.initial < 1024 {
+        log.Printf("[CONFIG] STACK_TRACE_BUFFER_BYTES must be at least 1024, using 1024")
+        cfg.StackTrace.initial = 1024
+    }
+    if cfg.StackTrace.max < cfg.StackTrace.initial {
+        log.Printf("[CONFIG] STACK_TRACE_MAX_BYTES must be at least STACK_TRACE_BUFFER_BYTES (%d), using that", cfg.StackTrace.initial)
+        cfg.StackTrace.max = cfg.StackTrace.initial
+    }
+    if cfg.ShutdownDrainTimeout < 0 {
+        log.Printf("[CONFIG] SHUTDOWN_DRAIN_SECONDS must not be negative, using 0 (no drain)")
+        cfg.ShutdownDrainTimeout = 0
+    }
+    if cfg.RetryAfterMin < 0 {
+        log.Printf("[CONFIG] RETRY_AFTER_MIN_SECONDS must not be negative, using 0")
+        cfg.RetryAfterMin = 0
+    }
+    if cfg.RetryAfterMax < cfg.RetryAfterMin {
+        log.Printf("[CONFIG] RETRY_AFTER_MAX_SECONDS must be at least RETRY_AFTER_MIN_SECONDS (%d), disabling jitter", cfg.RetryAfterMin)
+        cfg.RetryAfterMax = cfg.RetryAfterMin
+    }
+    if cfg.JSONFieldCase != "pascal" && cfg.JSONFieldCase != "camel" {
+        log.Printf("[CONFIG] Invalid value for JSON_FIELD_CASE (%q), using default pascal", cfg.JSONFieldCase)
+        cfg.JSONFieldCase = "pascal"
+    }
+    if strings.EqualFold(cfg.ResponseCharset, "none") {
+        cfg.ResponseCharset = ""
+    }
+    return cfg
+}
+
+// parsePort validates PORT before anything tries to bind it, so a typo is reported
+// plainly at startup instead of as an opaque listen error. An unset PORT means 8080.
+// Ports below 1024 are allowed (the process may have CAP_NET_BIND_SERVICE) but
+// warned about, since binding them usually fails for an unprivileged user.
+func parsePort(raw string) (int, error) {
+    raw = strings.TrimSpace(raw)
+    if raw == "" {
+        return 8080, nil
+    }
+    port, err := strconv.Atoi(raw)
+    if err != nil {
+        return 0, fmt.Errorf("PORT must be a number between 1 and 65535, got %q", raw)
+    }
+    if port < 1 || port > 65535 {
+        return 0, fmt.Errorf("PORT must be between 1 and 65535, got %d", port)
+    }
+    if port < 1024 {
+        log.Printf("[CONFIG] PORT %d is a privileged port; binding it needs root or CAP_NET_BIND_SERVICE", port)
+    }
+    return port, nil
+}
+
+// normalizeBasePath turns BASE_PATH into "" (mounted at root) or "/segment[/...]"
+// with a leading and no trailing slash
+func normalizeBasePath(raw string) string {
+    path := strings.Trim(strings.TrimSpace(raw), "/")
+    if path == "" {
+        return ""
+    }
+    return "/" + path
+}
+
+// getEnvString reads a string environment variable, falling back to def when unset
+func getEnvString(name, def string) string {
+    if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+        return value
+    }
+    return def
+}
+
+// getEnvInt reads an integer environment variable, falling back to def when unset or invalid
+func getEnvInt(name string, def int) int {
+    raw := strings.TrimSpace(os.Getenv(name))
+    if raw == "" {
+        return def
+    }
+    value, err := strconv.Atoi(raw)
+    if err != nil {
+        log.Printf("[CONFIG] Invalid value for %s (%q), using default %d", name, raw, def)
+        return def
+    }
+    return value
+}
+
+// getEnvFloat reads a float environment variable, falling back to def when unset or invalid
+func getEnvFloat(name string, def float64) float64 {
+    raw := strings.TrimSpace(os.Getenv(name))
+    if raw == "" {
+        return def
+    }
+    value, err := strconv.ParseFloat(raw, 64)
+    if err != nil {
+        log.Printf("[CONFIG] Invalid value for %s (%q), using default %g", name, raw, def)
+        return def
+    }
+    return value
+}
+
+// getEnvBool reads a boolean environment variable, falling back to def when unset or invalid
+func getEnvBool(name string, def bool) bool {
+    raw := strings.TrimSpace(os.Getenv(name))
+    if raw == "" {
+        return def
+    }
+    value, err := strconv.ParseBool(raw)
+    if err != nil {
+        log.Printf("[CONFIG] Invalid value for %s (%q), using default %t", name, raw, def)
+        return def
+    }
+    return value
+}
+
+// getEnvDuration reads a duration environment variable such as "30s" or "2m"; a bare
+// number is taken as seconds. Falls back to def when unset or invalid.
+func getEnvDuration(name string, def time.Duration) time.Duration {
+    raw := strings.TrimSpace(os.Getenv(name))
+    if raw == "" {
+        return def
+    }
+    if seconds, err := strconv.Atoi(raw); err == nil {
+        return time.Duration(seconds) * time.Second
+    }
+    value, err := time.ParseDuration(raw)
+    if err != nil {
+        log.Printf("[CONFIG] Invalid value for %s (%q), using default %s", name, raw, def)
+        return def
+    }
+    return value
+}
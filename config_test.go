@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestParsePort(t *testing.T) {
+    tests := []struct {
+        raw     string
+        want    int
+        wantErr bool
+    }{
+        {"", 8080, false},
+        {"80", 80, false},
+        {" 8081 ", 8081, false},
+        {"65535", 65535, false},
+        {"abc", 0, true},
+        {"0", 0, true},
+        {"-1", 0, true},
+        {"70000", 0, true},
+    }
+    for _, tt := range tests {
+        got, err := parsePort(tt.raw)
+        if (err != nil) != tt.wantErr {
+            t.Errorf("parsePort(%q) error = %v, want error %v", tt.raw, err, tt.wantErr)
+            continue
+        }
+        if got != tt.want {
+            t.Errorf("parsePort(%q) = %d, want %d", tt.raw, got, tt.want)
+        }
+    }
+}
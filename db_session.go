@@ -0,0 +1,92 @@
+package main
+
+import (
+    "context"
+    "database/sql"
+    "database/sql/driver"
+    "fmt"
+    "time"
+
+    "github.com/lib/pq"
+)
+
+// sessionConnector runs session setup on every new connection before database/sql
+// hands it out. A SET issued through *sql.DB lands on whichever pooled connection
+// happens to run it; doing it here means the setting is in place on exactly the
+// connection each query later uses, for the connection's whole life.
+type sessionConnector struct {
+    driver.Connector
+    // statementTimeout makes Postgres abort statements running longer than this; 0
+    // leaves the server default
+    statementTimeout time.Duration
+}
+
+// sessionSearchPath is set on every connection: the isolated role's default
+// search_path doesn't include public, where the unqualified "TestProjects" of the
+// list queries lives
+const sessionSearchPath = `SET search_path = public, "$user"`
+
+// setupStatements are the SETs run on each new connection
+func (c *sessionConnector) setupStatements() []string {
+    statements := []string{sessionSearchPath}
+    if c.statementTimeout > 0 {
+        statements = append(statements, fmt.Sprintf("SET statement_timeout = %d", c.statementTimeout.Milliseconds()))
+    }
+    return statements
+}
+
+func (c *sessionConnector) Connect(ctx context.Context) (driver.Conn, error) {
+    conn, err := c.Connector.Connect(ctx)
+    if err != nil {
+        return nil, err
+    }
+    execer, ok := conn.(driver.ExecerContext)
+    if !ok {
+        conn.Close()
+        return nil, fmt.Errorf("driver connection does not support ExecContext")
+    }
+    for _, query := range c.setupStatements() {
+        if _, err := execer.ExecContext(ctx, query, nil); err != nil {
+            conn.Close()
+            return nil, fmt.Errorf("session setup (%s): %w", query, err)
+        }
+    }
+    return conn, nil
+}
+
+// openDB opens a Postgres pool for dsn. Every connection gets the search_path, and a
+// positive statementTimeout as statement_timeout (DB_STATEMENT_TIMEOUT_MS), so the
+// server itself cancels runaway queries whatever the client-side deadlines are.
+func openDB(dsn string, statementTimeout time.Duration) (*sql.DB, error) {
+    connector, err := pq.NewConnector(dsn)
+    if err != nil {
+        return nil, err
+    }
+    return sql.OpenDB(&sessionConnector{Connector: connector, statementTimeout: statementTimeout}), nil
+}
+
+// pingStartup checks the database is reachable before the server starts. The ping
+// is bounded by timeout (DB_STARTUP_PING_TIMEOUT_SECONDS; <= 0 waits indefinitely)
+// because a blackholed network would otherwise hang startup with nothing logged.
+//
+// lib/pq only applies the context to dialing, not to the startup handshake, so a
+// server that accepts the connection and then says nothing would still hang
+// PingContext; the ping runs in a goroutine and is abandoned at the deadline.
+func pingStartup(db *sql.DB, timeout time.Duration) error {
+    if timeout <= 0 {
+        return db.Ping()
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), timeout)
+    defer cancel()
+    
+    result := make(chan error, 1)
+    go func() { result <- db.PingContext(ctx) }()
+    select {
+    case err := <-result:
+        if err == nil || ctx.Err() == nil {
+            return err
+        }
+    case <-ctx.Done():
+    }
+    return fmt.Errorf("no response within %s (DB_STARTUP_PING_TIMEOUT_SECONDS); check the host is reachable", timeout)
+}
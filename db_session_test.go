@@ -0,0 +1,168 @@
+package main
+
+import (
+    "context"
+    "database/sql/driver"
+    "errors"
+    "net"
+    "os"
+    "reflect"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+    
+    "backend/Dberr"
+)
+
+// recordingConnector hands out connections that record the statements they exec
+type recordingConnector struct {
+    execs   []string
+    closed  int
+    failing string
+}
+
+func (c *recordingConnector) Connect(context.Context) (driver.Conn, error) {
+    return &recordingConn{connector: c}, nil
+}
+
+func (c *recordingConnector) Driver() driver.Driver {
+    return nil
+}
+
+type recordingConn struct {
+    connector *recordingConnector
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+    if query == c.connector.failing {
+        return nil, errors.New("refused")
+    }
+    c.connector.execs = append(c.connector.execs, query)
+    return driver.RowsAffected(0), nil
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) {
+    return nil, errors.New("not supported")
+}
+
+func (c *recordingConn) Close() error {
+    c.connector.closed++
+    return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+    return nil, errors.New("not supported")
+}
+
+func TestSessionConnectorSetsSearchPathOnEveryConnection(t *testing.T) {
+    tests := []struct {
+        name             string
+        statementTimeout time.Duration
+        want             []string
+    }{
+        {"no statement timeout", 0, []string{sessionSearchPath}},
+        {"with statement timeout", 1500 * time.Millisecond, []string{sessionSearchPath, "SET statement_timeout = 1500"}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            inner := &recordingConnector{}
+            c := &sessionConnector{Connector: inner, statementTimeout: tt.statementTimeout}
+            for i := 0; i < 2; i++ {
+                if _, err := c.Connect(context.Background()); err != nil {
+                    t.Fatalf("Connect: %v", err)
+                }
+            }
+            want := append(append([]string{}, tt.want...), tt.want...)
+            if !reflect.DeepEqual(inner.execs, want) {
+                t.Errorf("execs = %q, want %q", inner.execs, want)
+            }
+        })
+    }
+}
+
+func TestSessionConnectorClosesConnectionWhenSetupFails(t *testing.T) {
+    inner := &recordingConnector{failing: sessionSearchPath}
+    c := &sessionConnector{Connector: inner}
+    conn, err := c.Connect(context.Background())
+    if err == nil || conn != nil {
+        t.Fatalf("Connect = %v, %v; want an error", conn, err)
+    }
+    if inner.closed != 1 {
+        t.Errorf("%d connections closed, want the failed one closed", inner.closed)
+    }
+}
+
+// silentListener accepts connections and never answers, like a host behind a
+// firewall that drops everything after the TCP handshake
+func silentListener(t *testing.T) string {
+    t.Helper()
+    ln, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatalf("Listen: %v", err)
+    }
+    var mu sync.Mutex
+    var conns []net.Conn
+    go func() {
+        for {
+            conn, err := ln.Accept()
+            if err != nil {
+                return
+            }
+            mu.Lock()
+            conns = append(conns, conn)
+            mu.Unlock()
+        }
+    }()
+    t.Cleanup(func() {
+        ln.Close()
+        mu.Lock()
+        defer mu.Unlock()
+        for _, conn := range conns {
+            conn.Close()
+        }
+    })
+    return ln.Addr().String()
+}
+
+func TestPingStartupGivesUpOnUnresponsiveHost(t *testing.T) {
+    db, err := openDB("postgres://app:secret@"+silentListener(t)+"/main?sslmode=disable", 0)
+    if err != nil {
+        t.Fatalf("openDB: %v", err)
+    }
+    defer db.Close()
+    
+    start := time.Now()
+    err = pingStartup(db, 200*time.Millisecond)
+    elapsed := time.Since(start)
+    if err == nil {
+        t.Fatal("ping of a silent host succeeded")
+    }
+    if !strings.Contains(err.Error(), "DB_STARTUP_PING_TIMEOUT_SECONDS") {
+        t.Errorf("error %q does not point at DB_STARTUP_PING_TIMEOUT_SECONDS", err)
+    }
+    if elapsed > 2*time.Second {
+        t.Errorf("ping gave up after %s, want about the 200ms timeout", elapsed)
+    }
+}
+
+func TestStatementTimeoutCancelsSlowQueries(t *testing.T) {
+    dsn := os.Getenv("TEST_DATABASE_URL")
+    if dsn == "" {
+        t.Skip("TEST_DATABASE_URL not set")
+    }
+    db, err := openDB(dsn, 100*time.Millisecond)
+    if err != nil {
+        t.Fatalf("openDB: %v", err)
+    }
+    defer db.Close()
+    
+    start := time.Now()
+    _, err = db.ExecContext(context.Background(), "SELECT pg_sleep(5)")
+    if !dberr.IsQueryCanceled(err) {
+        t.Fatalf("pg_sleep(5) past a 100ms statement_timeout: err = %v, want a query_canceled error", err)
+    }
+    if elapsed := time.Since(start); elapsed > 2*time.Second {
+        t.Errorf("query cancelled after %s, want about 100ms", elapsed)
+    }
+}
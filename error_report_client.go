@@ -0,0 +1,83 @@
+package main
+
+import (
+    "log"
+    "net/http"
+    "sync/atomic"
+    "time"
+)
+
+// errorReportClient sends every error report. It is shared so reports reuse
+// keep-alive connections to the endpoint instead of dialing (and leaving behind) a
+// new connection each time, which matters during a burst of panics.
+var errorReportClient = newErrorReportClient(errorReportTimeout, 4)
+
+// newErrorReportClient returns a client that gives up on a report after timeout and
+// keeps at most maxIdle idle connections to the endpoint
+func newErrorReportClient(timeout time.Duration, maxIdle int) *http.Client {
+    transport := http.DefaultTransport.(*http.Transport).Clone()
+    transport.MaxIdleConns = maxIdle
+    transport.MaxIdleConnsPerHost = maxIdle
+    return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// errorReportQueueSize is how many reports may wait for a free worker before new
+// ones are dropped
+const errorReportQueueSize = 64
+
+// errorReportQueue holds panic reports waiting to be sent. A fixed set of workers
+// (ERROR_REPORT_WORKERS) drains it, so a burst of panics can never have more than
+// that many POSTs to the endpoint in flight at once.
+var errorReportQueue chan func()
+
+// pendingErrorReports counts reports queued or being sent, for flushErrorReports
+var pendingErrorReports atomic.Int64
+
+// configureErrorReporting applies ERROR_REPORT_TIMEOUT and ERROR_REPORT_MAX_IDLE_CONNS
+// and starts the ERROR_REPORT_WORKERS report workers. Call once at startup, before
+// any report can be sent.
+func configureErrorReporting(timeout time.Duration, maxIdle, workers int) {
+    errorReportTimeout = timeout
+    errorReportClient = newErrorReportClient(timeout, maxIdle)
+    
+    // Workers drain the queue they were started with, not whatever errorReportQueue
+    // points at later
+    queue := make(chan func(), errorReportQueueSize)
+    errorReportQueue = queue
+    for i := 0; i < workers; i++ {
+        go func() {
+            for send := range queue {
+                send()
+                pendingErrorReports.Add(-1)
+            }
+        }()
+    }
+}
+
+// enqueueErrorReport queues send for the report workers. When the queue is full the
+// report is dropped (and counted) rather than blocking the request that panicked.
+func enqueueErrorReport(send func()) bool {
+    pendingErrorReports.Add(1)
+    select {
+    case errorReportQueue <- send:
+        return true
+    default:
+        pendingErrorReports.Add(-1)
+        droppedPanicReports.Add(1)
+        log.Printf("[PANIC RECOVERY] Error report queue full (%d waiting), dropping report", errorReportQueueSize)
+        return false
+    }
+}
+
+// flushErrorReports waits up to timeout for every queued report to be sent, and
+// returns how many are still pending
+func flushErrorReports(timeout time.Duration) int64 {
+    deadline := time.Now().Add(timeout)
+    for {
+        pending := pendingErrorReports.Load()
+        if pending == 0 || time.Now().After(deadline) {
+            return pending
+        }
+        time.Sleep(10 * time.Millisecond)
+    }
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+    "context"
+    "database/sql"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestErrorReportWorkersCapConcurrentSends(t *testing.T) {
+    const workers = 2
+    configureErrorReporting(time.Second, 4, workers)
+    
+    var running, most atomic.Int64
+    for i := 0; i < 10; i++ {
+        if !enqueueErrorReport(func() {
+            n := running.Add(1)
+            for {
+                m := most.Load()
+                if n <= m || most.CompareAndSwap(m, n) {
+                    break
+                }
+            }
+            time.Sleep(5 * time.Millisecond)
+            running.Add(-1)
+        }) {
+            t.Fatalf("report %d dropped with room in the queue", i+1)
+        }
+    }
+    
+    if pending := flushErrorReports(5 * time.Second); pending != 0 {
+        t.Fatalf("%d reports still pending", pending)
+    }
+    if n := most.Load(); n > workers {
+        t.Errorf("%d reports sent at once, want at most ERROR_REPORT_WORKERS=%d", n, workers)
+    }
+}
+
+func TestRecordPanicToDbUsesTheReportQueue(t *testing.T) {
+    configureErrorReporting(time.Second, 4, 1)
+    conn := &recordingConnector{}
+    db := sql.OpenDB(conn)
+    defer db.Close()
+    
+    r := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+    recordPanicToDb(db, r, "boom", "goroutine 1 [running]:\n")
+    
+    if pending := flushErrorReports(5 * time.Second); pending != 0 {
+        t.Fatalf("%d reports still pending", pending)
+    }
+    if len(conn.execs) != 1 || !strings.Contains(conn.execs[0], "panic_log") {
+        t.Errorf("execs = %q, want the panic_log insert", conn.execs)
+    }
+}
+
+func TestRecordPanicToDbDropsWhenQueueIsFull(t *testing.T) {
+    // No workers: the queue only fills up
+    configureErrorReporting(time.Second, 4, 0)
+    defer configureErrorReporting(time.Second, 4, 1)
+    for i := 0; i < errorReportQueueSize; i++ {
+        enqueueErrorReport(func() {})
+    }
+    
+    dropped := droppedPanicReports.Load()
+    pending := pendingErrorReports.Load()
+    conn := &recordingConnector{}
+    db := sql.OpenDB(conn)
+    defer db.Close()
+    recordPanicToDb(db, httptest.NewRequest(http.MethodGet, "/api/test", nil), "boom", "")
+    
+    if n := droppedPanicReports.Load() - dropped; n != 1 {
+        t.Errorf("%d reports counted as dropped, want 1", n)
+    }
+    if pendingErrorReports.Load() != pending {
+        t.Error("dropped panic_log write counted as pending")
+    }
+    pendingErrorReports.Add(-errorReportQueueSize)
+}
+
+func TestErrorReportsReuseConnection(t *testing.T) {
+    var reports, conns atomic.Int64
+    endpoint := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        reports.Add(1)
+    }))
+    endpoint.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+        if state == http.StateNew {
+            conns.Add(1)
+        }
+    }
+    endpoint.Start()
+    defer endpoint.Close()
+    configureErrorReporting(time.Second, 4, 1)
+    
+    for i := 0; i < 2; i++ {
+        sendErrorToEndpoint(context.Background(), endpoint.URL, "", httptest.NewRequest(http.MethodGet, "/api/test", nil), "boom", "stack", "")
+    }
+    if n := reports.Load(); n != 2 {
+        t.Fatalf("%d reports received, want 2", n)
+    }
+    if n := conns.Load(); n != 1 {
+        t.Errorf("%d connections opened for 2 reports, want 1", n)
+    }
+}
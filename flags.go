@@ -0,0 +1,119 @@
+package main
+
+import (
+    "crypto/subtle"
+    "encoding/json"
+    "log"
+    "net/http"
+    "os"
+    "strconv"
+    "strings"
+    "sync"
+
+    "backend/Controllers"
+)
+
+// FeatureFlags is an in-memory set of named on/off switches, seeded from
+// FEATURE_FLAGS (e.g. "search=true,bar=false") and updatable at runtime
+type FeatureFlags struct {
+    mu    sync.RWMutex
+    flags map[string]bool
+}
+
+// parseFeatureFlags parses a "name=bool,name=bool" list; a bare name means true
+func parseFeatureFlags(raw string) *FeatureFlags {
+    f := &FeatureFlags{flags: map[string]bool{}}
+    for _, entry := range strings.Split(raw, ",") {
+        entry = strings.TrimSpace(entry)
+        if entry == "" {
+            continue
+        }
+        name, value, hasValue := strings.Cut(entry, "=")
+        name = strings.TrimSpace(name)
+        enabled := true
+        if hasValue {
+            parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+            if err != nil {
+                log.Printf("[CONFIG] Invalid value for feature flag %q (%q), ignoring", name, value)
+                continue
+            }
+            enabled = parsed
+        }
+        f.flags[name] = enabled
+    }
+    return f
+}
+
+// Enabled reports whether a flag is on; unknown flags are off
+func (f *FeatureFlags) Enabled(name string) bool {
+    f.mu.RLock()
+    defer f.mu.RUnlock()
+    return f.flags[name]
+}
+
+// Snapshot returns a copy of all flags
+func (f *FeatureFlags) Snapshot() map[string]bool {
+    f.mu.RLock()
+    defer f.mu.RUnlock()
+    snapshot := make(map[string]bool, len(f.flags))
+    for name, enabled := range f.flags {
+        snapshot[name] = enabled
+    }
+    return snapshot
+}
+
+// Set merges updates into the flag set
+func (f *FeatureFlags) Set(updates map[string]bool) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    for name, enabled := range updates {
+        f.flags[name] = enabled
+    }
+}
+
+// requireAdmin checks the request carries "Authorization: Bearer <ADMIN_TOKEN>".
+// Admin endpoints are disabled entirely when ADMIN_TOKEN is not set.
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+    token := os.Getenv("ADMIN_TOKEN")
+    if token == "" {
+        http.Error(w, "Admin endpoints are disabled (ADMIN_TOKEN not set)", http.StatusForbidden)
+        return false
+    }
+    
+    // The scheme is required: a bare token (or any other scheme) is refused rather than
+    // compared. Scheme names are case-insensitive (RFC 9110).
+    const scheme = "Bearer "
+    header := r.Header.Get("Authorization")
+    hasScheme := len(header) > len(scheme) && strings.EqualFold(header[:len(scheme)], scheme)
+    if !hasScheme || subtle.ConstantTimeCompare([]byte(header[len(scheme):]), []byte(token)) != 1 {
+        w.Header().Set("WWW-Authenticate", "Bearer")
+        http.Error(w, "Unauthorized", http.StatusUnauthorized)
+        return false
+    }
+    return true
+}
+
+// flagsHandler serves GET /admin/flags (read) and POST /admin/flags (admin-only update
+// with a JSON object of flag names to booleans)
+func flagsHandler(flags *FeatureFlags) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        switch r.Method {
+        case "GET":
+            controllers.WriteJSON(w, http.StatusOK, flags.Snapshot())
+        case "POST":
+            if !requireAdmin(w, r) {
+                return
+            }
+            var updates map[string]bool
+            if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+                http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+                return
+            }
+            flags.Set(updates)
+            log.Printf("[FLAGS] Updated feature flags: %v", updates)
+            controllers.WriteJSON(w, http.StatusOK, flags.Snapshot())
+        default:
+            http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+        }
+    }
+}
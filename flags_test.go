@@ -0,0 +1,53 @@
+package main
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestRequireAdmin(t *testing.T) {
+    tests := []struct {
+        name          string
+        authorization string
+        want          int
+    }{
+        {"bearer token", "Bearer s3cret", http.StatusOK},
+        {"lowercase scheme", "bearer s3cret", http.StatusOK},
+        {"bare token", "s3cret", http.StatusUnauthorized},
+        {"other scheme", "Basic s3cret", http.StatusUnauthorized},
+        {"wrong token", "Bearer nope", http.StatusUnauthorized},
+        {"token prefix", "Bearer s3cre", http.StatusUnauthorized},
+        {"scheme only", "Bearer ", http.StatusUnauthorized},
+        {"missing", "", http.StatusUnauthorized},
+    }
+    t.Setenv("ADMIN_TOKEN", "s3cret")
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            r := httptest.NewRequest(http.MethodGet, "/admin/config", nil)
+            if tt.authorization != "" {
+                r.Header.Set("Authorization", tt.authorization)
+            }
+            w := httptest.NewRecorder()
+            if requireAdmin(w, r) {
+                w.WriteHeader(http.StatusOK)
+            }
+            if w.Code != tt.want {
+                t.Errorf("status = %d, want %d", w.Code, tt.want)
+            }
+            if tt.want == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") != "Bearer" {
+                t.Error("WWW-Authenticate not set on 401")
+            }
+        })
+    }
+}
+
+func TestRequireAdminDisabledWithoutToken(t *testing.T) {
+    t.Setenv("ADMIN_TOKEN", "")
+    r := httptest.NewRequest(http.MethodGet, "/admin/config", nil)
+    r.Header.Set("Authorization", "Bearer ")
+    w := httptest.NewRecorder()
+    if requireAdmin(w, r) || w.Code != http.StatusForbidden {
+        t.Errorf("status = %d, want 403 with admin endpoints disabled", w.Code)
+    }
+}
@@ -4,4 +4,6 @@ go 1.21
 
 require (
     github.com/lib/pq v1.10.9
+    golang.org/x/sync v0.7.0
+    gopkg.in/yaml.v3 v3.0.1
 )
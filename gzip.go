@@ -0,0 +1,157 @@
+package main
+
+import (
+    "bufio"
+    "compress/gzip"
+    "fmt"
+    "net"
+    "net/http"
+    "strings"
+)
+
+// gzipMiddleware compresses responses for clients that accept gzip once the body
+// reaches minBytes (GZIP_MIN_BYTES). Smaller bodies gain little and cost CPU, so
+// output is buffered up to the threshold before deciding; a response that finishes
+// below it is sent as is. A minBytes < 0 disables compression.
+func gzipMiddleware(next http.Handler, minBytes int) http.Handler {
+    if minBytes < 0 {
+        return next
+    }
+    
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Method == http.MethodHead || r.Header.Get("Range") != "" || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+            next.ServeHTTP(w, r)
+            return
+        }
+        
+        w.Header().Add("Vary", "Accept-Encoding")
+        gw := &gzipResponseWriter{ResponseWriter: w, minBytes: minBytes}
+        defer gw.finish()
+        next.ServeHTTP(gw, r)
+    })
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(acceptEncoding string) bool {
+    for _, coding := range strings.Split(acceptEncoding, ",") {
+        name, params, _ := strings.Cut(strings.TrimSpace(coding), ";")
+        if !strings.EqualFold(strings.TrimSpace(name), "gzip") {
+            continue
+        }
+        // gzip;q=0 means "not gzip"
+        return strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+    }
+    return false
+}
+
+// gzipResponseWriter holds back the status and the first minBytes of the body until
+// it knows whether the response is worth compressing
+type gzipResponseWriter struct {
+    http.ResponseWriter
+    minBytes int
+    status   int
+    buf      []byte
+    // decided is set once the headers have gone out; gz is non-nil if compressing
+    decided bool
+    gz      *gzip.Writer
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+    if w.status == 0 {
+        w.status = status
+    }
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+    if w.status == 0 {
+        w.status = http.StatusOK
+    }
+    if w.decided {
+        if w.gz != nil {
+            return w.gz.Write(b)
+        }
+        return w.ResponseWriter.Write(b)
+    }
+    
+    w.buf = append(w.buf, b...)
+    if len(w.buf) >= w.minBytes {
+        if err := w.start(true); err != nil {
+            return 0, err
+        }
+    }
+    return len(b), nil
+}
+
+// start sends the headers, compressed or not, followed by whatever was buffered
+func (w *gzipResponseWriter) start(compress bool) error {
+    w.decided = true
+    header := w.Header()
+    if header.Get("Content-Encoding") != "" || w.status == http.StatusNoContent || w.status == http.StatusNotModified {
+        compress = false
+    }
+    if compress {
+        header.Set("Content-Encoding", "gzip")
+        header.Del("Content-Length")
+        w.gz = gzip.NewWriter(w.ResponseWriter)
+    }
+    w.ResponseWriter.WriteHeader(w.status)
+    
+    buffered := w.buf
+    w.buf = nil
+    if len(buffered) == 0 {
+        return nil
+    }
+    var err error
+    if w.gz != nil {
+        _, err = w.gz.Write(buffered)
+    } else {
+        _, err = w.ResponseWriter.Write(buffered)
+    }
+    return err
+}
+
+// finish sends a response that stayed below the threshold uncompressed, and
+// completes the gzip stream of one that didn't
+func (w *gzipResponseWriter) finish() {
+    if !w.decided {
+        if w.status == 0 {
+            // Nothing written at all; leave the response to the outer middleware
+            return
+        }
+        w.start(false)
+    }
+    if w.gz != nil {
+        w.gz.Close()
+    }
+}
+
+// Flush sends what has been written so far: streaming responses can't wait for the
+// threshold, so a flush commits to compressing
+func (w *gzipResponseWriter) Flush() {
+    if !w.decided {
+        if w.status == 0 {
+            w.status = http.StatusOK
+        }
+        w.start(true)
+    }
+    if w.gz != nil {
+        w.gz.Flush()
+    }
+    if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+        flusher.Flush()
+    }
+}
+
+// Hijack passes through to the underlying writer, failing if it can't be hijacked
+func (w *gzipResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+    hijacker, ok := w.ResponseWriter.(http.Hijacker)
+    if !ok {
+        return nil, nil, fmt.Errorf("%T does not support hijacking", w.ResponseWriter)
+    }
+    return hijacker.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+    return w.ResponseWriter
+}
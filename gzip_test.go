@@ -0,0 +1,108 @@
+package main
+
+import (
+    "compress/gzip"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+// serveGzip runs a request accepting gzip through gzipMiddleware with the given
+// threshold, the handler writing body in the given chunks with status
+func serveGzip(minBytes, status int, chunks ...string) *httptest.ResponseRecorder {
+    h := gzipMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(status)
+        for _, chunk := range chunks {
+            w.Write([]byte(chunk))
+        }
+    }), minBytes)
+    r := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+    r.Header.Set("Accept-Encoding", "gzip, deflate")
+    w := httptest.NewRecorder()
+    h.ServeHTTP(w, r)
+    return w
+}
+
+// gunzip decompresses a response body
+func gunzip(t *testing.T, w *httptest.ResponseRecorder) string {
+    t.Helper()
+    zr, err := gzip.NewReader(w.Body)
+    if err != nil {
+        t.Fatalf("gzip.NewReader: %v", err)
+    }
+    body, err := io.ReadAll(zr)
+    if err != nil {
+        t.Fatalf("read gzip body: %v", err)
+    }
+    return string(body)
+}
+
+func TestGzipThreshold(t *testing.T) {
+    tests := []struct {
+        name       string
+        minBytes   int
+        chunks     []string
+        compressed bool
+    }{
+        {"below threshold", 10, []string{"123456789"}, false},
+        {"at threshold", 10, []string{"1234567890"}, true},
+        {"crosses threshold across writes", 10, []string{"12345", "67890", "abc"}, true},
+        {"zero threshold compresses everything", 0, []string{"x"}, true},
+        {"empty body under zero threshold", 0, nil, false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            w := serveGzip(tt.minBytes, http.StatusOK, tt.chunks...)
+            want := strings.Join(tt.chunks, "")
+            
+            if w.Code != http.StatusOK {
+                t.Errorf("status = %d, want 200", w.Code)
+            }
+            if got := w.Header().Get("Content-Encoding") == "gzip"; got != tt.compressed {
+                t.Fatalf("compressed = %v, want %v", got, tt.compressed)
+            }
+            body := w.Body.String()
+            if tt.compressed {
+                body = gunzip(t, w)
+            }
+            if body != want {
+                t.Errorf("body = %q, want %q", body, want)
+            }
+        })
+    }
+}
+
+func TestGzipLeavesBodilessStatusesAlone(t *testing.T) {
+    w := serveGzip(0, http.StatusNoContent)
+    if w.Code != http.StatusNoContent || w.Header().Get("Content-Encoding") != "" {
+        t.Errorf("status = %d, Content-Encoding = %q; want 204 uncompressed", w.Code, w.Header().Get("Content-Encoding"))
+    }
+}
+
+func TestGzipNegativeThresholdDisables(t *testing.T) {
+    w := serveGzip(-1, http.StatusOK, strings.Repeat("x", 4096))
+    if w.Header().Get("Content-Encoding") != "" || w.Header().Get("Vary") != "" {
+        t.Errorf("headers = %v, want no compression", w.Header())
+    }
+}
+
+func TestAcceptsGzip(t *testing.T) {
+    tests := map[string]bool{
+        "":              false,
+        "gzip":          true,
+        "GZIP":          true,
+        "deflate, gzip": true,
+        "gzip;q=0.5":    true,
+        "gzip;q=0":      false,
+        "gzip; q = 0":   false,
+        "deflate, br":   false,
+        "x-gzip":        false,
+    }
+    for header, want := range tests {
+        if got := acceptsGzip(header); got != want {
+            t.Errorf("acceptsGzip(%q) = %v, want %v", header, got, want)
+        }
+    }
+}
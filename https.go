@@ -0,0 +1,59 @@
+package main
+
+import (
+    "net/http"
+    "net/url"
+    "strconv"
+    "strings"
+
+    "backend/Controllers"
+)
+
+// isHTTPS reports whether the client reached us over TLS: directly, or through a
+// TLS-terminating proxy that says so in X-Forwarded-Proto (first hop wins)
+func isHTTPS(r *http.Request) bool {
+    if r.TLS != nil {
+        return true
+    }
+    proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+    return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
+
+// forceHTTPSMiddleware enforces HTTPS behind a TLS-terminating proxy (FORCE_HTTPS).
+// Plain-HTTP GET/HEAD requests are 308-redirected to the same URL over https; other
+// methods get 403, since redirecting would resend their body in the clear. HTTPS
+// responses carry Strict-Transport-Security with hstsMaxAge seconds
+// (HSTS_MAX_AGE_SECONDS; 0 omits the header).
+//
+// X-Forwarded-Proto is taken at face value, so the service must only be reachable
+// through the proxy. Health and readiness probes are exempt: orchestrators call
+// them over plain HTTP, straight to the container.
+func forceHTTPSMiddleware(next http.Handler, hstsMaxAge int) http.Handler {
+    hsts := "max-age=" + strconv.Itoa(hstsMaxAge)
+    
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if isHTTPS(r) {
+            if hstsMaxAge > 0 {
+                w.Header().Set("Strict-Transport-Security", hsts)
+            }
+            next.ServeHTTP(w, r)
+            return
+        }
+        if isHealthPath(r.URL.Path) {
+            next.ServeHTTP(w, r)
+            return
+        }
+    
+        if r.Method != http.MethodGet && r.Method != http.MethodHead {
+            controllers.WriteJSON(w, http.StatusForbidden, map[string]string{"error": "HTTPS is required"})
+            return
+        }
+        // RequestURI is the target as the client sent it, before BASE_PATH stripping;
+        // it may be in absolute form, so only its path and query are kept
+        target := r.URL.RequestURI()
+        if sent, err := url.ParseRequestURI(r.RequestURI); err == nil {
+            target = sent.RequestURI()
+        }
+        http.Redirect(w, r, "https://"+r.Host+target, http.StatusPermanentRedirect)
+    })
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+    "crypto/tls"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func serveForceHTTPS(req *http.Request, hstsMaxAge int) (*httptest.ResponseRecorder, bool) {
+    reached := false
+    h := forceHTTPSMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        reached = true
+    }), hstsMaxAge)
+    w := httptest.NewRecorder()
+    h.ServeHTTP(w, req)
+    return w, reached
+}
+
+func TestForceHTTPSRedirectsPlainGet(t *testing.T) {
+    for _, method := range []string{http.MethodGet, http.MethodHead} {
+        req := httptest.NewRequest(method, "http://api.example.com/api/test?limit=5", nil)
+        w, reached := serveForceHTTPS(req, 31536000)
+        if w.Code != http.StatusPermanentRedirect {
+            t.Errorf("%s: status = %d, want 308", method, w.Code)
+        }
+        if loc := w.Header().Get("Location"); loc != "https://api.example.com/api/test?limit=5" {
+            t.Errorf("%s: Location = %q", method, loc)
+        }
+        if reached {
+            t.Errorf("%s: plain-HTTP request reached the handler", method)
+        }
+        if w.Header().Get("Strict-Transport-Security") != "" {
+            t.Errorf("%s: HSTS sent over plain HTTP", method)
+        }
+    }
+}
+
+func TestForceHTTPSRefusesPlainWrites(t *testing.T) {
+    for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+        w, reached := serveForceHTTPS(httptest.NewRequest(method, "/api/test", nil), 31536000)
+        if w.Code != http.StatusForbidden || reached {
+            t.Errorf("%s: status = %d (handler reached %v), want 403", method, w.Code, reached)
+        }
+    }
+}
+
+func TestForceHTTPSServesHTTPSWithHSTS(t *testing.T) {
+    proxied := httptest.NewRequest(http.MethodPost, "/api/test", nil)
+    proxied.Header.Set("X-Forwarded-Proto", "https")
+    direct := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+    direct.TLS = &tls.ConnectionState{}
+    
+    for name, req := range map[string]*http.Request{"X-Forwarded-Proto": proxied, "TLS": direct} {
+        w, reached := serveForceHTTPS(req, 600)
+        if !reached {
+            t.Errorf("%s: HTTPS request didn't reach the handler (status %d)", name, w.Code)
+        }
+        if hsts := w.Header().Get("Strict-Transport-Security"); hsts != "max-age=600" {
+            t.Errorf("%s: Strict-Transport-Security = %q, want max-age=600", name, hsts)
+        }
+    }
+    
+    // HSTS_MAX_AGE_SECONDS=0 omits the header
+    w, _ := serveForceHTTPS(direct, 0)
+    if hsts := w.Header().Get("Strict-Transport-Security"); hsts != "" {
+        t.Errorf("with max-age 0: Strict-Transport-Security = %q, want none", hsts)
+    }
+}
+
+func TestForceHTTPSExemptsHealthProbes(t *testing.T) {
+    for _, path := range []string{"/health", "/ready"} {
+        w, reached := serveForceHTTPS(httptest.NewRequest(http.MethodGet, path, nil), 600)
+        if !reached || w.Code != http.StatusOK {
+            t.Errorf("%s over plain HTTP = %d (handler reached %v), want it served", path, w.Code, reached)
+        }
+    }
+}
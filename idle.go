@@ -0,0 +1,66 @@
+package main
+
+import (
+    "net/http"
+    "sync/atomic"
+    "time"
+)
+
+// idleTracker records request activity so the process can shut itself down after a
+// quiet period (IDLE_SHUTDOWN_SECONDS), for scale-to-zero deployments
+type idleTracker struct {
+    lastActivity atomic.Int64 // unix nanoseconds
+    inflight     atomic.Int64
+    // countHealth makes health/ready probes count as activity
+    countHealth bool
+}
+
+func newIdleTracker(countHealth bool) *idleTracker {
+    t := &idleTracker{countHealth: countHealth}
+    t.lastActivity.Store(time.Now().UnixNano())
+    return t
+}
+
+// middleware marks each request as activity for as long as it runs
+func (t *idleTracker) middleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if !t.countHealth && isHealthPath(r.URL.Path) {
+            next.ServeHTTP(w, r)
+            return
+        }
+        
+        t.inflight.Add(1)
+        t.lastActivity.Store(time.Now().UnixNano())
+        defer func() {
+            t.lastActivity.Store(time.Now().UnixNano())
+            t.inflight.Add(-1)
+        }()
+        next.ServeHTTP(w, r)
+    })
+}
+
+// idleFor reports how long there has been no request activity (0 while any request
+// is in flight)
+func (t *idleTracker) idleFor() time.Duration {
+    if t.inflight.Load() > 0 {
+        return 0
+    }
+    return time.Since(time.Unix(0, t.lastActivity.Load()))
+}
+
+// watch blocks until the process has been idle for timeout, then calls onIdle once
+func (t *idleTracker) watch(timeout time.Duration, onIdle func(idle time.Duration)) {
+    interval := time.Second
+    if timeout < interval {
+        interval = timeout
+    }
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    
+    for range ticker.C {
+        if idle := t.idleFor(); idle >= timeout {
+            onIdle(idle)
+            return
+        }
+    }
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+    "encoding/json"
+    "io"
+    "log"
+    "os"
+    "regexp"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// logFormat is the active log format, "text" or "json" (LOG_FORMAT)
+var logFormat string
+
+// setupLogging switches the standard logger to the LOG_FORMAT format: "text" for the
+// usual human-readable lines, "json" for one JSON object per line. When unset it is
+// text on a terminal and JSON otherwise, i.e. in containers where logs are shipped.
+// Everything logged through the standard log package - startup, access, panic and
+// request-scoped loggers alike - follows the setting.
+func setupLogging(format string) string {
+    format = strings.ToLower(strings.TrimSpace(format))
+    invalid := format != "" && format != "text" && format != "json"
+    if format == "" || invalid {
+        format = "json"
+        if isTerminal(os.Stderr) {
+            format = "text"
+        }
+    }
+    
+    if format == "json" {
+        // Time and source become JSON fields; the writer adds the time itself
+        log.SetFlags(log.Lshortfile)
+        log.SetOutput(&jsonLogWriter{out: os.Stderr})
+    } else {
+        log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
+        log.SetOutput(os.Stderr)
+    }
+    if invalid {
+        log.Printf("[CONFIG] Invalid value for LOG_FORMAT, using %s", format)
+    }
+    return format
+}
+
+// isTerminal reports whether f is a terminal (character device) rather than a pipe or file
+func isTerminal(f *os.File) bool {
+    info, err := f.Stat()
+    return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+var (
+    // "main.go:123: " as written by log.Lshortfile
+    logSourcePattern = regexp.MustCompile(`^([^\s:]+\.go:\d+): `)
+    // the request-scoped fields written by controllers.NewRequestLogger
+    logRequestPattern = regexp.MustCompile(`^\[request_id=(\S*) method=(\S*) path=("(?:[^"\\]|\\.)*") board_id=(\S*)\] `)
+    // the bracketed category most lines start with, e.g. [ACCESS] or [DB ERROR]
+    logTagPattern = regexp.MustCompile(`^\[([A-Z][A-Z0-9 _]*)\] ?`)
+)
+
+// jsonLogEntry is one line of JSON log output
+type jsonLogEntry struct {
+    Time      string `json:"time"`
+    Source    string `json:"source,omitempty"`
+    Tag       string `json:"tag,omitempty"`
+    RequestId string `json:"request_id,omitempty"`
+    Method    string `json:"method,omitempty"`
+    Path      string `json:"path,omitempty"`
+    BoardId   string `json:"board_id,omitempty"`
+    Message   string `json:"msg"`
+}
+
+// jsonLogWriter turns each line from the standard logger into a jsonLogEntry, lifting
+// the source location, request fields and [TAG] out of the text into their own keys
+type jsonLogWriter struct {
+    mu  sync.Mutex
+    out io.Writer
+}
+
+func (w *jsonLogWriter) Write(p []byte) (int, error) {
+    line := strings.TrimSuffix(string(p), "\n")
+    entry := jsonLogEntry{Time: time.Now().UTC().Format(time.RFC3339Nano)}
+    
+    if m := logSourcePattern.FindStringSubmatch(line); m != nil {
+        entry.Source = m[1]
+        line = line[len(m[0]):]
+    }
+    if m := logRequestPattern.FindStringSubmatch(line); m != nil {
+        entry.RequestId, entry.Method, entry.BoardId = m[1], m[2], m[4]
+        if entry.BoardId == "-" {
+            entry.BoardId = ""
+        }
+        if path, err := strconv.Unquote(m[3]); err == nil {
+            entry.Path = path
+        }
+        line = line[len(m[0]):]
+    }
+    if m := logTagPattern.FindStringSubmatch(line); m != nil {
+        entry.Tag = m[1]
+        line = line[len(m[0]):]
+    }
+    entry.Message = line
+    
+    payload, err := json.Marshal(entry)
+    if err != nil {
+        return 0, err
+    }
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if _, err := w.out.Write(append(payload, '\n')); err != nil {
+        return 0, err
+    }
+    return len(p), nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "log"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "backend/Controllers"
+)
+
+// decodeLogLines parses the JSON lines written to buf
+func decodeLogLines(t *testing.T, buf *bytes.Buffer) []jsonLogEntry {
+    t.Helper()
+    var entries []jsonLogEntry
+    for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+        var entry jsonLogEntry
+        if err := json.Unmarshal([]byte(line), &entry); err != nil {
+            t.Fatalf("line %q is not JSON: %v", line, err)
+        }
+        entries = append(entries, entry)
+    }
+    return entries
+}
+
+func TestJSONLogWriterLiftsRequestFields(t *testing.T) {
+    var buf bytes.Buffer
+    r := httptest.NewRequest("GET", `/api/test/"quoted"`, nil)
+    requestLogger := controllers.NewRequestLogger(r, "abc123", "0123456789abcdef01234567")
+    requestLogger.SetOutput(&jsonLogWriter{out: &buf})
+    requestLogger.SetFlags(log.Lshortfile | log.Lmsgprefix)
+    requestLogger.Printf("[DB ERROR] query failed: %s", "boom")
+    
+    entries := decodeLogLines(t, &buf)
+    if len(entries) != 1 {
+        t.Fatalf("got %d lines, want 1", len(entries))
+    }
+    entry := entries[0]
+    if !strings.HasPrefix(entry.Source, "log_format_test.go:") {
+        t.Errorf("source = %q, want this file", entry.Source)
+    }
+    if entry.RequestId != "abc123" || entry.Method != "GET" || entry.Path != `/api/test/"quoted"` || entry.BoardId != "0123456789abcdef01234567" {
+        t.Errorf("request fields = %+v", entry)
+    }
+    if entry.Tag != "DB ERROR" || entry.Message != "query failed: boom" {
+        t.Errorf("tag = %q, msg = %q", entry.Tag, entry.Message)
+    }
+    if _, err := time.Parse(time.RFC3339Nano, entry.Time); err != nil {
+        t.Errorf("time %q: %v", entry.Time, err)
+    }
+}
+
+func TestJSONLogWriterPlainLines(t *testing.T) {
+    tests := []struct {
+        name string
+        line string
+        want jsonLogEntry
+    }{
+        {"message only", "server started\n", jsonLogEntry{Message: "server started"}},
+        {"tag only", "[CONFIG] Invalid value for PORT\n", jsonLogEntry{Tag: "CONFIG", Message: "Invalid value for PORT"}},
+        {"lowercase bracket is not a tag", "[debug] hello\n", jsonLogEntry{Message: "[debug] hello"}},
+        {"no board id", `[request_id=r1 method=POST path="/api/test" board_id=-] created` + "\n",
+            jsonLogEntry{RequestId: "r1", Method: "POST", Path: "/api/test", Message: "created"}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            var buf bytes.Buffer
+            w := &jsonLogWriter{out: &buf}
+            n, err := w.Write([]byte(tt.line))
+            if err != nil || n != len(tt.line) {
+                t.Fatalf("Write = %d, %v; want %d, nil", n, err, len(tt.line))
+            }
+            got := decodeLogLines(t, &buf)[0]
+            got.Time = ""
+            if got != tt.want {
+                t.Errorf("entry = %+v, want %+v", got, tt.want)
+            }
+        })
+    }
+}
@@ -1,38 +1,106 @@
 package main
 
 import (
+    "context"
     "database/sql"
+    "encoding/json"
     "fmt"
     "io"
     "log"
+    "math/rand"
+    "net"
     "net/http"
     "os"
+    "os/signal"
     "runtime"
     "strconv"
     "strings"
+    "sync"
+    "sync/atomic"
     "time"
 
     "backend/Controllers"
+    "backend/Models"
     _ "github.com/lib/pq"
 )
 
 // Configure logging - Warning and Error only
 // Create a custom logger that only shows warnings and errors
 func init() {
-    // Set log flags to include timestamp
-    log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
+    // Text (with timestamp) or JSON lines, per LOG_FORMAT; set up before anything logs
+    logFormat = setupLogging(os.Getenv("LOG_FORMAT"))
     // Note: Go's standard log package doesn't have severity levels,
     // but we can use log.Printf for warnings and log.Fatal/panic for errors
     // For production, consider using logrus or zap for proper log levels
 }
 
-func corsMiddleware(next http.Handler) http.Handler {
+const (
+    corsAllowedMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+    corsAllowedHeaders = "Content-Type, X-Board-Id, X-Request-Id"
+)
+
+// apiTestSubpathMethods lists the methods served by each fixed /api/test/<subpath>
+// route; see apiTestHandler in main
+var apiTestSubpathMethods = map[string]string{
+    "":            "GET, POST, OPTIONS",
+    "search":      "GET, OPTIONS",
+    "all":         "DELETE, OPTIONS",
+    "bulk":        "POST, OPTIONS",
+    "bulk/delete": "POST, OPTIONS",
+    "import":      "POST, OPTIONS",
+    "stats":       "GET, OPTIONS",
+    "by-name":     "GET, OPTIONS",
+    "rename":      "PUT, OPTIONS",
+}
+
+// routeMethods returns the methods the route at path supports, for the Allow and
+// Access-Control-Allow-Methods headers. It mirrors the dispatch in apiTestHandler, so
+// the two must change together. Paths outside /api/test get corsAllowedMethods.
+func routeMethods(path string) string {
+    if path == "/api/test" {
+        return apiTestSubpathMethods[""]
+    }
+    subpath, ok := strings.CutPrefix(path, "/api/test/")
+    if !ok {
+        return corsAllowedMethods
+    }
+    if methods, ok := apiTestSubpathMethods[subpath]; ok {
+        return methods
+    }
+    if idStr, ok := strings.CutSuffix(subpath, "/exists"); ok {
+        if _, err := models.ParseID(idStr); err == nil {
+            return "GET, HEAD, OPTIONS"
+        }
+    }
+    if idStr, ok := strings.CutSuffix(subpath, "/duplicate"); ok {
+        if _, err := models.ParseID(idStr); err == nil {
+            return "POST, OPTIONS"
+        }
+    }
+    if _, err := models.ParseID(subpath); err == nil {
+        return "GET, PUT, PATCH, DELETE, OPTIONS"
+    }
+    return corsAllowedMethods
+}
+
+// corsMiddleware sets the CORS headers on every response. Preflight (OPTIONS)
+// responses answer with the methods of the requested route, in both Allow and
+// Access-Control-Allow-Methods, and carry Access-Control-Max-Age so browsers cache
+// them for maxAgeSeconds.
+func corsMiddleware(next http.Handler, maxAgeSeconds int) http.Handler {
+    maxAge := strconv.Itoa(maxAgeSeconds)
     return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
         w.Header().Set("Access-Control-Allow-Origin", "*")
-        w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-        w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+        w.Header().Set("Access-Control-Allow-Methods", corsAllowedMethods)
+        w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
 
         if r.Method == "OPTIONS" {
+            methods := routeMethods(r.URL.Path)
+            w.Header().Set("Allow", methods)
+            w.Header().Set("Access-Control-Allow-Methods", methods)
+            if maxAgeSeconds > 0 {
+                w.Header().Set("Access-Control-Max-Age", maxAge)
+            }
             w.WriteHeader(http.StatusOK)
             return
         }
@@ -41,20 +109,27 @@ func corsMiddleware(next http.Handler) http.Handler {
     })
 }
 
-func panicRecoveryMiddleware(next http.Handler) http.Handler {
+// panicRecoveryMiddleware recovers handler panics, reports them, and returns a 500.
+// When panicDb is non-nil each panic is also recorded in the panic_log table, and
+// every panic is kept in the recent ring for GET /admin/errors/recent. The raw panic
+// message only reaches the client with exposeDetails (EXPOSE_PANIC_DETAILS).
+//
+// As the outermost middleware it also resolves the request's board id, once, and
+// stores it in the context for everything below (controllers.BoardIDFromContext);
+// the report needs it too, even for a panic in the middleware further down.
+func panicRecoveryMiddleware(next http.Handler, panicDb *sql.DB, recent *panicRing, sampleRate float64, stack stackLimits, exposeDetails bool) http.Handler {
     return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        boardId := extractBoardId(r)
+        r = r.WithContext(controllers.WithBoardID(r.Context(), boardId))
+        
         defer func() {
             if err := recover(); err != nil {
-                log.Printf("[PANIC RECOVERY] Recovered from panic: %v", err)
+                controllers.LoggerFromContext(r.Context()).Printf("[PANIC RECOVERY] Recovered from panic: %s (client %s)", redactDSN(fmt.Sprintf("%v", err)), clientIP(r))
                 
                 // Capture full stack trace including all goroutines to find the actual panic location
-                // Use true to get all goroutines, which will include the panic location
-                buf := make([]byte, 8192)
-                n := runtime.Stack(buf, true)
-                stackTrace := string(buf[:n])
+                // (the panicking goroutine comes first), bounded for the report
+                stackTrace := truncateStack(captureStack(stack), stack.report)
                 
-                // Extract boardId
-                boardId := extractBoardId(r)
                 log.Printf("[PANIC RECOVERY] Extracted boardId: %s", func() string {
                     if boardId == "" { return "NULL" }
                     return boardId
@@ -62,17 +137,38 @@ func panicRecoveryMiddleware(next http.Handler) http.Handler {
                 
                 // Send error to runtime error endpoint if configured
                 runtimeErrorEndpointUrl := os.Getenv("RUNTIME_ERROR_ENDPOINT_URL")
-                if runtimeErrorEndpointUrl != "" {
-                    log.Printf("[PANIC RECOVERY] Sending error to endpoint: %s", runtimeErrorEndpointUrl)
-                    go sendErrorToEndpoint(runtimeErrorEndpointUrl, boardId, r, err, stackTrace)
+                if runtimeErrorEndpointUrl != "" && !samplePanicReport(sampleRate) {
+                    droppedPanicReports.Add(1)
+                    log.Printf("[PANIC RECOVERY] Not reporting to endpoint (sampled out at PANIC_REPORT_SAMPLE_RATE=%g)", sampleRate)
+                } else if runtimeErrorEndpointUrl != "" {
+                    log.Printf("[PANIC RECOVERY] Sending error to endpoint: %s", redactDSN(runtimeErrorEndpointUrl))
+                    reportCtx, cancel := errorReportContext(r)
+                    body := requestBodySnippet(r)
+                    if !enqueueErrorReport(func() {
+                        defer cancel()
+                        sendErrorToEndpoint(reportCtx, runtimeErrorEndpointUrl, boardId, r, err, stackTrace, body)
+                    }) {
+                        cancel()
+                    }
                 } else {
                     log.Printf("[PANIC RECOVERY] RUNTIME_ERROR_ENDPOINT_URL is not set - skipping error reporting")
                 }
                 
-                // Return error response
-                w.Header().Set("Content-Type", "application/json")
-                w.WriteHeader(http.StatusInternalServerError)
-                fmt.Fprintf(w, `{"error":"An error occurred while processing your request","message":"%s"}`, fmt.Sprintf("%v", err))
+                if panicDb != nil {
+                    recordPanicToDb(panicDb, r, err, stackTrace)
+                }
+                recent.record(r, boardId, err, stackTrace)
+                
+                // Return error response - the raw panic message is only exposed when
+                // EXPOSE_PANIC_DETAILS=true (dev); production clients get a generic message
+                message := "An internal error occurred"
+                if exposeDetails {
+                    message = fmt.Sprintf("%v", err)
+                }
+                controllers.WriteJSON(w, http.StatusInternalServerError, map[string]string{
+                    "error":   "An error occurred while processing your request",
+                    "message": message,
+                })
             }
         }()
         
@@ -80,15 +176,24 @@ func panicRecoveryMiddleware(next http.Handler) http.Handler {
     })
 }
 
+// extractBoardId resolves the board a request belongs to. Client-supplied values (the
+// boardId query parameter and X-Board-Id header) must be a valid boardId; anything
+// else is logged and ignored so a malformed value can't reach reports or scoping.
 func extractBoardId(r *http.Request) string {
     // Try query parameter
     if boardId := r.URL.Query().Get("boardId"); boardId != "" {
-        return boardId
+        if isValidBoardId(boardId) {
+            return boardId
+        }
+        log.Printf("[BOARD ID] Ignoring invalid boardId query parameter %q", truncateForLog(boardId))
     }
     
     // Try header
     if boardId := r.Header.Get("X-Board-Id"); boardId != "" {
-        return boardId
+        if isValidBoardId(boardId) {
+            return boardId
+        }
+        log.Printf("[BOARD ID] Ignoring invalid X-Board-Id header %q", truncateForLog(boardId))
     }
     
     // Try environment variable
@@ -102,10 +207,10 @@ func extractBoardId(r *http.Request) string {
         // Simple regex-like matching using strings
         if idx := strings.Index(strings.ToLower(host), "webapi"); idx >= 0 {
             remaining := host[idx+6:] // Skip "webapi"
-            if len(remaining) >= 24 {
+            if len(remaining) >= boardIdLength {
                 // Check if next 24 chars are hex
-                boardId := remaining[:24]
-                if isValidHex(boardId) {
+                boardId := remaining[:boardIdLength]
+                if isValidBoardId(boardId) {
                     return boardId
                 }
             }
@@ -117,9 +222,9 @@ func extractBoardId(r *http.Request) string {
     if endpointUrl != "" {
         if idx := strings.Index(strings.ToLower(endpointUrl), "webapi"); idx >= 0 {
             remaining := endpointUrl[idx+6:]
-            if len(remaining) >= 24 {
-                boardId := remaining[:24]
-                if isValidHex(boardId) {
+            if len(remaining) >= boardIdLength {
+                boardId := remaining[:boardIdLength]
+                if isValidBoardId(boardId) {
                     return boardId
                 }
             }
@@ -129,7 +234,28 @@ func extractBoardId(r *http.Request) string {
     return ""
 }
 
+// boardIdLength is the length of a boardId: 24 hex characters
+const boardIdLength = 24
+
+// isValidBoardId reports whether s is exactly boardIdLength hex characters
+func isValidBoardId(s string) bool {
+    return len(s) == boardIdLength && isValidHex(s)
+}
+
+// truncateForLog shortens a client-supplied value before it is logged
+func truncateForLog(s string) string {
+    const max = 64
+    if len(s) > max {
+        return s[:max] + "..."
+    }
+    return s
+}
+
+// isValidHex reports whether s is a non-empty string of hex digits
 func isValidHex(s string) bool {
+    if s == "" {
+        return false
+    }
     for _, c := range s {
         if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
             return false
@@ -138,15 +264,14 @@ func isValidHex(s string) bool {
     return true
 }
 
-func sendErrorToEndpoint(endpointUrl, boardId string, r *http.Request, err interface{}, stackTrace string) {
+// panicLocation finds the file and line of the actual panic in a stack trace captured
+// with runtime.Stack, skipping the recovery machinery and standard library frames
+func panicLocation(stackTrace string) (fileName string, lineNumber int) {
     // Parse stack trace to extract file and line number from the actual panic location
     // Go stack trace format: 
     // goroutine X [running]:
     // main.functionName(...)
     //     /path/to/file.go:123 +0x...
-    var fileName string
-    var lineNumber int
-    
     lines := strings.Split(stackTrace, "\n")
     // Go stack trace format (with all goroutines):
     // goroutine X [running]:
@@ -225,6 +350,42 @@ func sendErrorToEndpoint(endpointUrl, boardId string, r *http.Request, err inter
         }
     }
     
+    return fileName, lineNumber
+}
+
+// droppedPanicReports counts panic reports not sent to RUNTIME_ERROR_ENDPOINT_URL (or
+// written to panic_log) because they were sampled out or the report queue was full
+// (exposed on /metrics)
+var droppedPanicReports atomic.Int64
+
+// samplePanicReport decides whether a panic is reported to the endpoint, keeping
+// roughly rate (0.0-1.0) of them. Panics are always logged locally regardless.
+func samplePanicReport(rate float64) bool {
+    return rate >= 1 || rand.Float64() < rate
+}
+
+// errorReportTimeout bounds an error report when the request itself has no deadline
+// (ERROR_REPORT_TIMEOUT; see configureErrorReporting)
+var errorReportTimeout = 5 * time.Second
+
+// errorReportContext derives the context for a panic report from the request. The
+// report outlives the handler, so the request's own cancellation (which fires as soon
+// as the handler returns) is dropped, but its deadline is kept: a report never runs
+// past the point the request itself was allowed to. Without a deadline the report
+// gets errorReportTimeout.
+func errorReportContext(r *http.Request) (context.Context, context.CancelFunc) {
+    ctx := context.WithoutCancel(r.Context())
+    if deadline, ok := r.Context().Deadline(); ok {
+        return context.WithDeadline(ctx, deadline)
+    }
+    return context.WithTimeout(ctx, errorReportTimeout)
+}
+
+// sendErrorToEndpoint posts a panic report. body is the redacted request body
+// snippet (PANIC_REPORT_BODY_BYTES), sent as requestBody when not empty.
+func sendErrorToEndpoint(ctx context.Context, endpointUrl, boardId string, r *http.Request, err interface{}, stackTrace, body string) {
+    fileName, lineNumber := panicLocation(stackTrace)
+    
     // Escape stack trace for JSON (handle newlines, backslashes, and quotes)
     escapedStackTrace := strings.ReplaceAll(stackTrace, `\`, `\\`)
     escapedStackTrace = strings.ReplaceAll(escapedStackTrace, `"`, `\"`)
@@ -245,6 +406,12 @@ func sendErrorToEndpoint(endpointUrl, boardId string, r *http.Request, err inter
         lineJson = fmt.Sprintf("%d", lineNumber)
     }
     
+    bodyJson := "null"
+    if body != "" {
+        quoted, _ := json.Marshal(body)
+        bodyJson = string(quoted)
+    }
+    
     payload := fmt.Sprintf(`{
         "boardId":%s,
         "timestamp":"%s",
@@ -255,7 +422,8 @@ func sendErrorToEndpoint(endpointUrl, boardId string, r *http.Request, err inter
         "exceptionType":"panic",
         "requestPath":"%s",
         "requestMethod":"%s",
-        "userAgent":"%s"
+        "userAgent":"%s",
+        "requestBody":%s
     }`,
         func() string {
             if boardId == "" { return "null" }
@@ -269,21 +437,25 @@ func sendErrorToEndpoint(endpointUrl, boardId string, r *http.Request, err inter
         r.URL.Path,
         r.Method,
         r.UserAgent(),
+        bodyJson,
     )
     
-    // Send POST request (fire and forget)
-    req, err2 := http.NewRequest("POST", endpointUrl, strings.NewReader(payload))
+    // Send POST request (fire and forget); ctx bounds how long it may take
+    req, err2 := http.NewRequestWithContext(ctx, "POST", endpointUrl, strings.NewReader(payload))
     if err2 != nil {
-        log.Printf("[PANIC RECOVERY] Failed to create request: %v", err2)
+        log.Printf("[PANIC RECOVERY] Failed to create request: %s", redactErr(err2))
         return
     }
     
     req.Header.Set("Content-Type", "application/json")
-    client := &http.Client{Timeout: 5 * time.Second}
     
-    resp, err2 := client.Do(req)
+    resp, err2 := errorReportClient.Do(req)
     if err2 != nil {
-        log.Printf("[PANIC RECOVERY] Failed to send error to endpoint: %v", err2)
+        if ctxErr := ctx.Err(); ctxErr != nil {
+            log.Printf("[PANIC RECOVERY] Error report aborted: %v", ctxErr)
+            return
+        }
+        log.Printf("[PANIC RECOVERY] Failed to send error to endpoint: %s", redactErr(err2))
         return
     }
     defer resp.Body.Close()
@@ -293,251 +465,294 @@ func sendErrorToEndpoint(endpointUrl, boardId string, r *http.Request, err inter
         log.Printf("[PANIC RECOVERY] Error endpoint response: %d - %s", resp.StatusCode, string(body))
     } else {
         log.Printf("[PANIC RECOVERY] Error endpoint response: %d", resp.StatusCode)
+        // Drain the body so the connection goes back to the pool for the next report
+        io.Copy(io.Discard, resp.Body)
+    }
+}
+
+// warmupPool opens and pings n connections concurrently so the pool is primed
+// before the server starts accepting traffic. The connections are held until all
+// have been established (otherwise the pool would just hand the same one back),
+// then released into the idle pool.
+func warmupPool(db *sql.DB, n int) error {
+    ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+    defer cancel()
+    
+    conns := make([]*sql.Conn, 0, n)
+    defer func() {
+        for _, conn := range conns {
+            conn.Close()
+        }
+    }()
+    
+    for i := 0; i < n; i++ {
+        conn, err := db.Conn(ctx)
+        if err != nil {
+            return err
+        }
+        conns = append(conns, conn)
+        if err := conn.PingContext(ctx); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// poolStats summarizes sql.DBStats for the /health/db endpoint
+func poolStats(db *sql.DB) map[string]interface{} {
+    stats := db.Stats()
+    // InUse/MaxOpen; null when the pool is unbounded
+    var utilization interface{}
+    if stats.MaxOpenConnections > 0 {
+        utilization = float64(stats.InUse) / float64(stats.MaxOpenConnections)
+    }
+    return map[string]interface{}{
+        "maxOpenConnections": stats.MaxOpenConnections,
+        "openConnections":    stats.OpenConnections,
+        "inUse":              stats.InUse,
+        "idle":               stats.Idle,
+        "waitCount":          stats.WaitCount,
+        "waitDurationMs":     stats.WaitDuration.Milliseconds(),
+        "utilization":        utilization,
     }
 }
 
 func main() {
-    databaseUrl := os.Getenv("DATABASE_URL")
+    cfg := loadConfig()
+    configureErrorReporting(cfg.ErrorReportTimeout, cfg.ErrorReportMaxIdleConns, cfg.ErrorReportWorkers)
+    controllers.SetRetryAfterRange(cfg.RetryAfterMin, cfg.RetryAfterMax)
+    
+    gate := newStartupGate(cfg.BasePath)
+    
+    port := strconv.Itoa(cfg.Port)
+
+    // LISTEN_ADDR restricts which interface we bind to (e.g. 127.0.0.1); all interfaces by default
+    listenAddr := cfg.ListenAddr
+    bindAddr := net.JoinHostPort(listenAddr, port)
+    if _, err := net.ResolveTCPAddr("tcp", bindAddr); err != nil {
+        log.Fatalf("[STARTUP ERROR] Invalid listen address %q (LISTEN_ADDR=%q, PORT=%q): %v", bindAddr, listenAddr, port, err)
+    }
+
+    // Explicit timeouts guard against slowloris-style clients holding connections open
+    server := &http.Server{
+        Addr:              bindAddr,
+        Handler:           gate,
+        ReadTimeout:       cfg.HTTPReadTimeout,
+        ReadHeaderTimeout: cfg.HTTPReadTimeout,
+        WriteTimeout:      cfg.HTTPWriteTimeout,
+        IdleTimeout:       cfg.HTTPIdleTimeout,
+    }
+
+    // Listen before connecting to the database; the gate answers 503 (and /health
+    // 200) until everything below is set up
+    serveErr := make(chan error, 1)
+    listener, err := net.Listen("tcp", bindAddr)
+    if err != nil {
+        // Reported once setup is done, by the startup error handling at the end
+        log.Printf("[STARTUP ERROR] Failed to bind %s: %v", bindAddr, err)
+        serveErr <- err
+    } else {
+        log.Printf("Server listening on %s (read timeout %s, write timeout %s, idle timeout %s); waiting for the database",
+            bindAddr, server.ReadTimeout, server.WriteTimeout, server.IdleTimeout)
+        go func() { serveErr <- server.Serve(listener) }()
+    }
+    
+    databaseUrl := cfg.DatabaseURL
     if databaseUrl == "" {
         log.Fatal("DATABASE_URL environment variable not set")
     }
 
-    db, err := sql.Open("postgres", databaseUrl)
+    db, err := openDB(databaseUrl, cfg.DBStatementTimeout)
     if err != nil {
-        log.Fatal("Failed to connect to database: ", err)
+        log.Fatal("Failed to connect to database: ", redactErr(err))
     }
     defer db.Close()
 
-    if err := db.Ping(); err != nil {
-        log.Fatal("Failed to ping database: ", err)
+    db.SetMaxIdleConns(cfg.DBMaxIdle)
+    db.SetMaxOpenConns(cfg.DBMaxOpen)
+    
+    if err := pingStartup(db, cfg.DBStartupPingTimeout); err != nil {
+        log.Fatal("Failed to ping database: ", redactErr(err))
+    }
+    
+    // Optional read replica for GET endpoints; reads fall back to the primary when unset
+    var replicaDb *sql.DB
+    if replicaUrl := cfg.DatabaseReplicaURL; replicaUrl != "" {
+        replicaDb, err = openDB(replicaUrl, cfg.DBStatementTimeout)
+        if err != nil {
+            log.Fatal("Failed to connect to replica database: ", redactErr(err))
+        }
+        defer replicaDb.Close()
+        
+        replicaDb.SetMaxIdleConns(cfg.DBMaxIdle)
+        replicaDb.SetMaxOpenConns(cfg.DBMaxOpen)
+        
+        if err := pingStartup(replicaDb, cfg.DBStartupPingTimeout); err != nil {
+            log.Fatal("Failed to ping replica database: ", redactErr(err))
+        }
+        log.Printf("Read replica configured - GET endpoints will use DATABASE_REPLICA_URL")
     }
+    
+    if cfg.WarmupPool && cfg.DBMaxIdle > 0 {
+        pools := map[string]*sql.DB{"primary": db}
+        if replicaDb != nil {
+            pools["replica"] = replicaDb
+        }
+        for name, pool := range pools {
+            start := time.Now()
+            if err := warmupPool(pool, cfg.DBMaxIdle); err != nil {
+                log.Printf("[WARMUP] %s pool warmup failed after %s: %s", name, time.Since(start), redactErr(err))
+            } else {
+                log.Printf("[WARMUP] Primed %d %s database connections in %s", cfg.DBMaxIdle, name, time.Since(start))
+            }
+        }
+    }
+
+    var panicDb *sql.DB
+    if cfg.PanicDbLog {
+        panicDb = db
+    }
+
+    flags := parseFeatureFlags(cfg.FeatureFlags)
 
+    controllers.SetResponseCharset(cfg.ResponseCharset)
+    models.IdsAsStrings = cfg.IdAsString
+    models.CamelCaseJSON = cfg.JSONFieldCase == "camel"
     controller := controllers.NewTestController(db)
+    controller.Metrics = controllers.NewOperationMetrics()
+    controller.AllowDestructive = cfg.AllowDestructive
+    if cfg.NameUniquePerBoard {
+        controller.NameUniquePerBoard = true
+    }
+    if cfg.AllowDestructive {
+        log.Printf("[CONFIG] ALLOW_DESTRUCTIVE is set - DELETE /api/test/all is enabled")
+    }
+    controller.ReadDB = replicaDb
+    controller.DefaultPageSize = cfg.DefaultPageSize
+    controller.MaxPageSize = cfg.MaxPageSize
+    controller.MaxFields = cfg.MaxFields
+    controller.LenientQuery = cfg.LenientQuery
+    controller.AcquireTimeout = cfg.DBAcquireTimeout
+    
+    prepareCtx, cancelPrepare := context.WithTimeout(context.Background(), 10*time.Second)
+    if err := controller.PrepareStatements(prepareCtx); err != nil {
+        cancelPrepare()
+        log.Fatal("Failed to prepare statements: ", redactErr(err))
+    }
+    cancelPrepare()
+    defer controller.Close()
+    if cfg.AuditLog {
+        controller.Audit = controllers.NewAuditLogger(os.Stdout)
+    }
     mux := http.NewServeMux()
-
-    // Apply panic recovery middleware to all routes
-    handler := panicRecoveryMiddleware(corsMiddleware(mux))
+    idle := newIdleTracker(cfg.IdleShutdownCountHealth)
 
     mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
         if r.URL.Path != "/" {
             http.NotFound(w, r)
             return
         }
-        w.Header().Set("Content-Type", "application/json")
-        fmt.Fprintf(w, `{"message":"Backend API is running","status":"ok","swagger":"/swagger","api":"/api/test"}`)
+        controllers.WriteJSON(w, http.StatusOK, map[string]string{
+            "message": "Backend API is running",
+            "status":  "ok",
+            "swagger": cfg.BasePath + "/swagger",
+            "api":     cfg.BasePath + "/api/test",
+        })
     })
 
     mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-        w.Header().Set("Content-Type", "application/json")
-        fmt.Fprintf(w, `{"status":"healthy","service":"Backend API"}`)
+        controllers.WriteJSON(w, http.StatusOK, map[string]string{"status": "healthy", "service": "Backend API"})
     })
 
-    // Swagger UI endpoint - serve interactive Swagger UI HTML page
-    mux.HandleFunc("/swagger", func(w http.ResponseWriter, r *http.Request) {
-        w.Header().Set("Content-Type", "text/html")
-        fmt.Fprintf(w, `<!DOCTYPE html>
-<html>
-<head>
-    <title>Backend API - Swagger UI</title>
-    <link rel="stylesheet" type="text/css" href="https://unpkg.com/swagger-ui-dist@5.9.0/swagger-ui.css" />
-    <style>
-        html { box-sizing: border-box; overflow: -moz-scrollbars-vertical; overflow-y: scroll; }
-        *, *:before, *:after { box-sizing: inherit; }
-        body { margin:0; background: #fafafa; }
-    </style>
-</head>
-<body>
-    <div id="swagger-ui"></div>
-    <script src="https://unpkg.com/swagger-ui-dist@5.9.0/swagger-ui-bundle.js"></script>
-    <script src="https://unpkg.com/swagger-ui-dist@5.9.0/swagger-ui-standalone-preset.js"></script>
-    <script>
-        window.onload = function() {
-            const ui = SwaggerUIBundle({
-                url: "/swagger.json",
-                dom_id: "#swagger-ui",
-                deepLinking: true,
-                presets: [
-                    SwaggerUIBundle.presets.apis,
-                    SwaggerUIStandalonePreset
-                ],
-                plugins: [
-                    SwaggerUIBundle.plugins.DownloadUrl
-                ],
-                layout: "StandaloneLayout"
-            });
-        };
-    </script>
-</body>
-</html>`)
-    })
-
-    // Swagger JSON endpoint - return OpenAPI spec as JSON
-    mux.HandleFunc("/swagger.json", func(w http.ResponseWriter, r *http.Request) {
-        w.Header().Set("Content-Type", "application/json")
-        fmt.Fprintf(w, `{
-  "openapi": "3.0.0",
-  "info": {
-    "title": "Backend API",
-    "version": "1.0.0",
-    "description": "Go Backend API Documentation"
-  },
-  "paths": {
-    "/api/test": {
-      "get": {
-        "summary": "Get all test projects",
-        "responses": {
-          "200": {
-            "description": "List of test projects",
-            "content": {
-              "application/json": {
-                "schema": {
-                  "type": "array",
-                  "items": {
-                    "$ref": "#/components/schemas/TestProjects"
-                  }
-                }
-              }
-            }
-          }
-        }
-      },
-      "post": {
-        "summary": "Create a new test project",
-        "requestBody": {
-          "required": true,
-          "content": {
-            "application/json": {
-              "schema": {
-                "$ref": "#/components/schemas/TestProjectsInput"
-              }
-            }
-          }
-        },
-        "responses": {
-          "201": {
-            "description": "Created test project",
-            "content": {
-              "application/json": {
-                "schema": {
-                  "$ref": "#/components/schemas/TestProjects"
-                }
-              }
-            }
-          }
-        }
-      }
-    },
-    "/api/test/{id}": {
-      "get": {
-        "summary": "Get test project by ID",
-        "parameters": [
-          {
-            "name": "id",
-            "in": "path",
-            "required": true,
-            "schema": {
-              "type": "integer"
-            }
-          }
-        ],
-        "responses": {
-          "200": {
-            "description": "Test project found",
-            "content": {
-              "application/json": {
-                "schema": {
-                  "$ref": "#/components/schemas/TestProjects"
-                }
-              }
-            }
-          },
-          "404": {
-            "description": "Project not found"
-          }
-        }
-      },
-      "put": {
-        "summary": "Update test project",
-        "parameters": [
-          {
-            "name": "id",
-            "in": "path",
-            "required": true,
-            "schema": {
-              "type": "integer"
-            }
-          }
-        ],
-        "requestBody": {
-          "required": true,
-          "content": {
-            "application/json": {
-              "schema": {
-                "$ref": "#/components/schemas/TestProjectsInput"
-              }
-            }
-          }
-        },
-        "responses": {
-          "200": {
-            "description": "Updated test project"
-          },
-          "404": {
-            "description": "Project not found"
-          }
+    // Server and database clocks, for diagnosing clock skew
+    mux.HandleFunc("/api/time", func(w http.ResponseWriter, r *http.Request) {
+        if r.Method != "GET" {
+            http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+            return
         }
-      },
-      "delete": {
-        "summary": "Delete test project",
-        "parameters": [
-          {
-            "name": "id",
-            "in": "path",
-            "required": true,
-            "schema": {
-              "type": "integer"
-            }
-          }
-        ],
-        "responses": {
-          "200": {
-            "description": "Deleted successfully"
-          },
-          "404": {
-            "description": "Project not found"
-          }
+        
+        var dbTime time.Time
+        if err := db.QueryRowContext(r.Context(), "SELECT now()").Scan(&dbTime); err != nil {
+            log.Printf("[TIME] Failed to read database time: %s", redactErr(err))
+            controllers.WriteJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "database time unavailable"})
+            return
         }
-      }
-    }
-  },
-  "components": {
-    "schemas": {
-      "TestProjects": {
-        "type": "object",
-        "properties": {
-          "Id": {
-            "type": "integer"
-          },
-          "Name": {
-            "type": "string"
-          }
+        
+        controllers.WriteJSON(w, http.StatusOK, map[string]string{
+            "server":   time.Now().UTC().Format(time.RFC3339Nano),
+            "database": dbTime.UTC().Format(time.RFC3339Nano),
+        })
+    })
+
+    // Prometheus-style metrics
+    mux.HandleFunc("/metrics", metricsHandler(controller.Metrics))
+
+    // Runtime feature flags (seeded from FEATURE_FLAGS)
+    mux.HandleFunc("/admin/flags", flagsHandler(flags))
+
+    // Effective configuration, secrets redacted (admin-only)
+    mux.HandleFunc("/admin/config", configHandler(cfg))
+    
+    // The last RECENT_PANICS_SIZE recovered panics, newest first (admin-only)
+    recentPanics := newPanicRing(cfg.RecentPanicsSize)
+    mux.HandleFunc("/admin/errors/recent", recentPanicsHandler(recentPanics))
+
+    // Readiness: per-subsystem checks, 503 only when a critical one is down
+    readinessChecks := []readinessCheck{
+        {name: "primary_db", check: db.PingContext},
+        {name: "primary_pool", check: poolPressureCheck(db, cfg.PoolUtilizationThreshold, cfg.PoolWaitThreshold)},
+    }
+    if replicaDb != nil {
+        readinessChecks = append(readinessChecks,
+            readinessCheck{name: "replica_db", check: replicaDb.PingContext},
+            readinessCheck{name: "replica_pool", check: poolPressureCheck(replicaDb, cfg.PoolUtilizationThreshold, cfg.PoolWaitThreshold)},
+        )
+    }
+    mux.HandleFunc("/ready", readyHandler(readinessChecks, cfg.ReadyCriticalChecks))
+
+    // Connection pool stats for the primary and (if configured) replica pools
+    mux.HandleFunc("/health/db", func(w http.ResponseWriter, r *http.Request) {
+        stats := map[string]interface{}{
+            "primary": poolStats(db),
+            "replica": nil,
         }
-      },
-      "TestProjectsInput": {
-        "type": "object",
-        "required": ["Name"],
-        "properties": {
-          "Name": {
-            "type": "string"
-          }
+        if replicaDb != nil {
+            stats["replica"] = poolStats(replicaDb)
         }
-      }
+        controllers.WriteJSON(w, http.StatusOK, stats)
+    })
+
+    // Swagger UI endpoint - serve interactive Swagger UI HTML page
+    mux.HandleFunc("/swagger", swaggerUIHandler(cfg.BasePath, cfg.SwaggerUseCDN))
+
+    // swagger-ui assets for /swagger, embedded in the binary
+    mux.Handle("/swagger-assets/", swaggerAssetsHandler())
+
+    // OpenAPI spec - the JSON (swagger_spec.go) is the single source; /swagger.yaml is derived from it
+    swaggerServerURL := cfg.BasePath
+    if swaggerServerURL == "" {
+        swaggerServerURL = "/"
+    }
+    swaggerJSON := openAPISpec(swaggerServerURL)
+    swaggerYAML, err := specToYAML(swaggerJSON)
+    if err != nil {
+        log.Fatalf("[STARTUP ERROR] Failed to convert the OpenAPI spec to YAML: %v", err)
     }
-  }
-}`)
+
+    // Swagger JSON endpoint - return OpenAPI spec as JSON
+    mux.HandleFunc("/swagger.json", func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", controllers.JSONContentType())
+        io.WriteString(w, swaggerJSON)
     })
 
-    // API routes handler function
+    // Same spec as YAML for tooling that doesn't read JSON
+    mux.HandleFunc("/swagger.yaml", func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "application/yaml")
+        w.Write(swaggerYAML)
+    })
+
+    // API routes handler function; keep apiTestSubpathMethods / routeMethods in step
+    // with the methods dispatched here
     apiTestHandler := func(w http.ResponseWriter, r *http.Request) {
         path := r.URL.Path
         
@@ -545,7 +760,11 @@ func main() {
         if path == "/api/test" || path == "/api/test/" {
             switch r.Method {
             case "GET":
-                controller.GetAll(w, r)
+                if r.URL.Query().Get("ids") != "" {
+                    controller.GetByIds(w, r)
+                } else {
+                    controller.GetAll(w, r)
+                }
             case "POST":
                 controller.Create(w, r)
             default:
@@ -561,7 +780,11 @@ func main() {
                 // Empty ID after /api/test/, treat as /api/test/
                 switch r.Method {
                 case "GET":
-                    controller.GetAll(w, r)
+                    if r.URL.Query().Get("ids") != "" {
+                        controller.GetByIds(w, r)
+                    } else {
+                        controller.GetAll(w, r)
+                    }
                 case "POST":
                     controller.Create(w, r)
                 default:
@@ -570,7 +793,106 @@ func main() {
                 return
             }
             
-            id, err := strconv.Atoi(idStr)
+            if idStr == "search" {
+                if !flags.Enabled("search") {
+                    http.NotFound(w, r)
+                    return
+                }
+                if r.Method == "GET" {
+                    controller.Search(w, r)
+                } else {
+                    http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+                }
+                return
+            }
+            
+            if idStr == "all" {
+                if r.Method == "DELETE" {
+                    controller.DeleteAll(w, r)
+                } else {
+                    http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+                }
+                return
+            }
+            
+            if idStr == "bulk" || idStr == "bulk/delete" {
+                if r.Method != "POST" {
+                    http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+                } else if idStr == "bulk" {
+                    controller.BulkCreate(w, r)
+                } else {
+                    controller.BulkDelete(w, r)
+                }
+                return
+            }
+            
+            if idStr == "import" {
+                if r.Method == "POST" {
+                    controller.Import(w, r)
+                } else {
+                    http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+                }
+                return
+            }
+            
+            if idStr == "stats" {
+                if r.Method == "GET" {
+                    controller.Stats(w, r)
+                } else {
+                    http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+                }
+                return
+            }
+            
+            if idStr == "by-name" {
+                if r.Method == "GET" {
+                    controller.GetByName(w, r)
+                } else {
+                    http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+                }
+                return
+            }
+            
+            if idStr == "rename" {
+                if r.Method == "PUT" {
+                    controller.Rename(w, r)
+                } else {
+                    http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+                }
+                return
+            }
+            
+            // Handle /api/test/:id/duplicate
+            if duplicateIdStr := strings.TrimSuffix(idStr, "/duplicate"); duplicateIdStr != idStr {
+                id, err := models.ParseID(duplicateIdStr)
+                if err != nil {
+                    http.Error(w, "Invalid ID", http.StatusBadRequest)
+                    return
+                }
+                if r.Method == "POST" {
+                    controller.Duplicate(w, r, id)
+                } else {
+                    http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+                }
+                return
+            }
+            
+            // Handle /api/test/:id/exists
+            if existsIdStr := strings.TrimSuffix(idStr, "/exists"); existsIdStr != idStr {
+                id, err := models.ParseID(existsIdStr)
+                if err != nil {
+                    http.Error(w, "Invalid ID", http.StatusBadRequest)
+                    return
+                }
+                if r.Method == "GET" || r.Method == "HEAD" {
+                    controller.Exists(w, r, id)
+                } else {
+                    http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+                }
+                return
+            }
+            
+            id, err := models.ParseID(idStr)
             if err != nil {
                 http.Error(w, "Invalid ID", http.StatusBadRequest)
                 return
@@ -581,6 +903,8 @@ func main() {
                 controller.GetById(w, r, id)
             case "PUT":
                 controller.Update(w, r, id)
+            case "PATCH":
+                controller.Patch(w, r, id)
             case "DELETE":
                 controller.Delete(w, r, id)
             default:
@@ -596,16 +920,62 @@ func main() {
     mux.HandleFunc("/api/test", apiTestHandler)
     mux.HandleFunc("/api/test/", apiTestHandler)
 
-    // Apply panic recovery middleware FIRST, then CORS middleware
-    // Note: handler is already declared above, so use assignment instead of declaration
-    handler = panicRecoveryMiddleware(corsMiddleware(mux))
+    // The middleware chain is built once, after every route is registered. Panic recovery
+    // is outermost so a panic anywhere - in a handler or in any middleware - is recovered
+    // and reported; only the body capture for its reports (PANIC_REPORT_BODY_BYTES) sits
+    // outside it, so the request it sees carries the copy. Then: resolve the client IP and
+    // attach the request-scoped logger so every layer can log with them, the problem+json
+    // error rewriting (so it covers the errors of every layer below), the access log and
+    // response timing, the path length limit, BASE_PATH stripping so everything below sees
+    // root-relative paths, the HTTPS redirect, compression, idle tracking, CORS, the
+    // per-board rate limit and the concurrency limiter (inside CORS so browsers can read
+    // their 429s and 503s), the response time budget, and last the body checks. The pool
+    // acquisition timeout is not a layer: handlers put it on their own database calls
+    // (TestController.AcquireTimeout).
+    handler := Chain(mux,
+        func(h http.Handler) http.Handler { return panicBodyCaptureMiddleware(h, cfg.PanicReportBodyBytes, cfg.LogRedactParams) },
+        func(h http.Handler) http.Handler { return panicRecoveryMiddleware(h, panicDb, recentPanics, cfg.PanicReportSampleRate, cfg.StackTrace, cfg.ExposePanicDetails) },
+        func(h http.Handler) http.Handler { return clientIPMiddleware(h, cfg.TrustedProxies) },
+        func(h http.Handler) http.Handler { return requestLoggerMiddleware(h) },
+        func(h http.Handler) http.Handler { return problemDetailsMiddleware(h, cfg.ProblemDetails) },
+        func(h http.Handler) http.Handler { return accessLogMiddleware(h, cfg.LogQuery, cfg.LogRedactParams) },
+        responseTimeMiddleware,
+        func(h http.Handler) http.Handler { return maxPathLengthMiddleware(h, cfg.MaxPathLength) },
+        func(h http.Handler) http.Handler { return basePathMiddleware(h, cfg.BasePath) },
+        func(h http.Handler) http.Handler {
+            if !cfg.ForceHTTPS {
+                return h
+            }
+            return forceHTTPSMiddleware(h, cfg.HSTSMaxAgeSeconds)
+        },
+        func(h http.Handler) http.Handler { return gzipMiddleware(h, cfg.GzipMinBytes) },
+        idle.middleware,
+        func(h http.Handler) http.Handler { return corsMiddleware(h, cfg.CORSMaxAgeSeconds) },
+        func(h http.Handler) http.Handler { return boardRateLimitMiddleware(h, cfg.BoardRateLimitRPS, cfg.BoardRateLimitBurst, cfg.IPRateLimitRPS, cfg.IPRateLimitBurst) },
+        func(h http.Handler) http.Handler { return concurrencyLimitMiddleware(h, cfg.MaxInflightRequests) },
+        func(h http.Handler) http.Handler { return responseBudgetMiddleware(h, cfg.MaxResponseTime) },
+        func(h http.Handler) http.Handler { return rejectBodyMiddleware(h, cfg.LenientRequestBody) },
+    )
 
-    port := os.Getenv("PORT")
-    if port == "" {
-        port = "8080"
+    // Serve returns as soon as shutdown begins; main waits on shutdownDone so the
+    // deferred cleanup only runs once the whole sequence has finished
+    shutdownDone := make(chan struct{})
+    var shutdownOnce sync.Once
+    beginShutdown := func() {
+        shutdownOnce.Do(func() {
+            go func() {
+                gracefulShutdown(server, cfg.ShutdownDrainTimeout, db, replicaDb)
+                close(shutdownDone)
+            }()
+        })
+    }
+    
+    if cfg.IdleShutdown > 0 {
+        go idle.watch(cfg.IdleShutdown, func(idleFor time.Duration) {
+            log.Printf("[IDLE SHUTDOWN] No requests for %s (IDLE_SHUTDOWN_SECONDS=%d), shutting down", idleFor.Round(time.Second), int(cfg.IdleShutdown.Seconds()))
+            beginShutdown()
+        })
     }
-
-    log.Printf("Server starting on 0.0.0.0:%s", port)
     
     // Declare variables for startup error handling (used in defer and error handler)
     runtimeErrorEndpointUrl := os.Getenv("RUNTIME_ERROR_ENDPOINT_URL")
@@ -614,7 +984,7 @@ func main() {
     // Startup error handler
     defer func() {
         if r := recover(); r != nil {
-            log.Printf("[STARTUP ERROR] Application failed to start: %v", r)
+            log.Printf("[STARTUP ERROR] Application failed to start: %s", redactDSN(fmt.Sprintf("%v", r)))
             
             // Send startup error to endpoint (fire and forget)
             if runtimeErrorEndpointUrl != "" {
@@ -696,9 +1066,8 @@ func main() {
                     }
                     
                     req.Header.Set("Content-Type", "application/json")
-                    client := &http.Client{Timeout: 5 * time.Second}
                     
-                    client.Do(req) // Fire and forget
+                    errorReportClient.Do(req) // Fire and forget
                 }()
             }
             
@@ -706,8 +1075,25 @@ func main() {
         }
     }()
     
-    if err = http.ListenAndServe("0.0.0.0:"+port, handler); err != nil {
-        log.Printf("[STARTUP ERROR] Server failed to start: %v", err)
+    signals := make(chan os.Signal, 1)
+    signal.Notify(signals, shutdownSignals...)
+    go func() {
+        sig := <-signals
+        log.Printf("[SHUTDOWN] Received %s", sig)
+        beginShutdown()
+    }()
+    
+    gate.open(handler)
+    log.Printf("Database ready - serving requests on %s", bindAddr)
+    
+    err = <-serveErr
+    if err == http.ErrServerClosed {
+        <-shutdownDone
+        log.Printf("Server stopped")
+        return
+    }
+    if err != nil {
+        log.Printf("[STARTUP ERROR] Server failed to start: %s", redactErr(err))
         
         // Send startup error to endpoint (same as above)
         // Note: runtimeErrorEndpointUrl and boardId are already declared above
@@ -790,9 +1176,8 @@ func main() {
                 }
                 
                 req.Header.Set("Content-Type", "application/json")
-                client := &http.Client{Timeout: 5 * time.Second}
                 
-                client.Do(req) // Fire and forget
+                errorReportClient.Do(req) // Fire and forget
             }()
         }
         
@@ -0,0 +1,26 @@
+package main
+
+import (
+    "fmt"
+    "net/http"
+    
+    "backend/Controllers"
+)
+
+// metricsHandler serves GET /metrics: the controller operation counters and the
+// process-wide counters and gauges, in the Prometheus text exposition format
+func metricsHandler(metrics *controllers.OperationMetrics) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+        metrics.WritePrometheus(w)
+        fmt.Fprintln(w, "# HELP backend_panic_reports_dropped_total Panics not sent to the error endpoint due to sampling or a full report queue.")
+        fmt.Fprintln(w, "# TYPE backend_panic_reports_dropped_total counter")
+        fmt.Fprintf(w, "backend_panic_reports_dropped_total %d\n", droppedPanicReports.Load())
+        fmt.Fprintln(w, "# HELP backend_slow_request_violations_total Requests still running when MAX_RESPONSE_MS expired.")
+        fmt.Fprintln(w, "# TYPE backend_slow_request_violations_total counter")
+        fmt.Fprintf(w, "backend_slow_request_violations_total %d\n", slowRequestViolations.Load())
+        fmt.Fprintln(w, "# HELP backend_rate_limit_buckets Token buckets currently held by the board and client IP rate limits.")
+        fmt.Fprintln(w, "# TYPE backend_rate_limit_buckets gauge")
+        fmt.Fprintf(w, "backend_rate_limit_buckets %d\n", rateLimitBuckets.Load())
+    }
+}
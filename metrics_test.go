@@ -0,0 +1,65 @@
+package main
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "regexp"
+    "strings"
+    "testing"
+    
+    "backend/Controllers"
+)
+
+func TestMetricsExpositionIsWellFormed(t *testing.T) {
+    metrics := controllers.NewOperationMetrics()
+    metrics.Inc("GetAll", "success")
+    metrics.Inc("Delete", "conflict")
+    
+    w := httptest.NewRecorder()
+    metricsHandler(metrics).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+    if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+        t.Errorf("Content-Type = %q, want the Prometheus text format", ct)
+    }
+    
+    help := regexp.MustCompile(`^# HELP ([a-z_]+) \S.*$`)
+    typ := regexp.MustCompile(`^# TYPE ([a-z_]+) (counter|gauge)$`)
+    sample := regexp.MustCompile(`^([a-z_]+)(\{[a-z]+="[^"]*"(,[a-z]+="[^"]*")*\})? [0-9]+$`)
+    
+    // Every sample must follow the HELP and TYPE lines of its metric
+    typed := map[string]bool{}
+    described := map[string]bool{}
+    samples := map[string]int{}
+    for _, line := range strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n") {
+        if m := help.FindStringSubmatch(line); m != nil {
+            described[m[1]] = true
+            continue
+        }
+        if m := typ.FindStringSubmatch(line); m != nil {
+            if !described[m[1]] {
+                t.Errorf("TYPE before HELP for %s", m[1])
+            }
+            typed[m[1]] = true
+            continue
+        }
+        m := sample.FindStringSubmatch(line)
+        if m == nil {
+            t.Errorf("malformed line %q", line)
+            continue
+        }
+        if !typed[m[1]] {
+            t.Errorf("sample of %s before its TYPE line", m[1])
+        }
+        samples[m[1]]++
+    }
+    
+    for name, want := range map[string]int{
+        "backend_operations_total":              2,
+        "backend_panic_reports_dropped_total":   1,
+        "backend_slow_request_violations_total": 1,
+        "backend_rate_limit_buckets":            1,
+    } {
+        if samples[name] != want {
+            t.Errorf("%d samples of %s, want %d", samples[name], name, want)
+        }
+    }
+}
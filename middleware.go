@@ -0,0 +1,414 @@
+package main
+
+import (
+    "bufio"
+    "context"
+    "fmt"
+    "net"
+    "net/http"
+    "strconv"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "backend/Controllers"
+)
+
+// Middleware wraps a handler with additional behaviour
+type Middleware func(http.Handler) http.Handler
+
+// Chain applies middleware to h so that they run in the order listed: the first
+// middleware is the outermost and sees the request first.
+func Chain(h http.Handler, middleware ...Middleware) http.Handler {
+    for i := len(middleware) - 1; i >= 0; i-- {
+        h = middleware[i](h)
+    }
+    return h
+}
+
+// wrappedResponseWriter lets middleware observe the status code and run a hook
+// just before the headers are sent, which is the last moment headers can change
+type wrappedResponseWriter struct {
+    http.ResponseWriter
+    status            int
+    wroteHeader       bool
+    beforeWriteHeader func(w http.ResponseWriter)
+}
+
+func (w *wrappedResponseWriter) WriteHeader(status int) {
+    if w.wroteHeader {
+        return
+    }
+    w.wroteHeader = true
+    w.status = status
+    if w.beforeWriteHeader != nil {
+        w.beforeWriteHeader(w.ResponseWriter)
+    }
+    w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *wrappedResponseWriter) Write(b []byte) (int, error) {
+    if !w.wroteHeader {
+        w.WriteHeader(http.StatusOK)
+    }
+    return w.ResponseWriter.Write(b)
+}
+
+// Flush passes through to the underlying writer when it supports streaming (a no-op
+// otherwise), sending the headers first so beforeWriteHeader still runs
+func (w *wrappedResponseWriter) Flush() {
+    if !w.wroteHeader {
+        w.WriteHeader(http.StatusOK)
+    }
+    if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+        flusher.Flush()
+    }
+}
+
+// Hijack passes through to the underlying writer, failing if it can't be hijacked
+func (w *wrappedResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+    hijacker, ok := w.ResponseWriter.(http.Hijacker)
+    if !ok {
+        return nil, nil, fmt.Errorf("%T does not support hijacking", w.ResponseWriter)
+    }
+    return hijacker.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *wrappedResponseWriter) Unwrap() http.ResponseWriter {
+    return w.ResponseWriter
+}
+
+// responseTimeMiddleware sets X-Response-Time (milliseconds) on every response.
+// The duration is measured up to the moment the headers are written, since the
+// header can't be changed after that point.
+func responseTimeMiddleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        start := time.Now()
+        setResponseTime := func(w http.ResponseWriter) {
+            elapsed := float64(time.Since(start).Microseconds()) / 1000
+            w.Header().Set("X-Response-Time", strconv.FormatFloat(elapsed, 'f', 3, 64))
+        }
+
+        ww := &wrappedResponseWriter{ResponseWriter: w, beforeWriteHeader: setResponseTime}
+        next.ServeHTTP(ww, r)
+
+        // Handlers that never write anything still get an (empty) 200 response
+        if !ww.wroteHeader {
+            ww.WriteHeader(http.StatusOK)
+        }
+    })
+}
+
+// requestLoggerMiddleware gives every request an id (the client's X-Request-Id, or a
+// new one, echoed back on the response) and stores a logger carrying the request's
+// fields in its context; see controllers.LoggerFromContext
+func requestLoggerMiddleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requestId := r.Header.Get("X-Request-Id")
+        if requestId == "" {
+            requestId = controllers.NewRequestId()
+            r.Header.Set("X-Request-Id", requestId)
+        }
+        w.Header().Set("X-Request-Id", requestId)
+        
+        logger := controllers.NewRequestLogger(r, requestId, controllers.BoardIDFromContext(r.Context()))
+        next.ServeHTTP(w, r.WithContext(controllers.WithLogger(r.Context(), logger)))
+    })
+}
+
+// accessLogMiddleware logs one line per request with its status and duration. The
+// query string is only included when logQuery is set, with the values of the redact
+// parameters masked, since it can carry tokens or personal data.
+func accessLogMiddleware(next http.Handler, logQuery bool, redact map[string]bool) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        start := time.Now()
+        ww := &wrappedResponseWriter{ResponseWriter: w}
+        
+        // Deferred so requests that panic are logged too: the panic passes through
+        // here on its way to the (outermost) recovery middleware, which answers 500
+        completed := false
+        defer func() {
+            target := r.URL.Path
+            if logQuery && r.URL.RawQuery != "" {
+                target += "?" + redactQuery(r.URL.RawQuery, redact)
+            }
+            status := ww.status
+            switch {
+            case !completed:
+                status = http.StatusInternalServerError
+            case !ww.wroteHeader:
+                status = http.StatusOK
+            }
+            controllers.LoggerFromContext(r.Context()).Printf("[ACCESS] %s %s %d %.3fms (client %s)", r.Method, target, status,
+                float64(time.Since(start).Microseconds())/1000, clientIP(r))
+        }()
+        
+        next.ServeHTTP(ww, r)
+        completed = true
+    })
+}
+
+// basePathMiddleware serves the routes under basePath (e.g. /backend/api/test) by
+// stripping the prefix before routing; anything outside it is a 404. An empty
+// basePath mounts the service at the root.
+func basePathMiddleware(next http.Handler, basePath string) http.Handler {
+    if basePath == "" {
+        return next
+    }
+    
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var path string
+        switch {
+        case r.URL.Path == basePath:
+            path = "/"
+        case strings.HasPrefix(r.URL.Path, basePath+"/"):
+            path = strings.TrimPrefix(r.URL.Path, basePath)
+        default:
+            http.NotFound(w, r)
+            return
+        }
+        
+        r2 := r.Clone(r.Context())
+        r2.URL.Path = path
+        r2.URL.RawPath = ""
+        next.ServeHTTP(w, r2)
+    })
+}
+
+// maxPathLengthMiddleware answers 414 for request paths longer than maxLen bytes
+// (MAX_PATH_LENGTH) before any routing or parsing work is done on them. maxLen <= 0
+// disables the check.
+func maxPathLengthMiddleware(next http.Handler, maxLen int) http.Handler {
+    if maxLen <= 0 {
+        return next
+    }
+    
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if len(r.URL.Path) > maxLen {
+            controllers.LoggerFromContext(r.Context()).Printf("[PATH LENGTH] Rejecting %s path of %d bytes from %s (limit %d)", r.Method, len(r.URL.Path), clientIP(r), maxLen)
+            controllers.WriteJSON(w, http.StatusRequestURITooLong, map[string]string{"error": "request path too long"})
+            return
+        }
+        next.ServeHTTP(w, r)
+    })
+}
+
+// isHealthPath reports whether the request targets a health/readiness probe,
+// which must keep answering even when the service is shedding load
+func isHealthPath(path string) bool {
+    return path == "/health" || strings.HasPrefix(path, "/health/") || path == "/ready"
+}
+
+// concurrencyLimitMiddleware bounds the number of requests handled at once using a
+// buffered channel as a semaphore. Requests over the limit get an immediate 503 with
+// a jittered Retry-After instead of queueing. A limit <= 0 disables the limiter.
+func concurrencyLimitMiddleware(next http.Handler, limit int) http.Handler {
+    if limit <= 0 {
+        return next
+    }
+    sem := make(chan struct{}, limit)
+    
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if isHealthPath(r.URL.Path) {
+            next.ServeHTTP(w, r)
+            return
+        }
+        
+        select {
+        case sem <- struct{}{}:
+            defer func() { <-sem }()
+            next.ServeHTTP(w, r)
+        default:
+            controllers.LoggerFromContext(r.Context()).Printf("[CONCURRENCY LIMIT] Rejecting %s %s from %s - %d requests already in flight", r.Method, r.URL.Path, clientIP(r), limit)
+            controllers.SetRetryAfter(w)
+            controllers.WriteJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Server is busy, please retry later"})
+        }
+    })
+}
+
+// slowRequestViolations counts requests still running when responseBudgetMiddleware's
+// budget expired (exposed on /metrics)
+var slowRequestViolations atomic.Int64
+
+// responseBudgetMiddleware caps the time an /api/ request may take (MAX_RESPONSE_MS).
+// The handler's context gets the budget as its deadline, so in-flight queries are
+// cancelled, and a request that hasn't started its response when the budget expires
+// is answered with a JSON 503 at once and logged. The handler still runs to
+// completion on the request's goroutine - whatever it writes after the 503 is
+// dropped - so the layers around it, the concurrency limiter included, only let go
+// of the request once it has really finished. budget <= 0 disables it.
+func responseBudgetMiddleware(next http.Handler, budget time.Duration) http.Handler {
+    if budget <= 0 {
+        return next
+    }
+    
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if !strings.HasPrefix(r.URL.Path, "/api/") {
+            next.ServeHTTP(w, r)
+            return
+        }
+        
+        ctx, cancel := context.WithTimeout(r.Context(), budget)
+        defer cancel()
+        r = r.WithContext(ctx)
+        
+        start := time.Now()
+        bw := &budgetWriter{ResponseWriter: w, header: w.Header().Clone()}
+        timer := time.AfterFunc(budget, bw.expire)
+        next.ServeHTTP(bw, r)
+        timer.Stop()
+        
+        if bw.finish() {
+            slowRequestViolations.Add(1)
+            controllers.LoggerFromContext(r.Context()).Printf("[SLOW REQUEST] %s %s from %s still running after %s (MAX_RESPONSE_MS=%d), took %s",
+                r.Method, r.URL.Path, clientIP(r), budget, budget.Milliseconds(), time.Since(start).Round(time.Millisecond))
+        }
+    })
+}
+
+// budgetWriter guards a response against responseBudgetMiddleware's timer. The
+// handler works on its own copy of the headers, so the timer can write the 503
+// without racing it; once the 503 is out the handler's writes are dropped.
+type budgetWriter struct {
+    http.ResponseWriter
+    header http.Header
+    
+    mu          sync.Mutex
+    wroteHeader bool
+    // expired is set when the budget ran out while the handler was still running;
+    // timedOut when the 503 was sent because of it
+    expired  bool
+    timedOut bool
+    done     bool
+}
+
+func (w *budgetWriter) Header() http.Header {
+    return w.header
+}
+
+// writeHeaderLocked sends the handler's headers and status; w.mu must be held
+func (w *budgetWriter) writeHeaderLocked(status int) {
+    w.wroteHeader = true
+    dst := w.ResponseWriter.Header()
+    for key := range dst {
+        delete(dst, key)
+    }
+    for key, values := range w.header {
+        dst[key] = values
+    }
+    w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *budgetWriter) WriteHeader(status int) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if w.timedOut || w.wroteHeader {
+        return
+    }
+    w.writeHeaderLocked(status)
+}
+
+func (w *budgetWriter) Write(b []byte) (int, error) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if w.timedOut {
+        return 0, http.ErrHandlerTimeout
+    }
+    if !w.wroteHeader {
+        w.writeHeaderLocked(http.StatusOK)
+    }
+    return w.ResponseWriter.Write(b)
+}
+
+// Flush passes through to the underlying writer unless the 503 has been sent
+func (w *budgetWriter) Flush() {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if w.timedOut {
+        return
+    }
+    if !w.wroteHeader {
+        w.writeHeaderLocked(http.StatusOK)
+    }
+    if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+        flusher.Flush()
+    }
+}
+
+// Hijack passes through to the underlying writer unless the 503 has been sent; a
+// hijacked connection is the handler's, so the timer leaves it alone
+func (w *budgetWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if w.timedOut {
+        return nil, nil, http.ErrHandlerTimeout
+    }
+    hijacker, ok := w.ResponseWriter.(http.Hijacker)
+    if !ok {
+        return nil, nil, fmt.Errorf("%T does not support hijacking", w.ResponseWriter)
+    }
+    w.wroteHeader = true
+    return hijacker.Hijack()
+}
+
+// expire runs when the budget runs out. If the handler hasn't started its response
+// the 503 is sent and flushed now rather than when the handler returns.
+func (w *budgetWriter) expire() {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if w.done {
+        return
+    }
+    w.expired = true
+    if w.wroteHeader {
+        return
+    }
+    w.timedOut = true
+    controllers.SetRetryAfter(w.ResponseWriter)
+    controllers.WriteJSON(w.ResponseWriter, http.StatusServiceUnavailable, map[string]string{"error": "Request exceeded the response time budget, please retry later"})
+    if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+        flusher.Flush()
+    }
+}
+
+// finish marks the handler as returned and reports whether it overran the budget
+func (w *budgetWriter) finish() bool {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    w.done = true
+    return w.expired
+}
+
+// rejectBodyMiddleware returns 400 when a GET or DELETE request to /api/test carries a
+// body. Such bodies are otherwise silently ignored, which hides client bugs. lenient
+// restores the old behaviour.
+func rejectBodyMiddleware(next http.Handler, lenient bool) http.Handler {
+    if lenient {
+        return next
+    }
+    
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if (r.Method == http.MethodGet || r.Method == http.MethodDelete) && strings.HasPrefix(r.URL.Path, "/api/test") && hasBody(r) {
+            controllers.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "request body not allowed for this method"})
+            return
+        }
+        next.ServeHTTP(w, r)
+    })
+}
+
+// hasBody reports whether the request has a non-empty body. When the length is
+// unknown (chunked encoding) it reads one byte; the body is never used for these
+// methods, so consuming it is harmless.
+func hasBody(r *http.Request) bool {
+    if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+        return false
+    }
+    if r.ContentLength > 0 {
+        return true
+    }
+    var b [1]byte
+    n, _ := r.Body.Read(b[:])
+    return n > 0
+}
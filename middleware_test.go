@@ -0,0 +1,310 @@
+package main
+
+import (
+    "encoding/json"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+    
+    "backend/Models"
+)
+
+func TestChainRunsMiddlewareInListedOrder(t *testing.T) {
+    var calls []string
+    record := func(name string) Middleware {
+        return func(next http.Handler) http.Handler {
+            return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                calls = append(calls, name+" in")
+                next.ServeHTTP(w, r)
+                calls = append(calls, name+" out")
+            })
+        }
+    }
+    h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        calls = append(calls, "handler")
+    }), record("outer"), record("middle"), record("inner"))
+    
+    h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+    
+    want := []string{"outer in", "middle in", "inner in", "handler", "inner out", "middle out", "outer out"}
+    if !reflect.DeepEqual(calls, want) {
+        t.Errorf("calls = %v, want %v", calls, want)
+    }
+}
+
+func TestChainWithoutMiddlewareReturnsHandler(t *testing.T) {
+    called := false
+    h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+    h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+    if !called {
+        t.Error("handler not called")
+    }
+}
+
+func TestResponseBudgetAnswersJSON503WhileHandlerRuns(t *testing.T) {
+    release := make(chan struct{})
+    returned := make(chan struct{})
+    h := responseBudgetMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        defer close(returned)
+        <-release
+        w.Write([]byte("late"))
+    }), 50*time.Millisecond)
+    
+    server := httptest.NewServer(h)
+    defer server.Close()
+    defer close(release)
+    
+    start := time.Now()
+    resp, err := http.Get(server.URL + "/api/test")
+    if err != nil {
+        t.Fatalf("GET: %v", err)
+    }
+    defer resp.Body.Close()
+    if elapsed := time.Since(start); elapsed > time.Second {
+        t.Errorf("503 took %s, want about the 50ms budget", elapsed)
+    }
+    if resp.StatusCode != http.StatusServiceUnavailable {
+        t.Fatalf("status = %d, want 503", resp.StatusCode)
+    }
+    if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+        t.Errorf("Content-Type = %q, want JSON", ct)
+    }
+    if resp.Header.Get("Retry-After") == "" {
+        t.Error("Retry-After not set")
+    }
+    var body map[string]string
+    if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body["error"] == "" {
+        t.Errorf("body is not a JSON error (%v): %v", err, body)
+    }
+    select {
+    case <-returned:
+        t.Error("handler returned before it was released")
+    default:
+    }
+}
+
+func TestResponseBudgetCancelsHandlerContext(t *testing.T) {
+    var ctxErr error
+    h := responseBudgetMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        <-r.Context().Done()
+        ctxErr = r.Context().Err()
+    }), 20*time.Millisecond)
+    
+    w := httptest.NewRecorder()
+    h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/test", nil))
+    if ctxErr == nil {
+        t.Error("handler context was not cancelled at the budget")
+    }
+    if w.Code != http.StatusServiceUnavailable {
+        t.Errorf("status = %d, want 503", w.Code)
+    }
+}
+
+func TestResponseBudgetKeepsFlushAndHijack(t *testing.T) {
+    h := responseBudgetMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if _, ok := w.(http.Flusher); !ok {
+            t.Error("writer does not implement http.Flusher")
+        }
+        if _, ok := w.(http.Hijacker); !ok {
+            t.Error("writer does not implement http.Hijacker")
+        }
+        w.Header().Set("X-Handler", "yes")
+        w.WriteHeader(http.StatusAccepted)
+    }), time.Second)
+    
+    w := httptest.NewRecorder()
+    h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/test", nil))
+    if w.Code != http.StatusAccepted || w.Header().Get("X-Handler") != "yes" {
+        t.Errorf("got %d with X-Handler %q, want the handler's 202 and header", w.Code, w.Header().Get("X-Handler"))
+    }
+}
+
+func TestResponseBudgetHoldsConcurrencySlotUntilHandlerReturns(t *testing.T) {
+    release := make(chan struct{})
+    var once sync.Once
+    h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        <-release
+    }),
+        func(h http.Handler) http.Handler { return concurrencyLimitMiddleware(h, 1) },
+        func(h http.Handler) http.Handler { return responseBudgetMiddleware(h, 20*time.Millisecond) },
+    )
+    defer once.Do(func() { close(release) })
+    
+    first := make(chan int)
+    go func() {
+        w := httptest.NewRecorder()
+        h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/test", nil))
+        first <- w.Code
+    }()
+    time.Sleep(100 * time.Millisecond)
+    
+    // The first request is past its budget but its handler is still running
+    w := httptest.NewRecorder()
+    h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/test", nil))
+    if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "Server is busy") {
+        t.Errorf("second request got %d %q, want 503 from the concurrency limiter", w.Code, w.Body.String())
+    }
+    
+    once.Do(func() { close(release) })
+    if code := <-first; code != http.StatusServiceUnavailable {
+        t.Errorf("first request got %d, want 503 from the budget", code)
+    }
+}
+
+func TestBasePathMountsRoutesUnderPrefix(t *testing.T) {
+    mux := http.NewServeMux()
+    mux.HandleFunc("/api/test", func(w http.ResponseWriter, r *http.Request) {
+        io.WriteString(w, "routed "+r.URL.Path)
+    })
+    mux.HandleFunc("/swagger", swaggerUIHandler("/backend", false))
+    h := basePathMiddleware(mux, "/backend")
+    
+    serve := func(path string) *httptest.ResponseRecorder {
+        w := httptest.NewRecorder()
+        h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+        return w
+    }
+    
+    if w := serve("/backend/api/test"); w.Code != http.StatusOK || w.Body.String() != "routed /api/test" {
+        t.Errorf("/backend/api/test = %d %q, want it routed as /api/test", w.Code, w.Body)
+    }
+    for _, path := range []string{"/api/test", "/backendapi/test", "/other/api/test"} {
+        if w := serve(path); w.Code != http.StatusNotFound {
+            t.Errorf("%s = %d, want 404 outside the base path", path, w.Code)
+        }
+    }
+    
+    page := serve("/backend/swagger").Body.String()
+    for _, url := range []string{
+        `url: "/backend/swagger.json"`,
+        `href="/backend/swagger-assets/swagger-ui.css"`,
+        `src="/backend/swagger-assets/swagger-ui-bundle.js"`,
+        `src="/backend/swagger-assets/swagger-ui-standalone-preset.js"`,
+    } {
+        if !strings.Contains(page, url) {
+            t.Errorf("swagger page lacks %s", url)
+        }
+    }
+    if !strings.Contains(openAPISpec("/backend"), `"url": "/backend"`) {
+        t.Error("OpenAPI servers url doesn't carry the base path")
+    }
+}
+
+func TestBasePathEmptyMountsAtRoot(t *testing.T) {
+    next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+    if h := basePathMiddleware(next, ""); reflect.ValueOf(h).Pointer() != reflect.ValueOf(next).Pointer() {
+        t.Error("empty BASE_PATH wrapped the handler")
+    }
+}
+
+func TestRejectBodyMiddleware(t *testing.T) {
+    tests := []struct {
+        name    string
+        method  string
+        path    string
+        body    string
+        chunked bool
+        want    int
+    }{
+        {"GET with body", http.MethodGet, "/api/test", `{"Name":"x"}`, false, http.StatusBadRequest},
+        {"DELETE with body", http.MethodDelete, "/api/test/1", `{"Name":"x"}`, false, http.StatusBadRequest},
+        {"chunked GET with body", http.MethodGet, "/api/test", `{"Name":"x"}`, true, http.StatusBadRequest},
+        {"chunked GET without body", http.MethodGet, "/api/test", "", true, http.StatusOK},
+        {"GET without body", http.MethodGet, "/api/test", "", false, http.StatusOK},
+        {"POST with body", http.MethodPost, "/api/test", `{"Name":"x"}`, false, http.StatusOK},
+        {"GET with body outside the API", http.MethodGet, "/health", "x", false, http.StatusOK},
+    }
+    next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+            if tt.chunked {
+                req.ContentLength = -1
+                req.TransferEncoding = []string{"chunked"}
+            }
+            w := httptest.NewRecorder()
+            rejectBodyMiddleware(next, false).ServeHTTP(w, req)
+            if w.Code != tt.want {
+                t.Errorf("status = %d, want %d", w.Code, tt.want)
+            }
+        })
+    }
+}
+
+func TestRejectBodyMiddlewareLenientPassesBodies(t *testing.T) {
+    var body string
+    h := rejectBodyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        b, _ := io.ReadAll(r.Body)
+        body = string(b)
+    }), true)
+    
+    w := httptest.NewRecorder()
+    h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/test", strings.NewReader("ignored")))
+    if w.Code != http.StatusOK || body != "ignored" {
+        t.Errorf("status = %d, body seen %q; want 200 with the body untouched", w.Code, body)
+    }
+}
+
+func TestCORSPreflightListsRouteMethods(t *testing.T) {
+    tests := []struct {
+        path string
+        want string
+    }{
+        {"/api/test", "GET, POST, OPTIONS"},
+        {"/api/test/42", "GET, PUT, PATCH, DELETE, OPTIONS"},
+        {"/api/test/42/exists", "GET, HEAD, OPTIONS"},
+        {"/api/test/rename", "PUT, OPTIONS"},
+        {"/api/test/not-an-id", corsAllowedMethods},
+    }
+    h := corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        t.Errorf("preflight for %s reached the handler", r.URL.Path)
+    }), 600)
+    for _, tt := range tests {
+        w := httptest.NewRecorder()
+        h.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, tt.path, nil))
+        if w.Code != http.StatusOK {
+            t.Errorf("OPTIONS %s = %d, want 200", tt.path, w.Code)
+        }
+        if got := w.Header().Get("Allow"); got != tt.want {
+            t.Errorf("OPTIONS %s Allow = %q, want %q", tt.path, got, tt.want)
+        }
+        if got := w.Header().Get("Access-Control-Allow-Methods"); got != tt.want {
+            t.Errorf("OPTIONS %s Access-Control-Allow-Methods = %q, want %q", tt.path, got, tt.want)
+        }
+        if got := w.Header().Get("Access-Control-Max-Age"); got != "600" {
+            t.Errorf("OPTIONS %s Access-Control-Max-Age = %q, want 600", tt.path, got)
+        }
+    }
+}
+
+func TestMaxPathLengthRejectsLongIdSegment(t *testing.T) {
+    reached := false
+    h := maxPathLengthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        reached = true
+    }), 2048)
+    
+    w := httptest.NewRecorder()
+    h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/test/"+strings.Repeat("9", 10000), nil))
+    if w.Code != http.StatusRequestURITooLong {
+        t.Errorf("status = %d, want 414", w.Code)
+    }
+    if reached {
+        t.Error("an over-long path reached the handler")
+    }
+    
+    // Below the cap a long id still fails to parse, which the router answers with 400
+    w = httptest.NewRecorder()
+    longId := strings.Repeat("9", 100)
+    h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/test/"+longId, nil))
+    if !reached {
+        t.Error("a path within the cap was rejected")
+    }
+    if _, err := models.ParseID(longId); err == nil {
+        t.Errorf("ParseID accepted a %d digit id", len(longId))
+    }
+}
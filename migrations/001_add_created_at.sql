@@ -0,0 +1,6 @@
+-- Adds a creation timestamp to TestProjects, used by the createdAfter/createdBefore
+-- filters on GET /api/test/search. Existing rows get the time the migration ran.
+ALTER TABLE "TestProjects"
+    ADD COLUMN IF NOT EXISTS "CreatedAt" TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS "IX_TestProjects_CreatedAt" ON "TestProjects" ("CreatedAt");
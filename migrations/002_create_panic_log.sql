@@ -0,0 +1,13 @@
+-- Panics captured by the recovery middleware when PANIC_DB_LOG=true
+CREATE TABLE IF NOT EXISTS public.panic_log (
+    id          BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    path        TEXT NOT NULL,
+    method      TEXT NOT NULL,
+    message     TEXT NOT NULL,
+    file        TEXT,
+    line        INTEGER,
+    stack       TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS ix_panic_log_occurred_at ON public.panic_log (occurred_at DESC);
@@ -0,0 +1,9 @@
+-- Scopes project names to a board, used when NAME_UNIQUE_PER_BOARD=true. Inserts then
+-- record the request's board id and the unique index rejects a second project with
+-- the same name on the same board (409). Rows with no board (NULL, including every
+-- row that existed before this migration) are not constrained, since NULLs never
+-- compare equal in a unique index.
+ALTER TABLE "TestProjects"
+    ADD COLUMN IF NOT EXISTS "BoardId" TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS "UX_TestProjects_BoardId_Name" ON "TestProjects" ("BoardId", "Name");
@@ -0,0 +1,95 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "io"
+    "mime"
+    "net/http"
+    "regexp"
+    "sort"
+    "strings"
+)
+
+// maxPanicReportBodyBytes caps PANIC_REPORT_BODY_BYTES
+const maxPanicReportBodyBytes = 64 << 10
+
+// bodySnippetKey is the context key for a request's *bodySnippet
+type bodySnippetKey struct{}
+
+// bodySnippet is the start of a request body, kept for panic reports
+type bodySnippet struct {
+    data        []byte
+    truncated   bool
+    contentType string
+    redact      map[string]bool
+}
+
+// panicBodyCaptureMiddleware reads the first maxBytes of each request body
+// (PANIC_REPORT_BODY_BYTES) before the handler does, puts them back in front of the
+// rest of the body, and keeps the copy in the context so panic reports can include
+// it (requestBodySnippet). It sits outside panic recovery, whose request must
+// already carry the copy. maxBytes <= 0 disables it.
+func panicBodyCaptureMiddleware(next http.Handler, maxBytes int, redact map[string]bool) http.Handler {
+    if maxBytes <= 0 {
+        return next
+    }
+    
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Body == nil || r.Body == http.NoBody {
+            next.ServeHTTP(w, r)
+            return
+        }
+        
+        // One byte past the limit tells whether the snippet is truncated. A read error
+        // is left for the handler, which hits it again reading the rest of the body.
+        data, _ := io.ReadAll(io.LimitReader(r.Body, int64(maxBytes)+1))
+        r.Body = struct {
+            io.Reader
+            io.Closer
+        }{io.MultiReader(bytes.NewReader(data), r.Body), r.Body}
+        
+        snippet := &bodySnippet{data: data, contentType: r.Header.Get("Content-Type"), redact: redact}
+        if len(data) > maxBytes {
+            snippet.data, snippet.truncated = data[:maxBytes], true
+        }
+        next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), bodySnippetKey{}, snippet)))
+    })
+}
+
+// requestBodySnippet returns the redacted start of r's body, marked when truncated,
+// or "" when none was captured
+func requestBodySnippet(r *http.Request) string {
+    snippet, ok := r.Context().Value(bodySnippetKey{}).(*bodySnippet)
+    if !ok || len(snippet.data) == 0 {
+        return ""
+    }
+    
+    text := strings.ToValidUTF8(string(snippet.data), "")
+    if mediaType, _, _ := mime.ParseMediaType(snippet.contentType); mediaType == "application/x-www-form-urlencoded" {
+        text = redactQuery(text, snippet.redact)
+    } else {
+        text = redactJSONFields(text, snippet.redact)
+    }
+    text = redactDSN(text)
+    if snippet.truncated {
+        text += "...(truncated)"
+    }
+    return text
+}
+
+// redactJSONFields masks the values of the named fields (LOG_REDACT_PARAMS) in JSON
+// text with "***". It works on the text rather than a parsed document so a body cut
+// off mid-way is still redacted.
+func redactJSONFields(text string, redact map[string]bool) string {
+    if len(redact) == 0 {
+        return text
+    }
+    names := make([]string, 0, len(redact))
+    for name := range redact {
+        names = append(names, regexp.QuoteMeta(name))
+    }
+    sort.Strings(names)
+    pattern := regexp.MustCompile(`(?i)("(?:` + strings.Join(names, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]*)`)
+    return pattern.ReplaceAllString(text, `${1}"***"`)
+}
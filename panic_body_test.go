@@ -0,0 +1,92 @@
+package main
+
+import (
+    "encoding/json"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+var testRedactParams = map[string]bool{"password": true, "token": true}
+
+// snippetOf runs body through panicBodyCaptureMiddleware and returns the snippet a
+// panic report would carry, and what the handler itself read
+func snippetOf(body, contentType string, maxBytes int) (snippet, read string) {
+    h := panicBodyCaptureMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        data, _ := io.ReadAll(r.Body)
+        read = string(data)
+        snippet = requestBodySnippet(r)
+    }), maxBytes, testRedactParams)
+    req := httptest.NewRequest(http.MethodPost, "/api/test", strings.NewReader(body))
+    req.Header.Set("Content-Type", contentType)
+    h.ServeHTTP(httptest.NewRecorder(), req)
+    return snippet, read
+}
+
+func TestRequestBodySnippet(t *testing.T) {
+    tests := []struct {
+        name        string
+        body        string
+        contentType string
+        maxBytes    int
+        want        string
+    }{
+        {"JSON", `{"Name":"roadmap"}`, "application/json", 1024, `{"Name":"roadmap"}`},
+        {"JSON redacted", `{"Name":"x","password":"hunter2","Token": 123}`, "application/json", 1024, `{"Name":"x","password":"***","Token": "***"}`},
+        {"form redacted", "Name=x&password=hunter2", "application/x-www-form-urlencoded", 1024, "Name=x&password=***"},
+        {"truncated", `{"Name":"abcdefghij"}`, "application/json", 10, `{"Name":"a...(truncated)`},
+        {"redacted when cut mid-value", `{"password":"hunter2"}`, "application/json", 16, `{"password":"***"...(truncated)`},
+        {"exactly the limit", `{"Name":"x"}`, "application/json", 12, `{"Name":"x"}`},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            snippet, read := snippetOf(tt.body, tt.contentType, tt.maxBytes)
+            if snippet != tt.want {
+                t.Errorf("snippet = %q, want %q", snippet, tt.want)
+            }
+            // Capturing must not take anything away from the handler
+            if read != tt.body {
+                t.Errorf("handler read %q, want the whole body %q", read, tt.body)
+            }
+        })
+    }
+}
+
+func TestRequestBodySnippetDisabled(t *testing.T) {
+    if snippet, read := snippetOf(`{"Name":"x"}`, "application/json", 0); snippet != "" || read != `{"Name":"x"}` {
+        t.Errorf("with PANIC_REPORT_BODY_BYTES=0: snippet %q, read %q", snippet, read)
+    }
+}
+
+func TestPanicReportCarriesRedactedBody(t *testing.T) {
+    reports := make(chan map[string]interface{}, 1)
+    endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var report map[string]interface{}
+        json.NewDecoder(r.Body).Decode(&report)
+        reports <- report
+    }))
+    defer endpoint.Close()
+    t.Setenv("RUNTIME_ERROR_ENDPOINT_URL", endpoint.URL)
+    configureErrorReporting(time.Second, 4, 1)
+    
+    stack := stackLimits{initial: 8192, max: 1 << 20, report: 64 << 10}
+    h := panicBodyCaptureMiddleware(panicRecoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        io.ReadAll(r.Body)
+        panic("boom")
+    }), nil, nil, 1, stack, false), 1024, testRedactParams)
+    req := httptest.NewRequest(http.MethodPost, "/api/test", strings.NewReader(`{"Name":"x","password":"hunter2"}`))
+    req.Header.Set("Content-Type", "application/json")
+    h.ServeHTTP(httptest.NewRecorder(), req)
+    
+    select {
+    case report := <-reports:
+        if body := report["requestBody"]; body != `{"Name":"x","password":"***"}` {
+            t.Errorf("requestBody = %v, want the redacted body", body)
+        }
+    case <-time.After(5 * time.Second):
+        t.Fatal("no report sent")
+    }
+}
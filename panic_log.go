@@ -0,0 +1,54 @@
+package main
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "log"
+    "net/http"
+    "time"
+)
+
+// recordPanicToDb writes a panic_log row (see migrations/002_create_panic_log.sql)
+// in the background. It must never panic or block the response, so the insert is
+// queued for the error report workers (see enqueueErrorReport) and runs with a recover
+// and a bounded timeout. Sharing the workers keeps a burst of panics from opening a
+// goroutine and a pool connection per panic, and lets shutdown flush the inserts
+// before the pool is closed.
+func recordPanicToDb(db *sql.DB, r *http.Request, err interface{}, stackTrace string) {
+    // Copy what we need from the request now; it must not be used once the handler returns
+    path := r.URL.Path
+    method := r.Method
+    message := redactDSN(fmt.Sprintf("%v", err))
+    fileName, lineNumber := panicLocation(stackTrace)
+    occurredAt := time.Now().UTC()
+    
+    enqueueErrorReport(func() {
+        defer func() {
+            if rec := recover(); rec != nil {
+                log.Printf("[PANIC RECOVERY] Panic while writing panic_log: %v", rec)
+            }
+        }()
+        
+        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        defer cancel()
+        
+        var file sql.NullString
+        if fileName != "" {
+            file = sql.NullString{String: fileName, Valid: true}
+        }
+        var line sql.NullInt64
+        if lineNumber > 0 {
+            line = sql.NullInt64{Int64: int64(lineNumber), Valid: true}
+        }
+        
+        _, execErr := db.ExecContext(ctx,
+            `INSERT INTO public.panic_log (occurred_at, path, method, message, file, line, stack)
+             VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+            occurredAt, path, method, message, file, line, stackTrace,
+        )
+        if execErr != nil {
+            log.Printf("[PANIC RECOVERY] Failed to write panic_log: %s", redactErr(execErr))
+        }
+    })
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "sync/atomic"
+    "testing"
+    
+    "backend/Controllers"
+)
+
+// servePanic runs a request through panicRecoveryMiddleware around a handler that
+// panics with a message that must not leak in production
+func servePanic(t *testing.T, exposeDetails bool) (*httptest.ResponseRecorder, map[string]string) {
+    t.Helper()
+    t.Setenv("RUNTIME_ERROR_ENDPOINT_URL", "")
+    stack := stackLimits{initial: 8192, max: 1 << 20, report: 64 << 10}
+    h := panicRecoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        panic("lookup failed for postgres://app:s3cret@db/main")
+    }), nil, nil, 1, stack, exposeDetails)
+    
+    w := httptest.NewRecorder()
+    h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/test", nil))
+    var body map[string]string
+    if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+        t.Fatalf("body %q is not JSON: %v", w.Body.String(), err)
+    }
+    return w, body
+}
+
+func TestPanicRecoveryHidesDetailsByDefault(t *testing.T) {
+    w, body := servePanic(t, false)
+    
+    if w.Code != http.StatusInternalServerError {
+        t.Errorf("status = %d, want 500", w.Code)
+    }
+    if body["message"] != "An internal error occurred" {
+        t.Errorf("message = %q, want the generic message", body["message"])
+    }
+    if strings.Contains(w.Body.String(), "s3cret") || strings.Contains(w.Body.String(), "lookup failed") {
+        t.Errorf("response leaks the panic: %s", w.Body.String())
+    }
+}
+
+func TestPanicRecoveryExposesDetailsWhenEnabled(t *testing.T) {
+    _, body := servePanic(t, true)
+    if !strings.Contains(body["message"], "lookup failed") {
+        t.Errorf("message = %q, want the panic message", body["message"])
+    }
+}
+
+func TestLoadConfigExposePanicDetails(t *testing.T) {
+    t.Setenv("EXPOSE_PANIC_DETAILS", "")
+    if loadConfig().ExposePanicDetails {
+        t.Error("ExposePanicDetails defaults to true")
+    }
+    t.Setenv("EXPOSE_PANIC_DETAILS", "true")
+    if !loadConfig().ExposePanicDetails {
+        t.Error("ExposePanicDetails = false with EXPOSE_PANIC_DETAILS=true")
+    }
+}
+
+func TestSamplePanicReportBounds(t *testing.T) {
+    for i := 0; i < 1000; i++ {
+        if samplePanicReport(0) {
+            t.Fatal("a rate of 0 kept a report")
+        }
+        if !samplePanicReport(1) {
+            t.Fatal("a rate of 1 dropped a report")
+        }
+    }
+}
+
+func TestPanicRecoveryAtSampleRateZeroDropsEveryReport(t *testing.T) {
+    var reports atomic.Int64
+    endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        reports.Add(1)
+    }))
+    defer endpoint.Close()
+    t.Setenv("RUNTIME_ERROR_ENDPOINT_URL", endpoint.URL)
+    
+    stack := stackLimits{initial: 8192, max: 1 << 20, report: 64 << 10}
+    h := panicRecoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        panic("boom")
+    }), nil, nil, 0, stack, false)
+    
+    dropped := droppedPanicReports.Load()
+    for i := 0; i < 5; i++ {
+        h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/test", nil))
+    }
+    if n := droppedPanicReports.Load() - dropped; n != 5 {
+        t.Errorf("droppedPanicReports grew by %d, want 5", n)
+    }
+    if n := reports.Load(); n != 0 {
+        t.Errorf("%d reports reached the endpoint, want none", n)
+    }
+}
+
+func TestPanicRecoveryPutsBoardIdInContext(t *testing.T) {
+    t.Setenv("BOARD_ID", "")
+    const board = "0123456789abcdef01234567"
+    stack := stackLimits{initial: 8192, max: 1 << 20, report: 64 << 10}
+    var seen []string
+    h := panicRecoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        seen = append(seen, controllers.BoardIDFromContext(r.Context()))
+    }), nil, nil, 1, stack, false)
+    
+    for _, header := range []string{board, "malformed", ""} {
+        req := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+        if header != "" {
+            req.Header.Set("X-Board-Id", header)
+        }
+        h.ServeHTTP(httptest.NewRecorder(), req)
+    }
+    if want := []string{board, "", ""}; !reflect.DeepEqual(seen, want) {
+        t.Errorf("handler saw board ids %q, want %q", seen, want)
+    }
+}
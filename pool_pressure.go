@@ -0,0 +1,37 @@
+package main
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "sync"
+)
+
+// poolPressureCheck returns a readiness check that fails when the pool is close to
+// exhaustion: InUse/MaxOpen at or above utilization, or at least waitThreshold new
+// waits for a connection since the previous check (WaitCount is cumulative, so the
+// delta is what shows current pressure). Registered as non-critical it turns /ready
+// "degraded" as an early warning without taking the instance out of rotation.
+func poolPressureCheck(db *sql.DB, utilization float64, waitThreshold int) func(ctx context.Context) error {
+    var mu sync.Mutex
+    var lastWaitCount int64
+    
+    return func(ctx context.Context) error {
+        stats := db.Stats()
+        
+        mu.Lock()
+        newWaits := stats.WaitCount - lastWaitCount
+        lastWaitCount = stats.WaitCount
+        mu.Unlock()
+        
+        if stats.MaxOpenConnections > 0 && utilization > 0 {
+            if ratio := float64(stats.InUse) / float64(stats.MaxOpenConnections); ratio >= utilization {
+                return fmt.Errorf("pool saturated: %d of %d connections in use", stats.InUse, stats.MaxOpenConnections)
+            }
+        }
+        if waitThreshold > 0 && newWaits >= int64(waitThreshold) {
+            return fmt.Errorf("pool under pressure: %d waits for a connection since the last check", newWaits)
+        }
+        return nil
+    }
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "reflect"
+    "testing"
+    "time"
+)
+
+// holdConns checks out n connections from db until the test ends
+func holdConns(t *testing.T, db *sql.DB, n int) {
+    t.Helper()
+    for i := 0; i < n; i++ {
+        conn, err := db.Conn(context.Background())
+        if err != nil {
+            t.Fatalf("Conn: %v", err)
+        }
+        t.Cleanup(func() { conn.Close() })
+    }
+}
+
+// waitForConn makes one caller wait for a connection (and give up) on a full pool
+func waitForConn(db *sql.DB) {
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+    defer cancel()
+    if conn, err := db.Conn(ctx); err == nil {
+        conn.Close()
+    }
+}
+
+func TestPoolStatsShape(t *testing.T) {
+    db := sql.OpenDB(&recordingConnector{})
+    defer db.Close()
+    db.SetMaxOpenConns(4)
+    holdConns(t, db, 1)
+    
+    encoded, err := json.Marshal(poolStats(db))
+    if err != nil {
+        t.Fatalf("Marshal: %v", err)
+    }
+    var got map[string]interface{}
+    json.Unmarshal(encoded, &got)
+    want := map[string]interface{}{
+        "maxOpenConnections": float64(4),
+        "openConnections":    float64(1),
+        "inUse":              float64(1),
+        "idle":               float64(0),
+        "waitCount":          float64(0),
+        "waitDurationMs":     float64(0),
+        "utilization":        0.25,
+    }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("poolStats = %s, want %v", encoded, want)
+    }
+    
+    unbounded := sql.OpenDB(&recordingConnector{})
+    defer unbounded.Close()
+    if stats := poolStats(unbounded); stats["utilization"] != nil {
+        t.Errorf("utilization of an unbounded pool = %v, want null", stats["utilization"])
+    }
+}
+
+func TestPoolPressureCheckUtilization(t *testing.T) {
+    db := sql.OpenDB(&recordingConnector{})
+    defer db.Close()
+    db.SetMaxOpenConns(4)
+    check := poolPressureCheck(db, 0.75, 0)
+    
+    holdConns(t, db, 2)
+    if err := check(context.Background()); err != nil {
+        t.Errorf("2 of 4 in use: %v, want healthy", err)
+    }
+    holdConns(t, db, 1)
+    if err := check(context.Background()); err == nil {
+        t.Error("3 of 4 in use passed a 0.75 threshold")
+    }
+}
+
+func TestPoolPressureCheckCountsNewWaitsOnly(t *testing.T) {
+    db := sql.OpenDB(&recordingConnector{})
+    defer db.Close()
+    db.SetMaxOpenConns(1)
+    check := poolPressureCheck(db, 0, 2)
+    holdConns(t, db, 1)
+    
+    waitForConn(db)
+    if err := check(context.Background()); err != nil {
+        t.Errorf("1 wait: %v, want healthy below the threshold of 2", err)
+    }
+    waitForConn(db)
+    waitForConn(db)
+    if err := check(context.Background()); err == nil {
+        t.Error("2 new waits passed a threshold of 2")
+    }
+    // WaitCount is cumulative; without new waits the pool is healthy again
+    if err := check(context.Background()); err != nil {
+        t.Errorf("no new waits: %v, want healthy", err)
+    }
+}
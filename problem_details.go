@@ -0,0 +1,174 @@
+package main
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "log"
+    "net"
+    "net/http"
+    "strings"
+)
+
+// problemContentType is the RFC 7807 media type
+const problemContentType = "application/problem+json"
+
+// problemDetails is an RFC 7807 error body. Code, Id and Fields carry over the parts
+// of our own error envelope that have no standard member.
+type problemDetails struct {
+    Type      string          `json:"type"`
+    Title     string          `json:"title"`
+    Status    int             `json:"status"`
+    Detail    string          `json:"detail,omitempty"`
+    Instance  string          `json:"instance,omitempty"`
+    Code      string          `json:"code,omitempty"`
+    Id        json.RawMessage `json:"id,omitempty"`
+    Fields    json.RawMessage `json:"fields,omitempty"`
+    RequestId string          `json:"requestId,omitempty"`
+}
+
+// wantsProblemDetails reports whether the client asked for problem+json errors
+func wantsProblemDetails(r *http.Request) bool {
+    return strings.Contains(strings.ToLower(r.Header.Get("Accept")), problemContentType)
+}
+
+// problemDetailsMiddleware rewrites error responses (status >= 400) as RFC 7807
+// problem+json, for every request when always is set (PROBLEM_DETAILS) and otherwise
+// for clients that list application/problem+json in Accept. Handlers keep writing
+// their usual errors - the {"error": {...}} envelope, {"error": "..."} or plain
+// text - and the detail, code, id and field errors are taken from those.
+func problemDetailsMiddleware(next http.Handler, always bool) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if !always && !wantsProblemDetails(r) {
+            next.ServeHTTP(w, r)
+            return
+        }
+        
+        pw := &problemWriter{ResponseWriter: w, instance: r.URL.Path}
+        defer pw.finish()
+        next.ServeHTTP(pw, r)
+    })
+}
+
+// problemWriter holds back error responses so they can be rewritten once complete;
+// anything else passes straight through
+type problemWriter struct {
+    http.ResponseWriter
+    instance    string
+    wroteHeader bool
+    // capturing is set for an error status; the body is then collected in buf
+    capturing bool
+    status    int
+    buf       bytes.Buffer
+}
+
+func (w *problemWriter) WriteHeader(status int) {
+    if w.wroteHeader {
+        return
+    }
+    w.wroteHeader = true
+    // Compressed bodies can't be parsed; error bodies are too small to be compressed
+    // in practice, so they are left as they are
+    if status >= 400 && w.Header().Get("Content-Encoding") == "" {
+        w.capturing = true
+        w.status = status
+        return
+    }
+    w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *problemWriter) Write(b []byte) (int, error) {
+    if !w.wroteHeader {
+        w.WriteHeader(http.StatusOK)
+    }
+    if w.capturing {
+        return w.buf.Write(b)
+    }
+    return w.ResponseWriter.Write(b)
+}
+
+// finish sends the captured error as problem+json
+func (w *problemWriter) finish() {
+    if !w.capturing {
+        return
+    }
+    problem := problemDetails{
+        Type:      "about:blank",
+        Title:     http.StatusText(w.status),
+        Status:    w.status,
+        Instance:  w.instance,
+        RequestId: w.Header().Get("X-Request-Id"),
+    }
+    fillProblem(&problem, w.buf.Bytes())
+    
+    body, err := json.Marshal(problem)
+    if err != nil {
+        log.Printf("[RESPONSE ERROR] Failed to encode problem details (status %d): %v", w.status, err)
+        return
+    }
+    header := w.Header()
+    header.Set("Content-Type", problemContentType)
+    header.Del("Content-Length")
+    w.ResponseWriter.WriteHeader(w.status)
+    w.ResponseWriter.Write(append(body, '\n'))
+}
+
+// fillProblem takes the detail and extension members from an error body in one of
+// the shapes handlers write
+func fillProblem(problem *problemDetails, body []byte) {
+    var envelope struct {
+        Error   json.RawMessage `json:"error"`
+        Message string          `json:"message"`
+    }
+    if json.Unmarshal(body, &envelope) == nil && len(envelope.Error) > 0 {
+        var detail struct {
+            Code    string          `json:"code"`
+            Message string          `json:"message"`
+            Id      json.RawMessage `json:"id"`
+            Fields  json.RawMessage `json:"fields"`
+        }
+        var text string
+        switch {
+        case json.Unmarshal(envelope.Error, &detail) == nil:
+            problem.Detail = detail.Message
+            problem.Code = detail.Code
+            problem.Id = detail.Id
+            problem.Fields = detail.Fields
+        case json.Unmarshal(envelope.Error, &text) == nil:
+            problem.Detail = text
+            if envelope.Message != "" {
+                problem.Detail = fmt.Sprintf("%s: %s", text, envelope.Message)
+            }
+        }
+        return
+    }
+    problem.Detail = strings.TrimSpace(string(body))
+}
+
+// Flush is a no-op while an error is being captured; otherwise it passes through
+func (w *problemWriter) Flush() {
+    if w.capturing {
+        return
+    }
+    if !w.wroteHeader {
+        w.WriteHeader(http.StatusOK)
+    }
+    if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+        flusher.Flush()
+    }
+}
+
+// Hijack passes through to the underlying writer, failing if it can't be hijacked
+func (w *problemWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+    hijacker, ok := w.ResponseWriter.(http.Hijacker)
+    if !ok {
+        return nil, nil, fmt.Errorf("%T does not support hijacking", w.ResponseWriter)
+    }
+    return hijacker.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *problemWriter) Unwrap() http.ResponseWriter {
+    return w.ResponseWriter
+}
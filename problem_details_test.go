@@ -0,0 +1,109 @@
+package main
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    
+    "backend/Controllers"
+)
+
+// notFound writes the same body as the controllers' writeNotFound
+var notFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    controllers.WriteJSON(w, http.StatusNotFound, map[string]interface{}{
+        "error": map[string]interface{}{"code": "not_found", "message": "project not found", "id": 42},
+    })
+})
+
+func serveProblem(t *testing.T, h http.Handler, always bool, accept string) (*httptest.ResponseRecorder, map[string]interface{}) {
+    t.Helper()
+    req := httptest.NewRequest(http.MethodGet, "/api/test/42", nil)
+    if accept != "" {
+        req.Header.Set("Accept", accept)
+    }
+    w := httptest.NewRecorder()
+    problemDetailsMiddleware(h, always).ServeHTTP(w, req)
+    var body map[string]interface{}
+    if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+        t.Fatalf("body %q is not JSON: %v", w.Body, err)
+    }
+    return w, body
+}
+
+func TestProblemDetailsFromErrorEnvelope(t *testing.T) {
+    w, body := serveProblem(t, notFound, true, "")
+    if w.Code != http.StatusNotFound {
+        t.Errorf("status = %d, want 404", w.Code)
+    }
+    if ct := w.Header().Get("Content-Type"); ct != problemContentType {
+        t.Errorf("Content-Type = %q, want %q", ct, problemContentType)
+    }
+    want := map[string]interface{}{
+        "type":     "about:blank",
+        "title":    "Not Found",
+        "status":   float64(404),
+        "detail":   "project not found",
+        "instance": "/api/test/42",
+        "code":     "not_found",
+        "id":       float64(42),
+    }
+    for key, value := range want {
+        if body[key] != value {
+            t.Errorf("%s = %v, want %v", key, body[key], value)
+        }
+    }
+    if _, ok := body["error"]; ok {
+        t.Error("the original error envelope is still in the body")
+    }
+}
+
+func TestProblemDetailsFromPlainText(t *testing.T) {
+    h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        http.Error(w, "Invalid query: limit must be a positive integer", http.StatusBadRequest)
+    })
+    w, body := serveProblem(t, h, true, "")
+    if w.Code != http.StatusBadRequest {
+        t.Errorf("status = %d, want 400", w.Code)
+    }
+    if body["detail"] != "Invalid query: limit must be a positive integer" || body["title"] != "Bad Request" {
+        t.Errorf("body = %v, want the plain-text message as detail", body)
+    }
+}
+
+func TestProblemDetailsSwitching(t *testing.T) {
+    tests := []struct {
+        name    string
+        always  bool
+        accept  string
+        problem bool
+    }{
+        {"default", false, "", false},
+        {"plain JSON Accept", false, "application/json", false},
+        {"Accept problem+json", false, "application/problem+json", true},
+        {"Accept listing problem+json", false, "application/json, application/problem+json;q=0.9", true},
+        {"PROBLEM_DETAILS=true", true, "application/json", true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            w, body := serveProblem(t, notFound, tt.always, tt.accept)
+            isProblem := w.Header().Get("Content-Type") == problemContentType
+            if isProblem != tt.problem {
+                t.Errorf("problem+json = %v, want %v (body %v)", isProblem, tt.problem, body)
+            }
+            if _, ok := body["error"]; ok == tt.problem {
+                t.Errorf("error envelope present = %v, want %v", ok, !tt.problem)
+            }
+        })
+    }
+}
+
+func TestProblemDetailsLeavesSuccessAlone(t *testing.T) {
+    h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        controllers.WriteJSON(w, http.StatusOK, map[string]string{"Name": "ok"})
+    })
+    w, body := serveProblem(t, h, true, "")
+    if w.Code != http.StatusOK || body["Name"] != "ok" || w.Header().Get("Content-Type") == problemContentType {
+        t.Errorf("success rewritten: %d %v", w.Code, body)
+    }
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+    "math"
+    "net/http"
+    "strconv"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "backend/Controllers"
+)
+
+// rateBucketIdleTTL is how long an untouched bucket is kept; by then it has refilled,
+// so dropping it loses nothing
+const rateBucketIdleTTL = 10 * time.Minute
+
+// tokenBucket allows rps requests per second on average, in bursts of up to burst
+type tokenBucket struct {
+    tokens float64
+    last   time.Time
+}
+
+// rateLimitBuckets is the number of token buckets held across the rate limiters
+// (exposed on /metrics)
+var rateLimitBuckets atomic.Int64
+
+// boardRateLimiter keeps one token bucket per key (a board id or a client IP) and
+// drops buckets left idle for rateBucketIdleTTL
+type boardRateLimiter struct {
+    rps   float64
+    burst float64
+    
+    mu        sync.Mutex
+    buckets   map[string]*tokenBucket
+    lastSweep time.Time
+}
+
+func newBoardRateLimiter(rps float64, burst int) *boardRateLimiter {
+    return &boardRateLimiter{rps: rps, burst: float64(burst), buckets: make(map[string]*tokenBucket)}
+}
+
+// allow takes a token from key's bucket. When the bucket is empty it returns false
+// and how long until the next token.
+func (l *boardRateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    
+    if now.Sub(l.lastSweep) > time.Minute {
+        for k, b := range l.buckets {
+            if now.Sub(b.last) > rateBucketIdleTTL {
+                delete(l.buckets, k)
+                rateLimitBuckets.Add(-1)
+            }
+        }
+        l.lastSweep = now
+    }
+    
+    b, ok := l.buckets[key]
+    if !ok {
+        b = &tokenBucket{tokens: l.burst, last: now}
+        l.buckets[key] = b
+        rateLimitBuckets.Add(1)
+    }
+    b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rps)
+    b.last = now
+    if b.tokens >= 1 {
+        b.tokens--
+        return true, 0
+    }
+    return false, time.Duration((1 - b.tokens) / l.rps * float64(time.Second))
+}
+
+// boardRateLimitMiddleware answers 429 with Retry-After once a board exceeds rps
+// requests per second beyond its burst (BOARD_RATE_LIMIT_RPS, BOARD_RATE_LIMIT_BURST).
+// Every request also counts against its client IP's bucket (IP_RATE_LIMIT_RPS,
+// IP_RATE_LIMIT_BURST): the board id is chosen by the client, so without it a client
+// could get a fresh quota per request by sending a new board id each time. Boards
+// sharing an IP still get separate board quotas. Only /api/ requests are limited.
+// rps <= 0 disables the limiter.
+func boardRateLimitMiddleware(next http.Handler, rps float64, burst int, ipRps float64, ipBurst int) http.Handler {
+    if rps <= 0 {
+        return next
+    }
+    boards := newBoardRateLimiter(rps, burst)
+    ips := newBoardRateLimiter(ipRps, ipBurst)
+    
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if !strings.HasPrefix(r.URL.Path, "/api/") {
+            next.ServeHTTP(w, r)
+            return
+        }
+        
+        now := time.Now()
+        key := "ip:" + clientIP(r)
+        allowed, wait := ips.allow(key, now)
+        if boardId := controllers.BoardIDFromContext(r.Context()); allowed && boardId != "" {
+            key = "board:" + boardId
+            allowed, wait = boards.allow(key, now)
+        }
+        if !allowed {
+            controllers.LoggerFromContext(r.Context()).Printf("[RATE LIMIT] Rejecting %s %s for %s", r.Method, r.URL.Path, key)
+            w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+            controllers.WriteJSON(w, http.StatusTooManyRequests, map[string]string{"error": "Rate limit exceeded, please retry later"})
+            return
+        }
+        next.ServeHTTP(w, r)
+    })
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "testing"
+    "time"
+    
+    "backend/Controllers"
+)
+
+func TestBoardRateLimiterKeepsBoardsIndependent(t *testing.T) {
+    l := newBoardRateLimiter(1, 2)
+    now := time.Unix(1700000000, 0)
+    
+    for i := 0; i < 2; i++ {
+        if ok, _ := l.allow("board:aaaa", now); !ok {
+            t.Fatalf("request %d on board aaaa refused within the burst", i+1)
+        }
+    }
+    ok, wait := l.allow("board:aaaa", now)
+    if ok {
+        t.Fatal("request beyond the burst allowed")
+    }
+    if wait != time.Second {
+        t.Errorf("wait = %v, want 1s at 1 rps", wait)
+    }
+    
+    // Board aaaa being empty doesn't touch board bbbb's tokens
+    if ok, _ := l.allow("board:bbbb", now); !ok {
+        t.Error("board bbbb refused after board aaaa used its burst")
+    }
+    
+    // One token refills per second
+    if ok, _ := l.allow("board:aaaa", now.Add(time.Second)); !ok {
+        t.Error("board aaaa refused after refilling a token")
+    }
+}
+
+func TestBoardRateLimiterEvictsIdleBuckets(t *testing.T) {
+    l := newBoardRateLimiter(1, 1)
+    start := time.Unix(1700000000, 0)
+    before := rateLimitBuckets.Load()
+    
+    l.allow("board:idle", start)
+    l.allow("board:busy", start)
+    if n := rateLimitBuckets.Load() - before; n != 2 {
+        t.Fatalf("bucket gauge grew by %d, want 2", n)
+    }
+    
+    // Keep busy in use; idle is untouched past the TTL and goes at the next sweep
+    later := start.Add(rateBucketIdleTTL / 2)
+    l.allow("board:busy", later)
+    l.allow("board:busy", start.Add(rateBucketIdleTTL+2*time.Minute))
+    
+    if _, ok := l.buckets["board:idle"]; ok {
+        t.Error("idle bucket kept past rateBucketIdleTTL")
+    }
+    if _, ok := l.buckets["board:busy"]; !ok {
+        t.Error("bucket in use was evicted")
+    }
+    if n := rateLimitBuckets.Load() - before; n != 1 {
+        t.Errorf("bucket gauge is %d above its start, want 1", n)
+    }
+}
+
+func TestBoardRateLimitCapsClientsRotatingBoardIds(t *testing.T) {
+    ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+    h := boardRateLimitMiddleware(ok, 1, 5, 1, 3)
+    
+    codes := make([]int, 5)
+    for i := range codes {
+        r := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+        // A fresh board id every time: each board bucket is full
+        r = r.WithContext(controllers.WithBoardID(r.Context(), "b"+strconv.Itoa(i)))
+        w := httptest.NewRecorder()
+        h.ServeHTTP(w, r)
+        codes[i] = w.Code
+    }
+    
+    want := []int{200, 200, 200, 429, 429}
+    for i := range want {
+        if codes[i] != want[i] {
+            t.Fatalf("statuses = %v, want %v (the client IP's burst of 3)", codes, want)
+        }
+    }
+}
+
+func TestBoardRateLimitSharesNoQuotaBetweenBoards(t *testing.T) {
+    ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+    h := boardRateLimitMiddleware(ok, 1, 1, 1, 10)
+    serve := func(board string) int {
+        r := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+        r = r.WithContext(controllers.WithBoardID(r.Context(), board))
+        w := httptest.NewRecorder()
+        h.ServeHTTP(w, r)
+        return w.Code
+    }
+    
+    if code := serve("aaaa"); code != http.StatusOK {
+        t.Fatalf("first request on board aaaa = %d, want 200", code)
+    }
+    if code := serve("aaaa"); code != http.StatusTooManyRequests {
+        t.Errorf("second request on board aaaa = %d, want 429", code)
+    }
+    if code := serve("bbbb"); code != http.StatusOK {
+        t.Errorf("first request on board bbbb = %d, want 200", code)
+    }
+}
+
+func TestLoadConfigIPRateLimitDefaultsToBoardLimit(t *testing.T) {
+    t.Setenv("BOARD_RATE_LIMIT_RPS", "5")
+    t.Setenv("BOARD_RATE_LIMIT_BURST", "7")
+    t.Setenv("IP_RATE_LIMIT_RPS", "")
+    t.Setenv("IP_RATE_LIMIT_BURST", "")
+    cfg := loadConfig()
+    if cfg.IPRateLimitRPS != 5 || cfg.IPRateLimitBurst != 7 {
+        t.Errorf("IP limit = %v rps, burst %d; want the board's 5, 7", cfg.IPRateLimitRPS, cfg.IPRateLimitBurst)
+    }
+    
+    t.Setenv("IP_RATE_LIMIT_RPS", "50")
+    t.Setenv("IP_RATE_LIMIT_BURST", "100")
+    cfg = loadConfig()
+    if cfg.IPRateLimitRPS != 50 || cfg.IPRateLimitBurst != 100 {
+        t.Errorf("IP limit = %v rps, burst %d; want 50, 100", cfg.IPRateLimitRPS, cfg.IPRateLimitBurst)
+    }
+}
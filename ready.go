@@ -0,0 +1,92 @@
+package main
+
+import (
+    "context"
+    "net/http"
+    "strings"
+    "sync"
+    "time"
+
+    "backend/Controllers"
+)
+
+// readinessCheck is one subsystem reported by /ready
+type readinessCheck struct {
+    name  string
+    check func(ctx context.Context) error
+}
+
+// checkResult is the per-subsystem entry in the /ready response
+type checkResult struct {
+    Status    string  `json:"status"`
+    LatencyMs float64 `json:"latencyMs"`
+    Critical  bool    `json:"critical"`
+    Error     string  `json:"error,omitempty"`
+}
+
+// parseCriticalChecks parses READY_CRITICAL_CHECKS, e.g. "primary_db,replica_db"
+func parseCriticalChecks(raw string) map[string]bool {
+    critical := map[string]bool{}
+    for _, name := range strings.Split(raw, ",") {
+        if name = strings.TrimSpace(name); name != "" {
+            critical[name] = true
+        }
+    }
+    return critical
+}
+
+// readyHandler runs all checks concurrently and reports each one. Overall status is
+// "up" when everything passes, "degraded" when only non-critical checks fail, and
+// "down" (with a 503) when any critical check fails.
+func readyHandler(checks []readinessCheck, critical map[string]bool) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+        defer cancel()
+        
+        results := make(map[string]checkResult, len(checks))
+        var mu sync.Mutex
+        var wg sync.WaitGroup
+        for _, c := range checks {
+            wg.Add(1)
+            go func(c readinessCheck) {
+                defer wg.Done()
+                start := time.Now()
+                err := c.check(ctx)
+                result := checkResult{
+                    Status:    "up",
+                    LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+                    Critical:  critical[c.name],
+                }
+                if err != nil {
+                    result.Status = "down"
+                    result.Error = redactErr(err)
+                }
+                mu.Lock()
+                results[c.name] = result
+                mu.Unlock()
+            }(c)
+        }
+        wg.Wait()
+        
+        status := "up"
+        for _, result := range results {
+            if result.Status == "up" {
+                continue
+            }
+            if result.Critical {
+                status = "down"
+                break
+            }
+            status = "degraded"
+        }
+        
+        code := http.StatusOK
+        if status == "down" {
+            code = http.StatusServiceUnavailable
+        }
+        controllers.WriteJSON(w, code, map[string]interface{}{
+            "status": status,
+            "checks": results,
+        })
+    }
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+    "fmt"
+    "net/http"
+    "sync"
+    "time"
+
+    "backend/Controllers"
+)
+
+// panicRecord is one recovered panic as served by GET /admin/errors/recent
+type panicRecord struct {
+    OccurredAt time.Time `json:"occurredAt"`
+    Method     string    `json:"method"`
+    Path       string    `json:"path"`
+    BoardId    string    `json:"boardId,omitempty"`
+    Message    string    `json:"message"`
+    File       string    `json:"file,omitempty"`
+    Line       int       `json:"line,omitempty"`
+    StackTrace string    `json:"stackTrace"`
+}
+
+// panicRing keeps the last few recovered panics in memory, so recent crashes can be
+// inspected even when neither RUNTIME_ERROR_ENDPOINT_URL nor PANIC_DB_LOG is set.
+// A nil *panicRing (RECENT_PANICS_SIZE=0) records nothing.
+type panicRing struct {
+    mu      sync.Mutex
+    records []panicRecord
+    next    int
+    full    bool
+}
+
+func newPanicRing(size int) *panicRing {
+    if size <= 0 {
+        return nil
+    }
+    return &panicRing{records: make([]panicRecord, size)}
+}
+
+// record stores a recovered panic, overwriting the oldest once the ring is full
+func (p *panicRing) record(r *http.Request, boardId string, err interface{}, stackTrace string) {
+    if p == nil {
+        return
+    }
+    fileName, lineNumber := panicLocation(stackTrace)
+    rec := panicRecord{
+        OccurredAt: time.Now().UTC(),
+        Method:     r.Method,
+        Path:       r.URL.Path,
+        BoardId:    boardId,
+        Message:    redactDSN(fmt.Sprintf("%v", err)),
+        File:       fileName,
+        Line:       lineNumber,
+        StackTrace: stackTrace,
+    }
+    
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    p.records[p.next] = rec
+    p.next = (p.next + 1) % len(p.records)
+    if p.next == 0 {
+        p.full = true
+    }
+}
+
+// recent returns the stored panics, newest first
+func (p *panicRing) recent() []panicRecord {
+    if p == nil {
+        return []panicRecord{}
+    }
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    
+    count := p.next
+    if p.full {
+        count = len(p.records)
+    }
+    out := make([]panicRecord, 0, count)
+    for i := 1; i <= count; i++ {
+        out = append(out, p.records[(p.next-i+len(p.records))%len(p.records)])
+    }
+    return out
+}
+
+// recentPanicsHandler serves GET /admin/errors/recent (admin-only)
+func recentPanicsHandler(ring *panicRing) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if r.Method != "GET" {
+            http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+            return
+        }
+        if !requireAdmin(w, r) {
+            return
+        }
+        controllers.WriteJSON(w, http.StatusOK, map[string][]panicRecord{"panics": ring.recent()})
+    }
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+// recentPanics reads /admin/errors/recent as an admin
+func recentPanics(t *testing.T, ring *panicRing) []panicRecord {
+    t.Helper()
+    req := httptest.NewRequest(http.MethodGet, "/admin/errors/recent", nil)
+    req.Header.Set("Authorization", "Bearer s3cret")
+    w := httptest.NewRecorder()
+    recentPanicsHandler(ring).ServeHTTP(w, req)
+    if w.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+    }
+    var body struct {
+        Panics []panicRecord `json:"panics"`
+    }
+    if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+        t.Fatalf("body %q is not JSON: %v", w.Body, err)
+    }
+    return body.Panics
+}
+
+func TestRecentPanicsNewestFirstAndWrapping(t *testing.T) {
+    t.Setenv("ADMIN_TOKEN", "s3cret")
+    t.Setenv("RUNTIME_ERROR_ENDPOINT_URL", "")
+    ring := newPanicRing(3)
+    stack := stackLimits{initial: 8192, max: 1 << 20, report: 64 << 10}
+    h := panicRecoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        panic("failure on " + r.URL.Path)
+    }), nil, ring, 1, stack, false)
+    
+    fail := func(n int) {
+        req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/test/%d", n), nil)
+        req.Header.Set("X-Board-Id", "0123456789abcdef01234567")
+        h.ServeHTTP(httptest.NewRecorder(), req)
+    }
+    paths := func(records []panicRecord) []string {
+        var out []string
+        for _, rec := range records {
+            out = append(out, rec.Path)
+        }
+        return out
+    }
+    
+    if got := recentPanics(t, ring); len(got) != 0 {
+        t.Fatalf("recent panics before any = %v", paths(got))
+    }
+    
+    fail(1)
+    fail(2)
+    got := recentPanics(t, ring)
+    if fmt.Sprint(paths(got)) != "[/api/test/2 /api/test/1]" {
+        t.Errorf("before wrapping: %v, want newest first", paths(got))
+    }
+    if got[0].Message != "failure on /api/test/2" || got[0].Method != http.MethodGet || got[0].StackTrace == "" {
+        t.Errorf("record = %+v, want the panic message, method and stack", got[0])
+    }
+    if got[0].BoardId != "0123456789abcdef01234567" {
+        t.Errorf("boardId = %q, want the request's board", got[0].BoardId)
+    }
+    
+    // A full ring drops the oldest
+    fail(3)
+    fail(4)
+    fail(5)
+    if got := paths(recentPanics(t, ring)); fmt.Sprint(got) != "[/api/test/5 /api/test/4 /api/test/3]" {
+        t.Errorf("after wrapping: %v, want the last 3, newest first", got)
+    }
+}
+
+func TestRecentPanicsDisabled(t *testing.T) {
+    t.Setenv("ADMIN_TOKEN", "s3cret")
+    ring := newPanicRing(0)
+    ring.record(httptest.NewRequest(http.MethodGet, "/api/test", nil), "", "boom", "")
+    if got := recentPanics(t, ring); len(got) != 0 {
+        t.Errorf("RECENT_PANICS_SIZE=0 kept %d panics", len(got))
+    }
+}
+
+func TestRecentPanicsRequiresAdmin(t *testing.T) {
+    t.Setenv("ADMIN_TOKEN", "s3cret")
+    w := httptest.NewRecorder()
+    recentPanicsHandler(newPanicRing(3)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/errors/recent", nil))
+    if w.Code != http.StatusUnauthorized {
+        t.Errorf("without a token = %d, want 401", w.Code)
+    }
+}
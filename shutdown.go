@@ -0,0 +1,49 @@
+package main
+
+import (
+    "context"
+    "database/sql"
+    "log"
+    "net/http"
+    "os"
+    "syscall"
+    "time"
+)
+
+// shutdownSignals start a graceful shutdown
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// gracefulShutdown stops the server in order: stop accepting connections, wait up to
+// drainTimeout (SHUTDOWN_DRAIN_SECONDS) for in-flight requests, send the panic
+// reports still queued, and only then close the database pools, so a request that
+// was let in doesn't lose its database midway. Requests still running after the
+// drain timeout have their connections closed.
+func gracefulShutdown(server *http.Server, drainTimeout time.Duration, dbs ...*sql.DB) {
+    log.Printf("[SHUTDOWN] Stopping new connections, draining in-flight requests (up to %s)", drainTimeout)
+    ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+    defer cancel()
+    
+    start := time.Now()
+    if err := server.Shutdown(ctx); err != nil {
+        log.Printf("[SHUTDOWN] Requests still in flight after %s, closing their connections: %v", drainTimeout, err)
+        server.Close()
+    } else {
+        log.Printf("[SHUTDOWN] In-flight requests drained in %s", time.Since(start).Round(time.Millisecond))
+    }
+    
+    if pending := flushErrorReports(errorReportTimeout); pending > 0 {
+        log.Printf("[SHUTDOWN] %d error reports still unsent after %s, dropping them", pending, errorReportTimeout)
+    } else {
+        log.Printf("[SHUTDOWN] Error report queue flushed")
+    }
+    
+    for _, db := range dbs {
+        if db == nil {
+            continue
+        }
+        if err := db.Close(); err != nil {
+            log.Printf("[SHUTDOWN] Failed to close database pool: %v", err)
+        }
+    }
+    log.Printf("[SHUTDOWN] Database pools closed")
+}
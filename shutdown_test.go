@@ -0,0 +1,77 @@
+package main
+
+import (
+    "database/sql"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func TestGracefulShutdownDrainsRequestsBeforeClosingDB(t *testing.T) {
+    conn := &recordingConnector{}
+    db := sql.OpenDB(conn)
+    
+    started := make(chan struct{})
+    release := make(chan struct{})
+    ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        close(started)
+        <-release
+        // The pool must still be open for a request that was let in
+        if _, err := db.ExecContext(r.Context(), "SELECT 1"); err != nil {
+            http.Error(w, err.Error(), http.StatusInternalServerError)
+            return
+        }
+        io.WriteString(w, "done")
+    }))
+    defer ts.Close()
+    shuttingDown := make(chan struct{})
+    ts.Config.RegisterOnShutdown(func() { close(shuttingDown) })
+    
+    type result struct {
+        status int
+        body   string
+        err    error
+    }
+    results := make(chan result, 1)
+    go func() {
+        resp, err := http.Get(ts.URL)
+        if err != nil {
+            results <- result{err: err}
+            return
+        }
+        defer resp.Body.Close()
+        body, _ := io.ReadAll(resp.Body)
+        results <- result{status: resp.StatusCode, body: string(body)}
+    }()
+    <-started
+    
+    shutdownDone := make(chan struct{})
+    go func() {
+        gracefulShutdown(ts.Config, 5*time.Second, db)
+        close(shutdownDone)
+    }()
+    <-shuttingDown
+    
+    // Shutdown waits on the request in flight
+    select {
+    case <-shutdownDone:
+        t.Fatal("gracefulShutdown returned with a request in flight")
+    case <-time.After(50 * time.Millisecond):
+    }
+    close(release)
+    
+    res := <-results
+    if res.err != nil || res.status != http.StatusOK || res.body != "done" {
+        t.Fatalf("in-flight request = %d %q, %v; want it completed against an open pool", res.status, res.body, res.err)
+    }
+    select {
+    case <-shutdownDone:
+    case <-time.After(5 * time.Second):
+        t.Fatal("gracefulShutdown did not return after the request completed")
+    }
+    if err := db.Ping(); err == nil {
+        t.Error("database pool still open after gracefulShutdown")
+    }
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+    "fmt"
+    "runtime"
+)
+
+// stackLimits bounds the all-goroutine stack traces captured for panic reports
+type stackLimits struct {
+    // initial is the first buffer size tried (STACK_TRACE_BUFFER_BYTES)
+    initial int `env:"STACK_TRACE_BUFFER_BYTES"`
+    // max caps how far the buffer grows while capturing (STACK_TRACE_MAX_BYTES)
+    max int `env:"STACK_TRACE_MAX_BYTES"`
+    // report caps the trace sent in reports and stored in panic_log (STACK_TRACE_REPORT_BYTES)
+    report int `env:"STACK_TRACE_REPORT_BYTES"`
+}
+
+// captureStack returns the stacks of all goroutines. runtime.Stack silently stops at
+// the end of the buffer, so the buffer is doubled until the trace fits or reaches
+// limits.max; a trace that still doesn't fit is cut there with a marker.
+func captureStack(limits stackLimits) string {
+    size := limits.initial
+    for {
+        buf := make([]byte, size)
+        n := runtime.Stack(buf, true)
+        if n < size {
+            return string(buf[:n])
+        }
+        if size >= limits.max {
+            return string(buf[:n]) + fmt.Sprintf("\n... [stack truncated: exceeded %d bytes]\n", limits.max)
+        }
+        size *= 2
+        if size > limits.max {
+            size = limits.max
+        }
+    }
+}
+
+// truncateStack cuts a trace to limit bytes for reporting, with a marker saying how
+// much was dropped. The panicking goroutine is printed first, so it survives the cut.
+func truncateStack(stackTrace string, limit int) string {
+    if limit <= 0 || len(stackTrace) <= limit {
+        return stackTrace
+    }
+    return stackTrace[:limit] + fmt.Sprintf("\n... [stack truncated: %d of %d bytes shown]\n", limit, len(stackTrace))
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+    "strings"
+    "sync"
+    "testing"
+)
+
+// parkDeep blocks at the bottom of depth nested calls until release is closed, so
+// every parked goroutine adds a long stack to an all-goroutine trace
+func parkDeep(depth int, parked *sync.WaitGroup, release chan struct{}) {
+    if depth == 0 {
+        parked.Done()
+        <-release
+        return
+    }
+    parkDeep(depth-1, parked, release)
+}
+
+// withLargeStack parks enough goroutines to make the trace far bigger than 64KB
+func withLargeStack(t *testing.T) {
+    release := make(chan struct{})
+    var parked sync.WaitGroup
+    for i := 0; i < 50; i++ {
+        parked.Add(1)
+        go parkDeep(50, &parked, release)
+    }
+    parked.Wait()
+    t.Cleanup(func() { close(release) })
+}
+
+func TestCaptureStackGrowsBufferUntilTraceFits(t *testing.T) {
+    withLargeStack(t)
+    
+    trace := captureStack(stackLimits{initial: 1024, max: 16 << 20})
+    if len(trace) <= 64<<10 {
+        t.Fatalf("trace is %d bytes, want it grown well past the 1KB first buffer", len(trace))
+    }
+    if strings.Contains(trace, "stack truncated") {
+        t.Error("trace within the cap was truncated")
+    }
+    if n := strings.Count(trace, "parkDeep"); n < 50*50 {
+        t.Errorf("trace has %d parkDeep frames, want every parked goroutine", n)
+    }
+}
+
+func TestCaptureStackCutsAtMax(t *testing.T) {
+    withLargeStack(t)
+    
+    trace := captureStack(stackLimits{initial: 1024, max: 8192})
+    marker := "\n... [stack truncated: exceeded 8192 bytes]\n"
+    if !strings.HasSuffix(trace, marker) {
+        t.Errorf("trace ends %q, want the truncation marker", trace[len(trace)-80:])
+    }
+    if len(trace) != 8192+len(marker) {
+        t.Errorf("trace is %d bytes, want the 8192 byte cap plus the marker", len(trace))
+    }
+}
+
+func TestTruncateStack(t *testing.T) {
+    trace := strings.Repeat("x", 100)
+    tests := []struct {
+        limit int
+        want  string
+    }{
+        {0, trace},
+        {100, trace},
+        {200, trace},
+        {10, "xxxxxxxxxx\n... [stack truncated: 10 of 100 bytes shown]\n"},
+    }
+    for _, tt := range tests {
+        if got := truncateStack(trace, tt.limit); got != tt.want {
+            t.Errorf("truncateStack(100 bytes, %d) = %q, want %q", tt.limit, got, tt.want)
+        }
+    }
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+    "net/http"
+    "strings"
+    "sync/atomic"
+
+    "backend/Controllers"
+)
+
+// startupGate lets the server listen before the database is ready. Until open is
+// called, /health answers 200 (the process is alive), /ready and everything else
+// answer 503 with Retry-After, so orchestrators keep traffic away without
+// restarting a container that is only waiting on a slow database.
+type startupGate struct {
+    basePath string
+    handler  atomic.Pointer[http.Handler]
+}
+
+func newStartupGate(basePath string) *startupGate {
+    return &startupGate{basePath: basePath}
+}
+
+// open starts passing every request to h
+func (g *startupGate) open(h http.Handler) {
+    g.handler.Store(&h)
+}
+
+func (g *startupGate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    if h := g.handler.Load(); h != nil {
+        (*h).ServeHTTP(w, r)
+        return
+    }
+
+    // The gate sits outside basePathMiddleware, so it sees the full path
+    path := r.URL.Path
+    if g.basePath != "" {
+        path = strings.TrimPrefix(path, g.basePath)
+    }
+    if path == "/health" {
+        controllers.WriteJSON(w, http.StatusOK, map[string]string{"status": "healthy", "service": "Backend API"})
+        return
+    }
+    controllers.SetRetryAfter(w)
+    controllers.WriteJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Service is starting, please retry later"})
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func serveGate(g *startupGate, path string) *httptest.ResponseRecorder {
+    w := httptest.NewRecorder()
+    g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+    return w
+}
+
+func TestStartupGateBeforeOpen(t *testing.T) {
+    g := newStartupGate("/backend")
+    
+    for _, path := range []string{"/backend/api/test", "/backend/ready"} {
+        w := serveGate(g, path)
+        if w.Code != http.StatusServiceUnavailable {
+            t.Errorf("%s before open = %d, want 503", path, w.Code)
+        }
+        if w.Header().Get("Retry-After") == "" {
+            t.Errorf("%s before open has no Retry-After", path)
+        }
+    }
+    if w := serveGate(g, "/backend/health"); w.Code != http.StatusOK {
+        t.Errorf("/health before open = %d, want 200", w.Code)
+    }
+}
+
+func TestStartupGateAfterOpen(t *testing.T) {
+    g := newStartupGate("")
+    var served []string
+    g.open(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        served = append(served, r.URL.Path)
+        w.WriteHeader(http.StatusOK)
+    }))
+    
+    for _, path := range []string{"/api/test", "/health"} {
+        w := serveGate(g, path)
+        if w.Code != http.StatusOK {
+            t.Errorf("%s after open = %d, want 200", path, w.Code)
+        }
+        if w.Header().Get("Retry-After") != "" {
+            t.Errorf("%s after open still sends Retry-After", path)
+        }
+    }
+    // Once open, /health is the application's own, not the gate's
+    if len(served) != 2 {
+        t.Errorf("handler served %v, want both requests", served)
+    }
+}
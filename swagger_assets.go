@@ -0,0 +1,82 @@
+package main
+
+import (
+    "embed"
+    "fmt"
+    "io/fs"
+    "net/http"
+)
+
+// swaggerUIVersion is the swagger-ui-dist release embedded in swagger-assets/ and
+// loaded from the CDN when SWAGGER_USE_CDN is set
+const swaggerUIVersion = "5.18.2"
+
+// swaggerAssets are the swagger-ui-dist files the /swagger page needs, embedded so
+// the docs work where external CDNs are blocked
+//
+//go:embed swagger-assets/*.css swagger-assets/*.js
+var swaggerAssets embed.FS
+
+// swaggerAssetsHandler serves the embedded assets under /swagger-assets/. They only
+// change with a new build, so browsers may cache them for a day.
+func swaggerAssetsHandler() http.Handler {
+    assets, err := fs.Sub(swaggerAssets, "swagger-assets")
+    if err != nil {
+        panic(err)
+    }
+    files := http.StripPrefix("/swagger-assets/", http.FileServer(http.FS(assets)))
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Cache-Control", "public, max-age=86400")
+        files.ServeHTTP(w, r)
+    })
+}
+
+// swaggerAssetsBase is where the /swagger page loads swagger-ui from: the embedded
+// copy below basePath, or unpkg when useCDN is set
+func swaggerAssetsBase(basePath string, useCDN bool) string {
+    if useCDN {
+        return "https://unpkg.com/swagger-ui-dist@" + swaggerUIVersion
+    }
+    return basePath + "/swagger-assets"
+}
+
+// swaggerUIHandler serves the /swagger page for an API mounted at basePath
+func swaggerUIHandler(basePath string, useCDN bool) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "text/html")
+        fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head>
+    <title>Backend API - Swagger UI</title>
+    <link rel="stylesheet" type="text/css" href="%[2]s/swagger-ui.css" />
+    <style>
+        html { box-sizing: border-box; overflow: -moz-scrollbars-vertical; overflow-y: scroll; }
+        *, *:before, *:after { box-sizing: inherit; }
+        body { margin:0; background: #fafafa; }
+    </style>
+</head>
+<body>
+    <div id="swagger-ui"></div>
+    <script src="%[2]s/swagger-ui-bundle.js"></script>
+    <script src="%[2]s/swagger-ui-standalone-preset.js"></script>
+    <script>
+        window.onload = function() {
+            const ui = SwaggerUIBundle({
+                url: "%[1]s/swagger.json",
+                dom_id: "#swagger-ui",
+                deepLinking: true,
+                presets: [
+                    SwaggerUIBundle.presets.apis,
+                    SwaggerUIStandalonePreset
+                ],
+                plugins: [
+                    SwaggerUIBundle.plugins.DownloadUrl
+                ],
+                layout: "StandaloneLayout"
+            });
+        };
+    </script>
+</body>
+</html>`, basePath, swaggerAssetsBase(basePath, useCDN))
+    }
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestSwaggerAssetsServed(t *testing.T) {
+    tests := []struct {
+        path        string
+        contentType string
+    }{
+        {"/swagger-assets/swagger-ui-bundle.js", "javascript"},
+        {"/swagger-assets/swagger-ui-standalone-preset.js", "javascript"},
+        {"/swagger-assets/swagger-ui.css", "text/css"},
+    }
+    h := swaggerAssetsHandler()
+    for _, tt := range tests {
+        w := httptest.NewRecorder()
+        h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+        if w.Code != http.StatusOK {
+            t.Errorf("%s = %d, want 200", tt.path, w.Code)
+            continue
+        }
+        if got := w.Header().Get("Content-Type"); !strings.Contains(got, tt.contentType) {
+            t.Errorf("%s Content-Type = %q, want %s", tt.path, got, tt.contentType)
+        }
+        if w.Body.Len() == 0 {
+            t.Errorf("%s is empty", tt.path)
+        }
+        if got := w.Header().Get("Cache-Control"); got != "public, max-age=86400" {
+            t.Errorf("%s Cache-Control = %q", tt.path, got)
+        }
+    }
+    
+    w := httptest.NewRecorder()
+    h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger-assets/missing.js", nil))
+    if w.Code != http.StatusNotFound {
+        t.Errorf("missing asset = %d, want 404", w.Code)
+    }
+}
@@ -0,0 +1,1274 @@
+package main
+
+import (
+    "fmt"
+    
+    "backend/Models"
+)
+
+// openAPISpec returns the OpenAPI document served at /swagger.json, for an API
+// mounted at serverURL. Project keys are named the way responses emit them
+// (JSON_FIELD_CASE), so models.CamelCaseJSON must be set first.
+func openAPISpec(serverURL string) string {
+    return fmt.Sprintf(`{
+  "openapi": "3.0.0",
+  "info": {
+    "title": "Backend API",
+    "version": "1.0.0",
+    "description": "Go Backend API Documentation"
+  },
+  "servers": [
+    {
+      "url": "%s"
+    }
+  ],
+  "paths": {
+    "/api/test": {
+      "get": {
+        "summary": "Get all test projects",
+        "parameters": [
+          {
+            "name": "ids",
+            "in": "query",
+            "required": false,
+            "description": "Comma-separated list of ids to fetch (max 100); results keep the requested order",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "reportMissing",
+            "in": "query",
+            "required": false,
+            "description": "With ids, wrap the result as {items, missing} listing ids that were not found",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "limit",
+            "in": "query",
+            "required": false,
+            "description": "Page size; defaults to DEFAULT_PAGE_SIZE and values above MAX_PAGE_SIZE are clamped (the effective value is returned as limit)",
+            "schema": {
+              "type": "integer",
+              "minimum": 1,
+              "maximum": 500,
+              "default": 50
+            }
+          },
+          {
+            "name": "offset",
+            "in": "query",
+            "required": false,
+            "description": "Number of rows to skip",
+            "schema": {
+              "type": "integer",
+              "minimum": 0,
+              "default": 0
+            }
+          },
+          {
+            "name": "cursor",
+            "in": "query",
+            "required": false,
+            "description": "nextCursor of the previous page; use instead of offset",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "q",
+            "in": "query",
+            "required": false,
+            "description": "Case-insensitive substring match on Name",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "sort",
+            "in": "query",
+            "required": false,
+            "description": "Column to sort by",
+            "schema": {
+              "type": "string",
+              "enum": ["%[2]s", "%[3]s"],
+              "default": "%[2]s"
+            }
+          },
+          {
+            "name": "dir",
+            "in": "query",
+            "required": false,
+            "description": "Sort direction",
+            "schema": {
+              "type": "string",
+              "enum": ["asc", "desc"],
+              "default": "asc"
+            }
+          },
+          {
+            "name": "envelope",
+            "in": "query",
+            "required": false,
+            "description": "Wrap the page in {items, hasMore, total, limit, offset}; otherwise a bare array is returned with X-Has-More / X-Total-Count headers",
+            "schema": {
+              "type": "boolean",
+              "default": false
+            }
+          },
+          {
+            "name": "countMode",
+            "in": "query",
+            "required": false,
+            "description": "exact runs a COUNT for total, estimate uses the planner's estimate, none only reports hasMore",
+            "schema": {
+              "type": "string",
+              "enum": ["none", "exact", "estimate"],
+              "default": "none"
+            }
+          },
+          {
+            "name": "fields",
+            "in": "query",
+            "required": false,
+            "description": "Comma-separated subset of fields to return (%[2]s, %[3]s); JSON only",
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Test projects: a bare array by default or when ids is used, a TestProjectsPage with envelope=true",
+            "headers": {
+              "X-Has-More": {
+                "description": "Whether more rows follow this page (bare-array responses)",
+                "schema": {
+                  "type": "boolean"
+                }
+              },
+              "X-Next-Cursor": {
+                "description": "cursor for the following page, absent on the last page (bare-array responses)",
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "X-Total-Count": {
+                "description": "Matching rows when countMode is exact or estimate (bare-array responses)",
+                "schema": {
+                  "type": "integer"
+                }
+              }
+            },
+            "content": {
+              "application/json": {
+                "schema": {
+                  "oneOf": [
+                    {
+                      "type": "array",
+                      "items": {
+                        "$ref": "#/components/schemas/TestProjects"
+                      }
+                    },
+                    {
+                      "$ref": "#/components/schemas/TestProjectsPage"
+                    }
+                  ]
+                }
+              },
+              "application/xml": {
+                "schema": {
+                  "$ref": "#/components/schemas/TestProjectsPage"
+                }
+              },
+              "application/vnd.api+json": {
+                "schema": {
+                  "type": "object",
+                  "description": "JSON:API document: data is an array of {type, id, attributes}, meta holds hasMore/limit/offset and total when counted"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid query parameter"
+          },
+          "406": {
+            "description": "Accept header does not allow JSON, JSON:API or XML"
+          }
+        }
+      },
+      "post": {
+        "summary": "Create a new test project",
+        "parameters": [
+          {
+            "name": "getOrCreate",
+            "in": "query",
+            "required": false,
+            "description": "Return the existing project (200) instead of 409 when the name is taken on the request's board. Only supported with NAME_UNIQUE_PER_BOARD and a board id; otherwise 400",
+            "schema": {
+              "type": "boolean",
+              "default": false
+            }
+          }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/TestProjectsInput"
+              }
+            },
+            "application/x-www-form-urlencoded": {
+              "schema": {
+                "$ref": "#/components/schemas/TestProjectsInput"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "getOrCreate=true: a project with this name already existed and is returned",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/TestProjects"
+                }
+              }
+            }
+          },
+          "201": {
+            "description": "Created test project",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/TestProjects"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "Validation failed; every problem is listed",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ValidationError"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid getOrCreate value, or getOrCreate=true without NAME_UNIQUE_PER_BOARD or a board id"
+          },
+          "415": {
+            "description": "Body is neither JSON nor form-encoded"
+          },
+          "409": {
+            "description": "A project with this name already exists on the board (NAME_UNIQUE_PER_BOARD)"
+          }
+        }
+      }
+    },
+    "/api/time": {
+      "get": {
+        "summary": "Current server and database time (for clock-skew debugging)",
+        "responses": {
+          "200": {
+            "description": "Server and database clocks in RFC3339 UTC",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object",
+                  "properties": {
+                    "server": {
+                      "type": "string",
+                      "format": "date-time"
+                    },
+                    "database": {
+                      "type": "string",
+                      "format": "date-time"
+                    }
+                  }
+                }
+              }
+            }
+          },
+          "503": {
+            "description": "Database time could not be fetched"
+          }
+        }
+      }
+    },
+    "/api/test/search": {
+      "get": {
+        "summary": "Search test projects by name and creation date (requires the search feature flag)",
+        "parameters": [
+          {
+            "name": "name",
+            "in": "query",
+            "required": false,
+            "description": "Case-insensitive substring match on Name",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "createdAfter",
+            "in": "query",
+            "required": false,
+            "description": "Only projects created after this time",
+            "schema": {
+              "type": "string",
+              "format": "date-time"
+            }
+          },
+          {
+            "name": "createdBefore",
+            "in": "query",
+            "required": false,
+            "description": "Only projects created before this time",
+            "schema": {
+              "type": "string",
+              "format": "date-time"
+            }
+          },
+          {
+            "name": "limit",
+            "in": "query",
+            "required": false,
+            "schema": {
+              "type": "integer",
+              "minimum": 1,
+              "maximum": 500,
+              "default": 50
+            }
+          },
+          {
+            "name": "offset",
+            "in": "query",
+            "required": false,
+            "schema": {
+              "type": "integer",
+              "minimum": 0,
+              "default": 0
+            }
+          },
+          {
+            "name": "cursor",
+            "in": "query",
+            "required": false,
+            "description": "nextCursor of the previous page; use instead of offset",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "sort",
+            "in": "query",
+            "required": false,
+            "schema": {
+              "type": "string",
+              "enum": ["%[2]s", "%[3]s"],
+              "default": "%[2]s"
+            }
+          },
+          {
+            "name": "dir",
+            "in": "query",
+            "required": false,
+            "schema": {
+              "type": "string",
+              "enum": ["asc", "desc"],
+              "default": "asc"
+            }
+          },
+          {
+            "name": "envelope",
+            "in": "query",
+            "required": false,
+            "description": "Wrap the page in {items, hasMore, total, limit, offset}; otherwise a bare array is returned with X-Has-More / X-Total-Count headers",
+            "schema": {
+              "type": "boolean",
+              "default": false
+            }
+          },
+          {
+            "name": "countMode",
+            "in": "query",
+            "required": false,
+            "description": "exact runs a COUNT for total, estimate uses the planner's estimate, none only reports hasMore",
+            "schema": {
+              "type": "string",
+              "enum": ["none", "exact", "estimate"],
+              "default": "none"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Matching test projects: a bare array by default, a TestProjectsPage with envelope=true",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "oneOf": [
+                    {
+                      "type": "array",
+                      "items": {
+                        "$ref": "#/components/schemas/TestProjects"
+                      }
+                    },
+                    {
+                      "$ref": "#/components/schemas/TestProjectsPage"
+                    }
+                  ]
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid filter or pagination parameter"
+          }
+        }
+      }
+    },
+    "/api/test/all": {
+      "delete": {
+        "summary": "Delete every test project (test environments only, requires ALLOW_DESTRUCTIVE=true)",
+        "parameters": [
+          {
+            "name": "confirm",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "boolean",
+              "enum": [true]
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "All projects deleted",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object",
+                  "properties": {
+                    "deleted": {
+                      "type": "integer"
+                    }
+                  }
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "confirm=true missing"
+          },
+          "403": {
+            "description": "Destructive operations are disabled"
+          },
+          "409": {
+            "description": "Some projects are still referenced"
+          }
+        }
+      }
+    },
+    "/api/test/bulk": {
+      "post": {
+        "summary": "Create several test projects",
+        "parameters": [
+          {
+            "name": "atomic",
+            "in": "query",
+            "required": false,
+            "description": "true: all-or-nothing in one transaction; false: best effort, always 207 with per-item results",
+            "schema": {
+              "type": "boolean",
+              "default": true
+            }
+          }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "type": "array",
+                "maxItems": 1000,
+                "items": {
+                  "$ref": "#/components/schemas/TestProjectsInput"
+                }
+              }
+            }
+          }
+        },
+        "responses": {
+          "201": {
+            "description": "All items created",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/BulkResponse"
+                }
+              }
+            }
+          },
+          "207": {
+            "description": "Per-item results (atomic=false)",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/BulkResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid body or item (atomic=true: nothing was changed)"
+          },
+          "404": {
+            "description": "An item was not found (atomic=true: nothing was changed)"
+          },
+          "409": {
+            "description": "An item conflicted (atomic=true: nothing was changed)"
+          }
+        }
+      }
+    },
+    "/api/test/bulk/delete": {
+      "post": {
+        "summary": "Delete several test projects by id",
+        "parameters": [
+          {
+            "name": "atomic",
+            "in": "query",
+            "required": false,
+            "description": "true: all-or-nothing in one transaction; false: best effort, always 207 with per-item results",
+            "schema": {
+              "type": "boolean",
+              "default": true
+            }
+          }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "type": "array",
+                "maxItems": 1000,
+                "items": {
+                  "type": "integer"
+                }
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "All items deleted",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/BulkResponse"
+                }
+              }
+            }
+          },
+          "207": {
+            "description": "Per-item results (atomic=false)",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/BulkResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid body or item (atomic=true: nothing was changed)"
+          },
+          "404": {
+            "description": "An item was not found (atomic=true: nothing was changed)"
+          },
+          "409": {
+            "description": "An item conflicted (atomic=true: nothing was changed)"
+          }
+        }
+      }
+    },
+    "/api/test/import": {
+      "post": {
+        "summary": "Bulk-import test projects from CSV (one Name per row, optional header)",
+        "parameters": [
+          {
+            "name": "report",
+            "in": "query",
+            "required": false,
+            "description": "Insert row by row, skipping and reporting failed rows instead of rejecting the whole import",
+            "schema": {
+              "type": "boolean",
+              "default": false
+            }
+          }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "text/csv": {
+              "schema": {
+                "type": "string"
+              }
+            }
+          }
+        },
+        "responses": {
+          "201": {
+            "description": "All rows imported",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ImportResult"
+                }
+              }
+            }
+          },
+          "207": {
+            "description": "Some rows were skipped (report mode only)",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ImportResult"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid CSV, or invalid rows (nothing was imported)"
+          },
+          "413": {
+            "description": "Body too large"
+          }
+        }
+      }
+    },
+    "/api/test/stats": {
+      "get": {
+        "summary": "Summary numbers for dashboards (requires migration 001)",
+        "responses": {
+          "200": {
+            "description": "Project count, projects created in the last 24 hours and the newest project",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ProjectStats"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/api/test/by-name": {
+      "get": {
+        "summary": "Get a test project by its exact name",
+        "parameters": [
+          {
+            "name": "name",
+            "in": "query",
+            "required": true,
+            "description": "Exact, case-sensitive name; limited to the request's board when NAME_UNIQUE_PER_BOARD=true",
+            "schema": {
+              "type": "string",
+              "minLength": 1,
+              "maxLength": 200
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The project with this name",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/TestProjects"
+                }
+              }
+            }
+          },
+          "404": {
+            "description": "No project has this name"
+          },
+          "409": {
+            "description": "More than one project has this name; the body lists their ids (up to 10)"
+          },
+          "422": {
+            "description": "name is missing or invalid",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ValidationError"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/api/test/rename": {
+      "put": {
+        "summary": "Rename a test project by its current name",
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/RenameInput"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Project renamed",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/TestProjects"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid JSON"
+          },
+          "422": {
+            "description": "Validation failed; every problem is listed",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ValidationError"
+                }
+              }
+            }
+          },
+          "404": {
+            "description": "No project has the from name"
+          },
+          "409": {
+            "description": "A project already has the to name"
+          }
+        }
+      }
+    },
+    "/api/test/{id}/duplicate": {
+      "post": {
+        "summary": "Duplicate a test project",
+        "description": "Creates a copy named \"Copy of <name>\", or \"Copy of <name> (2)\", \"(3)\", ... when that name is already in use (within the board when NAME_UNIQUE_PER_BOARD is set).",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          }
+        ],
+        "responses": {
+          "201": {
+            "description": "The new copy",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/TestProjects"
+                }
+              }
+            }
+          },
+          "404": {
+            "description": "Source project not found",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/NotFoundError"
+                }
+              }
+            }
+          },
+          "409": {
+            "description": "Every candidate copy name is taken, or a concurrent duplicate took the chosen one"
+          }
+        }
+      }
+    },
+    "/api/test/{id}/exists": {
+      "get": {
+        "summary": "Check whether a test project exists",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          }
+        ],
+        "responses": {
+          "204": {
+            "description": "Project exists"
+          },
+          "404": {
+            "description": "Project not found"
+          }
+        }
+      }
+    },
+    "/api/test/{id}": {
+      "get": {
+        "summary": "Get test project by ID",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Test project found",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/TestProjects"
+                }
+              },
+              "application/xml": {
+                "schema": {
+                  "$ref": "#/components/schemas/TestProjects"
+                }
+              },
+              "application/vnd.api+json": {
+                "schema": {
+                  "type": "object",
+                  "description": "JSON:API document: data is {type: \"test\", id, attributes: {name}}"
+                }
+              }
+            }
+          },
+          "404": {
+            "description": "Project not found",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/NotFoundError"
+                }
+              }
+            }
+          },
+          "406": {
+            "description": "Accept header does not allow JSON, JSON:API or XML"
+          }
+        }
+      },
+      "put": {
+        "summary": "Update test project",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          },
+          {
+            "name": "If-Match",
+            "in": "header",
+            "required": false,
+            "description": "ETag from a previous GET; the request fails with 412 if the project has changed since",
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/TestProjectsInput"
+              }
+            },
+            "application/x-www-form-urlencoded": {
+              "schema": {
+                "$ref": "#/components/schemas/TestProjectsInput"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Updated test project"
+          },
+          "415": {
+            "description": "Body is neither JSON nor form-encoded"
+          },
+          "422": {
+            "description": "Validation failed; every problem is listed",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ValidationError"
+                }
+              }
+            }
+          },
+          "412": {
+            "description": "If-Match does not match the current ETag"
+          },
+          "404": {
+            "description": "Project not found",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/NotFoundError"
+                }
+              }
+            }
+          }
+        }
+      },
+      "patch": {
+        "summary": "Partially update test project (JSON Merge Patch)",
+        "description": "Applies an RFC 7386 merge patch: members present are set, absent members are left unchanged, and null clears a field. Name is required, so clearing it is rejected with 422; Id is read-only.",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          },
+          {
+            "name": "If-Match",
+            "in": "header",
+            "required": false,
+            "description": "ETag from a previous GET; the request fails with 412 if the project has changed since",
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/merge-patch+json": {
+              "schema": {
+                "type": "object"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Patched test project"
+          },
+          "400": {
+            "description": "Body is not a JSON object"
+          },
+          "415": {
+            "description": "Content-Type is not application/merge-patch+json"
+          },
+          "422": {
+            "description": "Validation failed; every problem is listed",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ValidationError"
+                }
+              }
+            }
+          },
+          "412": {
+            "description": "If-Match does not match the current ETag"
+          },
+          "404": {
+            "description": "Project not found",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/NotFoundError"
+                }
+              }
+            }
+          }
+        }
+      },
+      "delete": {
+        "summary": "Delete test project",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          },
+          {
+            "name": "If-Match",
+            "in": "header",
+            "required": false,
+            "description": "ETag from a previous GET; the request fails with 412 if the project has changed since",
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Deleted successfully"
+          },
+          "409": {
+            "description": "Project is referenced by other records and cannot be deleted"
+          },
+          "412": {
+            "description": "If-Match does not match the current ETag"
+          },
+          "404": {
+            "description": "Project not found",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/NotFoundError"
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "TestProjects": {
+        "type": "object",
+        "description": "Keys follow JSON_FIELD_CASE (%[2]s, %[3]s)",
+        "properties": {
+          "%[2]s": {
+            "type": "integer",
+            "format": "int64",
+            "description": "Serialized as a string when ID_AS_STRING=true"
+          },
+          "%[3]s": {
+            "type": "string"
+          }
+        }
+      },
+      "NotFoundError": {
+        "type": "object",
+        "properties": {
+          "error": {
+            "type": "object",
+            "properties": {
+              "code": {
+                "type": "string",
+                "example": "not_found"
+              },
+              "message": {
+                "type": "string",
+                "example": "project not found"
+              },
+              "id": {
+                "type": "integer"
+              }
+            }
+          }
+        }
+      },
+      "ValidationError": {
+        "type": "object",
+        "properties": {
+          "error": {
+            "type": "object",
+            "properties": {
+              "code": {
+                "type": "string",
+                "example": "validation_failed"
+              },
+              "message": {
+                "type": "string"
+              },
+              "fields": {
+                "type": "array",
+                "items": {
+                  "type": "object",
+                  "properties": {
+                    "field": {
+                      "type": "string"
+                    },
+                    "message": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            }
+          }
+        }
+      },
+      "TestProjectsPage": {
+        "type": "object",
+        "xml": {
+          "name": "TestProjectsPage"
+        },
+        "properties": {
+          "items": {
+            "type": "array",
+            "xml": {
+              "name": "Items",
+              "wrapped": true
+            },
+            "items": {
+              "$ref": "#/components/schemas/TestProjects"
+            }
+          },
+          "total": {
+            "type": "integer",
+            "description": "Rows matching the filter (only with countMode exact or estimate)"
+          },
+          "estimated": {
+            "type": "boolean",
+            "description": "Whether total is a planner estimate"
+          },
+          "hasMore": {
+            "type": "boolean",
+            "description": "Whether more rows follow this page"
+          },
+          "limit": {
+            "type": "integer",
+            "description": "Effective page size"
+          },
+          "offset": {
+            "type": "integer"
+          },
+          "nextCursor": {
+            "type": "string",
+            "description": "cursor for the following page; absent on the last page"
+          }
+        }
+      },
+      "ProjectStats": {
+        "type": "object",
+        "properties": {
+          "total": {
+            "type": "integer"
+          },
+          "createdLast24h": {
+            "type": "integer"
+          },
+          "latest": {
+            "nullable": true,
+            "description": "Most recently created project; null when there are none",
+            "allOf": [
+              {
+                "$ref": "#/components/schemas/TestProjects"
+              }
+            ]
+          }
+        }
+      },
+      "TestProjectsInput": {
+        "type": "object",
+        "description": "Keys are matched case-insensitively, so name is accepted too",
+        "required": ["Name"],
+        "properties": {
+          "Name": {
+            "type": "string",
+            "minLength": 1,
+            "maxLength": 200
+          }
+        }
+      },
+      "BulkResponse": {
+        "type": "object",
+        "properties": {
+          "results": {
+            "type": "array",
+            "description": "One entry per request item, in request order",
+            "items": {
+              "type": "object",
+              "properties": {
+                "index": {
+                  "type": "integer"
+                },
+                "status": {
+                  "type": "integer"
+                },
+                "id": {
+                  "type": "integer"
+                },
+                "error": {
+                  "type": "string"
+                }
+              }
+            }
+          }
+        }
+      },
+      "ImportResult": {
+        "type": "object",
+        "properties": {
+          "imported": {
+            "type": "integer"
+          },
+          "errors": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "properties": {
+                "line": {
+                  "type": "integer"
+                },
+                "error": {
+                  "type": "string"
+                }
+              }
+            }
+          }
+        }
+      },
+      "RenameInput": {
+        "type": "object",
+        "required": ["from", "to"],
+        "properties": {
+          "from": {
+            "type": "string",
+            "minLength": 1,
+            "maxLength": 200
+          },
+          "to": {
+            "type": "string",
+            "minLength": 1,
+            "maxLength": 200
+          }
+        }
+      }
+    }
+  }
+}`, serverURL, models.JSONFieldName("Id"), models.JSONFieldName("Name"))
+}
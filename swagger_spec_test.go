@@ -0,0 +1,99 @@
+package main
+
+import (
+    "encoding/json"
+    "reflect"
+    "sort"
+    "testing"
+    
+    "backend/Models"
+    "gopkg.in/yaml.v3"
+)
+
+func TestOpenAPISpecProjectKeysFollowFieldCase(t *testing.T) {
+    defer func(old bool) { models.CamelCaseJSON = old }(models.CamelCaseJSON)
+    
+    for _, tt := range []struct {
+        camel bool
+        keys  []string
+    }{
+        {false, []string{"Id", "Name"}},
+        {true, []string{"id", "name"}},
+    } {
+        models.CamelCaseJSON = tt.camel
+        var spec struct {
+            Components struct {
+                Schemas map[string]struct {
+                    Properties map[string]json.RawMessage `json:"properties"`
+                } `json:"schemas"`
+            } `json:"components"`
+        }
+        if err := json.Unmarshal([]byte(openAPISpec("/")), &spec); err != nil {
+            t.Fatalf("spec is not valid JSON: %v", err)
+        }
+        
+        var keys []string
+        for key := range spec.Components.Schemas["TestProjects"].Properties {
+            keys = append(keys, key)
+        }
+        sort.Strings(keys)
+        if !reflect.DeepEqual(keys, tt.keys) {
+            t.Errorf("CamelCaseJSON=%v: TestProjects properties %v, want %v", tt.camel, keys, tt.keys)
+        }
+        
+        // The schema must describe what the API actually emits
+        data, err := json.Marshal(models.TestProjects{Id: 1, Name: "x"})
+        if err != nil {
+            t.Fatal(err)
+        }
+        var emitted map[string]json.RawMessage
+        if err := json.Unmarshal(data, &emitted); err != nil {
+            t.Fatal(err)
+        }
+        for _, key := range tt.keys {
+            if _, ok := emitted[key]; !ok {
+                t.Errorf("CamelCaseJSON=%v: %s has no %q key", tt.camel, data, key)
+            }
+        }
+    }
+}
+
+func TestSpecYAMLMatchesJSON(t *testing.T) {
+    specJSON := openAPISpec("/")
+    specYAML, err := specToYAML(specJSON)
+    if err != nil {
+        t.Fatalf("specToYAML: %v", err)
+    }
+    
+    var fromJSON, fromYAML map[string]interface{}
+    if err := json.Unmarshal([]byte(specJSON), &fromJSON); err != nil {
+        t.Fatalf("spec is not valid JSON: %v", err)
+    }
+    if err := yaml.Unmarshal(specYAML, &fromYAML); err != nil {
+        t.Fatalf("spec is not valid YAML: %v", err)
+    }
+    
+    paths := func(spec map[string]interface{}) []string {
+        var keys []string
+        for key := range spec["paths"].(map[string]interface{}) {
+            keys = append(keys, key)
+        }
+        sort.Strings(keys)
+        return keys
+    }
+    if got, want := paths(fromYAML), paths(fromJSON); !reflect.DeepEqual(got, want) {
+        t.Errorf("YAML paths %v, want the JSON paths %v", got, want)
+    }
+    
+    // Round-trip the YAML through JSON so numbers compare alike, then the whole
+    // documents must agree
+    reencoded, err := json.Marshal(fromYAML)
+    if err != nil {
+        t.Fatalf("Marshal: %v", err)
+    }
+    var roundTripped map[string]interface{}
+    json.Unmarshal(reencoded, &roundTripped)
+    if !reflect.DeepEqual(roundTripped, fromJSON) {
+        t.Error("the YAML spec differs from the JSON spec")
+    }
+}
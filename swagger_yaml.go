@@ -0,0 +1,27 @@
+package main
+
+import (
+    "gopkg.in/yaml.v3"
+)
+
+// specToYAML converts the JSON OpenAPI spec to YAML. JSON is valid YAML, so the spec
+// is parsed into a yaml.Node (which keeps the key order of the original, unlike a
+// map) and re-emitted in block style.
+func specToYAML(specJSON string) ([]byte, error) {
+    var doc yaml.Node
+    if err := yaml.Unmarshal([]byte(specJSON), &doc); err != nil {
+        return nil, err
+    }
+    clearStyle(&doc)
+    return yaml.Marshal(&doc)
+}
+
+// clearStyle drops the flow/quoted styles the JSON syntax left on every node so the
+// encoder picks plain block style (still quoting strings that would otherwise be
+// read as another type)
+func clearStyle(node *yaml.Node) {
+    node.Style = 0
+    for _, child := range node.Content {
+        clearStyle(child)
+    }
+}